@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+
+	"github.com/marwanhawari/stew/constants"
+	stew "github.com/marwanhawari/stew/lib"
+)
+
+// AuthStatus is executed when you run `stew auth status`
+func AuthStatus() {
+	userOS, userArch, _, systemInfo, err := stew.Initialize()
+	stew.CatchAndExit(err)
+
+	knownHosts, err := stew.KnownCredentialHosts()
+	stew.CatchAndExit(err)
+
+	hosts := map[string]bool{}
+	for _, host := range knownHosts {
+		hosts[host] = true
+	}
+
+	// Add the hosts that the GitHub API and the installed binaries are downloaded from, after the URL rewrite rules
+	requestURLs := []string{"https://api.github.com/"}
+	lockFile, err := stew.NewLockFile(systemInfo.StewLockFilePath, userOS, userArch)
+	stew.CatchAndExit(err)
+	for _, pkg := range lockFile.Packages {
+		if pkg.Source != "github" {
+			requestURLs = append(requestURLs, pkg.URL)
+		}
+	}
+	for _, requestURL := range requestURLs {
+		rewrittenURL, _ := stew.RewriteURL(requestURL)
+		if parsedURL, err := url.Parse(rewrittenURL); err == nil && parsedURL.Hostname() != "" {
+			hosts[parsedURL.Hostname()] = true
+		}
+	}
+
+	sortedHosts := []string{}
+	for host := range hosts {
+		sortedHosts = append(sortedHosts, host)
+	}
+	sort.Strings(sortedHosts)
+
+	for _, host := range sortedHosts {
+		credential, err := stew.FindCredential(host)
+		stew.CatchAndExit(err)
+		if credential == nil {
+			fmt.Fprintf(stew.HumanOutput(), "%v: no credentials\n", host)
+			stew.EmitResult(stew.Result{Status: "missing", Auth: &stew.AuthStatus{Host: host}})
+			continue
+		}
+		fmt.Fprintf(stew.HumanOutput(), "%v: %v from %v\n", constants.GreenColor(host), credential.Kind(), credential.Source)
+		stew.EmitResult(stew.Result{Status: "success", Auth: &stew.AuthStatus{Host: host, Kind: credential.Kind(), Source: credential.Source}})
+	}
+}
@@ -2,8 +2,7 @@ package cmd
 
 import (
 	"fmt"
-	"os"
-	"path/filepath"
+	"strings"
 
 	"github.com/marwanhawari/stew/constants"
 	stew "github.com/marwanhawari/stew/lib"
@@ -11,16 +10,16 @@ import (
 
 // Browse is executed when you run `stew browse`
 func Browse(cliInput string) {
+	if stew.IsNonInteractive() {
+		stew.CatchAndExit(stew.NonInteractiveError{Prompt: "stew browse", Resolution: "Install a specific release directly with stew install owner/repo@tag"})
+	}
 
 	userOS, userArch, _, systemInfo, err := stew.Initialize()
 	stew.CatchAndExit(err)
 
 	sp := constants.LoadingSpinner
 
-	stewBinPath := systemInfo.StewBinPath
-	stewPkgPath := systemInfo.StewPkgPath
 	stewLockFilePath := systemInfo.StewLockFilePath
-	stewTmpPath := systemInfo.StewTmpPath
 
 	parsedInput, err := stew.ParseCLIInput(cliInput)
 	stew.CatchAndExit(err)
@@ -28,15 +27,7 @@ func Browse(cliInput string) {
 	owner := parsedInput.Owner
 	repo := parsedInput.Repo
 
-	lockFile, err := stew.NewLockFile(stewLockFilePath, userOS, userArch)
-	stew.CatchAndExit(err)
-
-	err = os.RemoveAll(stewTmpPath)
-	stew.CatchAndExit(err)
-	err = os.MkdirAll(stewTmpPath, 0755)
-	stew.CatchAndExit(err)
-
-	fmt.Println(constants.GreenColor(owner + "/" + repo))
+	fmt.Fprintln(stew.HumanOutput(), constants.GreenColor(owner+"/"+repo))
 	sp.Start()
 	githubProject, err := stew.NewGithubProject(owner, repo)
 	sp.Stop()
@@ -44,44 +35,72 @@ func Browse(cliInput string) {
 
 	releaseTags, err := stew.GetGithubReleasesTags(githubProject)
 	stew.CatchAndExit(err)
-	tag, err := stew.PromptSelect("Choose a release tag:", releaseTags)
+	formattedReleases := stew.FormatReleases(githubProject.Releases)
+	formattedRelease, err := stew.PromptSelect("Choose a release tag:", formattedReleases)
 	stew.CatchAndExit(err)
-	tagIndex, _ := stew.Contains(releaseTags, tag)
+	tagIndex, _ := stew.Contains(formattedReleases, formattedRelease)
+	tag := releaseTags[tagIndex]
+	release := githubProject.Releases[tagIndex]
 
 	releaseAssets, err := stew.GetGithubReleasesAssets(githubProject, tag)
 	stew.CatchAndExit(err)
-	asset, err := stew.PromptSelect("Download and install an asset", releaseAssets)
+	detectedAsset, _, err := stew.DetectPlatformAsset(userOS, userArch, releaseAssets)
 	stew.CatchAndExit(err)
-	assetIndex, _ := stew.Contains(releaseAssets, asset)
-
-	downloadURL := githubProject.Releases[tagIndex].Assets[assetIndex].DownloadURL
-	downloadPath := filepath.Join(stewPkgPath, asset)
-	err = stew.DownloadFile(downloadPath, downloadURL)
-	stew.CatchAndExit(err)
-	fmt.Printf("✅ Downloaded %v to %v\n", constants.GreenColor(asset), constants.GreenColor(stewPkgPath))
+	formattedAssets := stew.FormatAssets(release.Assets, detectedAsset, userOS, userArch)
+	defaultAsset := ""
+	if detectedAssetIndex, found := stew.Contains(releaseAssets, detectedAsset); found {
+		defaultAsset = formattedAssets[detectedAssetIndex]
+	}
+	viewReleaseNotes := fmt.Sprintf("📝 View the release notes for %v", tag)
 
-	binaryName, binaryHash, err := stew.InstallBinary(downloadPath, repo, systemInfo, &lockFile, false, "", "")
-	if err != nil {
-		os.RemoveAll(downloadPath)
+	var asset string
+	for {
+		formattedAsset, err := stew.PromptSelectDefault("Download and install an asset", append(formattedAssets, viewReleaseNotes), defaultAsset)
 		stew.CatchAndExit(err)
+		if formattedAsset != viewReleaseNotes {
+			assetIndex, _ := stew.Contains(formattedAssets, formattedAsset)
+			asset = releaseAssets[assetIndex]
+			break
+		}
+		printReleaseNotes(release)
 	}
+	assetIndex, _ := stew.Contains(releaseAssets, asset)
 
 	packageData := stew.PackageData{
-		Source:     "github",
-		Owner:      githubProject.Owner,
-		Repo:       githubProject.Repo,
-		Tag:        tag,
-		Asset:      asset,
-		Binary:     binaryName,
-		URL:        downloadURL,
-		BinaryHash: binaryHash,
+		Source: "github",
+		Owner:  githubProject.Owner,
+		Repo:   githubProject.Repo,
+		Tag:    tag,
+		Asset:  asset,
+		URL:    githubProject.Releases[tagIndex].Assets[assetIndex].DownloadURL,
 	}
+	// The stew path is only locked once the release and asset are chosen, so that other stew processes don't wait for the prompts
+	var unlock func()
+	systemInfo, unlock, err = stew.LockStewPath(systemInfo)
+	stew.CatchAndExit(err)
+	defer unlock()
 
-	lockFile.Packages = append(lockFile.Packages, packageData)
-
-	err = stew.WriteLockFileJSON(lockFile, stewLockFilePath)
+	lockFile, err := stew.NewLockFile(stewLockFilePath, userOS, userArch)
+	stew.CatchAndExit(err)
+	stagedPkg, err := downloadAndStage(packageData, systemInfo)
+	stew.CatchAndExit(err)
+	err = stew.ConfirmBinaryOverwrite(lockFile, stagedPkg.Package.Binary)
+	stew.CatchAndExit(err)
+	var previousTag string
+	if indexInLockFile, found := stew.FindBinaryInLockFile(lockFile, stagedPkg.Package.Binary); found {
+		previousTag = lockFile.Packages[indexInLockFile].Tag
+	}
+	packageData, err = installStaged(stagedPkg, systemInfo, userOS, userArch)
 	stew.CatchAndExit(err)
 
-	fmt.Printf("✨ Successfully installed the %v binary in %v\n", constants.GreenColor(binaryName), constants.GreenColor(stewBinPath))
+	finishInstall(packageData, systemInfo, previousTag)
+}
 
+func printReleaseNotes(release stew.GithubRelease) {
+	fmt.Fprintln(stew.HumanOutput(), constants.GreenColor(release.TagName))
+	if strings.TrimSpace(release.Body) == "" {
+		fmt.Fprintln(stew.HumanOutput(), constants.YellowColor("This release doesn't have any release notes"))
+		return
+	}
+	fmt.Fprintf(stew.HumanOutput(), "%v\n\n", strings.TrimSpace(release.Body))
 }
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/marwanhawari/stew/constants"
+	stew "github.com/marwanhawari/stew/lib"
+)
+
+// CacheList is executed when you run `stew cache list`
+func CacheList() {
+	_, _, _, systemInfo, err := stew.Initialize()
+	stew.CatchAndExit(err)
+
+	entries, err := stew.ReadCacheIndex(systemInfo.StewCachePath)
+	stew.CatchAndExit(err)
+
+	for _, entry := range entries {
+		fmt.Fprintf(stew.HumanOutput(), "%v  %9v  %v  %v\n", constants.GreenColor(entry.ShortHash()), stew.FormatBytes(entry.Size), entry.LastUsed.Local().Format(time.DateOnly), entry.URL)
+	}
+}
+
+// CacheSize is executed when you run `stew cache size`
+func CacheSize() {
+	_, _, _, systemInfo, err := stew.Initialize()
+	stew.CatchAndExit(err)
+
+	count, size, err := stew.CacheSize(systemInfo.StewCachePath)
+	stew.CatchAndExit(err)
+
+	fmt.Fprintf(stew.HumanOutput(), "📦 %v cached assets use %v in %v\n", constants.GreenColor(count), constants.GreenColor(stew.FormatBytes(size)), constants.GreenColor(systemInfo.StewCachePath))
+}
+
+// CachePrune is executed when you run `stew cache prune`
+func CachePrune(cliOlderThanFlag string, cliAllFlag bool) {
+	if cliOlderThanFlag != "" && cliAllFlag {
+		stew.CatchAndExit(stew.ConflictingFlagsError{Flags: []string{"--older-than", "--all"}})
+	}
+	if cliOlderThanFlag == "" && !cliAllFlag {
+		stew.CatchAndExit(stew.CachePruneScopeError{})
+	}
+
+	var olderThan time.Duration
+	if cliOlderThanFlag != "" {
+		var err error
+		olderThan, err = stew.ParseAge(cliOlderThanFlag)
+		stew.CatchAndExit(err)
+	}
+
+	_, _, _, systemInfo, err := stew.Initialize()
+	stew.CatchAndExit(err)
+
+	_, sizeBefore, err := stew.CacheSize(systemInfo.StewCachePath)
+	stew.CatchAndExit(err)
+
+	prunedEntries, err := stew.PruneCache(systemInfo.StewCachePath, olderThan)
+	stew.CatchAndExit(err)
+
+	_, sizeAfter, err := stew.CacheSize(systemInfo.StewCachePath)
+	stew.CatchAndExit(err)
+
+	for _, entry := range prunedEntries {
+		fmt.Fprintf(stew.HumanOutput(), "🗑️  Removed %v\n", entry.URL)
+	}
+	fmt.Fprintf(stew.HumanOutput(), "✨ Freed %v from %v\n", constants.GreenColor(stew.FormatBytes(sizeBefore-sizeAfter)), constants.GreenColor(systemInfo.StewCachePath))
+}
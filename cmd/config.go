@@ -23,6 +23,10 @@ func Config() {
 		return
 	}
 
+	if stew.IsNonInteractive() {
+		stew.CatchAndExit(stew.NonInteractiveError{Prompt: "stew config", Resolution: "Edit " + stewConfigFilePath + " directly"})
+	}
+
 	config, err := stew.ReadStewConfigJSON(stewConfigFilePath)
 	stew.CatchAndExit(err)
 	systemInfo := stew.NewSystemInfo(config)
@@ -31,11 +35,15 @@ func Config() {
 	newStewPath, newStewBinPath, newExcludedFromUpgradeAll, err := stew.PromptConfig(config.StewPath, config.StewBinPath, installedPackages, config.ExcludedFromUpgradeAll)
 	stew.CatchAndExit(err)
 
-	newStewConfig := stew.StewConfig{StewPath: newStewPath, StewBinPath: newStewBinPath, ExcludedFromUpgradeAll: newExcludedFromUpgradeAll}
+	// Keep the settings that can't be set with the prompts
+	newStewConfig := config
+	newStewConfig.StewPath = newStewPath
+	newStewConfig.StewBinPath = newStewBinPath
+	newStewConfig.ExcludedFromUpgradeAll = newExcludedFromUpgradeAll
 	err = stew.WriteStewConfigJSON(newStewConfig, stewConfigFilePath)
 	stew.CatchAndExit(err)
 
-	fmt.Printf("📄 Updated %v\n", constants.GreenColor(stewConfigFilePath))
+	fmt.Fprintf(stew.HumanOutput(), "📄 Updated %v\n", constants.GreenColor(stewConfigFilePath))
 
 	pathVariable := os.Getenv("PATH")
 	stew.ValidateStewBinPath(newStewBinPath, pathVariable)
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/marwanhawari/stew/constants"
+	stew "github.com/marwanhawari/stew/lib"
+)
+
+// Doctor is executed when you run `stew doctor`
+func Doctor(cliFixFlag bool) {
+	userOS, userArch, _, systemInfo, err := stew.Initialize()
+	stew.CatchAndExit(err)
+
+	var unlock func()
+	systemInfo, unlock, err = stew.LockStewPath(systemInfo)
+	stew.CatchAndExit(err)
+	defer unlock()
+
+	lockFile, err := stew.NewLockFile(systemInfo.StewLockFilePath, userOS, userArch)
+	stew.CatchAndExit(err)
+
+	problems, err := stew.Diagnose(lockFile, systemInfo)
+	stew.CatchAndExit(err)
+	if len(problems) == 0 {
+		fmt.Fprintf(stew.HumanOutput(), "✨ No problems found in %v and %v\n", constants.GreenColor(systemInfo.StewBinPath), constants.GreenColor(systemInfo.StewPkgPath))
+		return
+	}
+
+	var needsReinstall, needsFix bool
+	for index, problem := range problems {
+		if cliFixFlag && problem.Fixable {
+			err = problem.Fix()
+			stew.CatchAndExit(err)
+			fmt.Fprintf(stew.HumanOutput(), "🗑️  Removed the %v %v\n", problem.Kind, constants.GreenColor(problem.Path))
+			stew.EmitResult(stew.Result{Status: "fixed", Binary: problem.Binary, Problem: &problems[index]})
+			continue
+		}
+
+		fmt.Fprintf(stew.HumanOutput(), "%v %v\n", constants.YellowColor("⚠️  "+describeProblem(problem)), problem.Path)
+		stew.EmitResult(stew.Result{Status: "problem", Binary: problem.Binary, Problem: &problems[index]})
+		if problem.Fixable {
+			needsFix = true
+		} else {
+			needsReinstall = true
+		}
+	}
+
+	if needsFix {
+		fmt.Fprintf(stew.HumanOutput(), "Run %v to remove the links, packages, and assets that no installed binary uses\n", constants.GreenColor("stew doctor --fix"))
+	}
+	if needsReinstall {
+		fmt.Fprintf(stew.HumanOutput(), "Run %v to reinstall every binary in the lockfile\n", constants.GreenColor("stew install "+systemInfo.StewLockFilePath))
+	}
+	if needsFix || needsReinstall {
+		stew.FlushResults()
+		os.Exit(1)
+	}
+}
+
+func describeProblem(problem stew.DoctorProblem) string {
+	switch problem.Kind {
+	case stew.MissingBinaryProblem:
+		return fmt.Sprintf("The %v binary is in the lockfile but not installed:", problem.Binary)
+	case stew.DanglingLinkProblem:
+		return fmt.Sprintf("The %v link points to %v, which doesn't exist:", problem.Binary, problem.Target)
+	case stew.ModifiedBinaryProblem:
+		return fmt.Sprintf("The %v binary doesn't match the hash in the lockfile:", problem.Binary)
+	case stew.OrphanedLinkProblem:
+		return fmt.Sprintf("The %v link is not in the lockfile:", problem.Binary)
+	case stew.OrphanedPackageProblem:
+		return fmt.Sprintf("The package of %v is not used by any installed binary:", problem.Binary)
+	case stew.OrphanedAssetProblem:
+		return "The asset is not used by any installed binary:"
+	}
+	return problem.Kind + ":"
+}
@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	stew "github.com/marwanhawari/stew/lib"
+)
+
+// Export is executed when you run `stew export`
+func Export(cliPinFlag bool, cliFormat string) {
+	userOS, userArch, _, systemInfo, err := stew.Initialize()
+	stew.CatchAndExit(err)
+
+	lockFile, err := stew.NewLockFile(systemInfo.StewLockFilePath, userOS, userArch)
+	stew.CatchAndExit(err)
+
+	stewfileContents, err := stew.FormatStewfile(lockFile.Packages, cliFormat, cliPinFlag)
+	stew.CatchAndExit(err)
+
+	// The Stewfile is the output of the command, so it is written to stdout or emitted as a result instead of the human readable output
+	if stew.IsStructuredOutput() {
+		stew.EmitResult(stew.Result{Status: "success", Stewfile: stewfileContents})
+		return
+	}
+	fmt.Fprint(os.Stdout, stewfileContents)
+}
@@ -0,0 +1,216 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/marwanhawari/stew/constants"
+	stew "github.com/marwanhawari/stew/lib"
+)
+
+// Info is executed when you run `stew info`
+func Info(cliInput string) {
+	userOS, userArch, _, systemInfo, err := stew.Initialize()
+	stew.CatchAndExit(err)
+
+	err = stew.ValidateCLIInput(cliInput)
+	stew.CatchAndExit(err)
+
+	lockFile, err := stew.NewLockFile(systemInfo.StewLockFilePath, userOS, userArch)
+	stew.CatchAndExit(err)
+
+	pkg, installed, err := findInfoPackage(cliInput, lockFile)
+	stew.CatchAndExit(err)
+
+	info := stew.PackageInfo{Installed: installed}
+	if installed {
+		info.BinaryPath = filepath.Join(systemInfo.StewBinPath, pkg.Binary)
+		binaryFilePath := info.BinaryPath
+		if stew.IsDirectoryPackage(pkg) {
+			info.BinaryPath = stew.EntrypointPath(systemInfo.StewBinPath, pkg.Binary, pkg.Wrappers)
+			info.PackagePath = stew.PackageStorePath(systemInfo.StewPkgPath, pkg.Binary, pkg.Tag)
+			binaryFilePath = filepath.Join(info.PackagePath, filepath.FromSlash(pkg.Entrypoints[0]))
+		}
+		if binaryInfo, err := os.Stat(binaryFilePath); err == nil {
+			info.BinarySize = binaryInfo.Size()
+		}
+		if linkTarget, err := os.Readlink(info.BinaryPath); err == nil {
+			info.LinkTarget = linkTarget
+		}
+	}
+
+	if pkg.Source == "github" {
+		err = addGithubInfo(&pkg, &info, userOS, userArch)
+		stew.CatchAndExit(err)
+	}
+
+	if stew.IsStructuredOutput() {
+		stew.EmitResult(stew.Result{Status: "success", Binary: pkg.Binary, Package: &pkg, Info: &info})
+		return
+	}
+	printInfo(pkg, info, userOS, userArch)
+}
+
+// findInfoPackage finds the lockfile entry of an installed binary or GitHub repo. A GitHub repo that isn't installed returns the parsed input.
+func findInfoPackage(cliInput string, lockFile stew.LockFile) (stew.PackageData, bool, error) {
+	if indexInLockFile, found := stew.FindBinaryInLockFile(lockFile, cliInput); found {
+		return lockFile.Packages[indexInLockFile], true, nil
+	}
+
+	parsedInput, err := stew.ParseCLIInput(cliInput)
+	if err != nil {
+		if !strings.Contains(cliInput, "/") {
+			return stew.PackageData{}, false, stew.BinaryNotInstalledError{Binary: cliInput}
+		}
+		return stew.PackageData{}, false, err
+	}
+
+	for _, pkg := range lockFile.Packages {
+		if parsedInput.Source == "github" && pkg.Source == "github" && pkg.Owner == parsedInput.Owner && pkg.Repo == parsedInput.Repo {
+			return pkg, true, nil
+		}
+		if parsedInput.Source == "other" && pkg.URL == parsedInput.URL {
+			return pkg, true, nil
+		}
+	}
+	return parsedInput, false, nil
+}
+
+// addGithubInfo adds the repo details and the latest release. A package that isn't installed gets the asset that would be installed on this platform.
+func addGithubInfo(pkg *stew.PackageData, info *stew.PackageInfo, userOS, userArch string) error {
+	sp := constants.LoadingSpinner
+
+	sp.Start()
+	githubRepo, err := stew.GetGithubRepo(pkg.Owner, pkg.Repo)
+	if err != nil {
+		sp.Stop()
+		return err
+	}
+	githubProject, err := stew.NewGithubProject(pkg.Owner, pkg.Repo)
+	sp.Stop()
+	if err != nil {
+		return err
+	}
+	info.Repo = &githubRepo
+
+	latestTag, latestTagFound := stew.LatestReleaseTag(githubProject.Releases)
+	if latestTagFound {
+		info.LatestTag = latestTag
+	}
+
+	if info.Installed {
+		if releasesBehind, tagFound := stew.ReleasesBehind(githubProject.Releases, pkg.Tag); tagFound {
+			info.ReleasesBehind = &releasesBehind
+		}
+		return nil
+	}
+
+	if pkg.Tag == "" {
+		if !latestTagFound {
+			return nil
+		}
+		pkg.Tag = latestTag
+	}
+	releaseAssets, err := stew.GetGithubReleasesAssets(githubProject, pkg.Tag)
+	if err != nil {
+		return err
+	}
+	if pkg.Asset == "" {
+		info.DetectedAsset, _, err = stew.DetectPlatformAsset(userOS, userArch, releaseAssets)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func printInfo(pkg stew.PackageData, info stew.PackageInfo, userOS, userArch string) {
+	printInfoField := func(label, value string) {
+		if value != "" {
+			fmt.Fprintf(stew.HumanOutput(), "  %-16v %v\n", label+":", value)
+		}
+	}
+
+	name := pkg.Binary
+	if name == "" {
+		name = pkg.Owner + "/" + pkg.Repo
+	}
+	if info.Installed {
+		fmt.Fprintln(stew.HumanOutput(), constants.GreenColor(name))
+	} else {
+		fmt.Fprintf(stew.HumanOutput(), "%v %v\n", constants.GreenColor(name), constants.YellowColor("(not installed)"))
+	}
+
+	printInfoField("Source", pkg.Source)
+	if pkg.Source == "github" {
+		printInfoField("Repo", pkg.Owner+"/"+pkg.Repo)
+	}
+	printInfoField("Tag", pkg.Tag)
+	printInfoField("Asset", pkg.Asset)
+	if !info.Installed && pkg.Source == "github" && pkg.Asset == "" {
+		detectedAsset := constants.YellowColor(fmt.Sprintf("no %v/%v asset detected", userOS, userArch))
+		if info.DetectedAsset != "" {
+			detectedAsset = fmt.Sprintf("%v (detected for %v/%v)", info.DetectedAsset, userOS, userArch)
+		}
+		printInfoField("Asset", detectedAsset)
+	}
+	printInfoField("URL", pkg.URL)
+	printInfoField("URL template", pkg.URLTemplate)
+	if pkg.VersionSource != nil {
+		printInfoField("Version source", fmt.Sprintf("%v (%v)", pkg.VersionSource.URL, pkg.VersionSource.Type))
+	}
+	if hooks := stew.PackageHooks(pkg); hooks != nil {
+		printInfoField("postInstall", hooks.PostInstall)
+		printInfoField("postUpgrade", hooks.PostUpgrade)
+		printInfoField("preUninstall", hooks.PreUninstall)
+	}
+	printInfoField("Binary hash", pkg.BinaryHash)
+	printInfoField("Asset hash", pkg.AssetHash)
+	printInfoField("Binary path", info.BinaryPath)
+	printInfoField("Link target", info.LinkTarget)
+	printInfoField("Package path", info.PackagePath)
+	printInfoField("Entrypoints", strings.Join(pkg.Entrypoints, ", "))
+	printInfoField("Extra files", strings.Join(pkg.ExtraFiles, ", "))
+	if info.BinarySize > 0 {
+		printInfoField("Binary size", stew.FormatBytes(info.BinarySize))
+	}
+
+	if info.Repo != nil {
+		printInfoField("Description", info.Repo.Description)
+		license := "none"
+		if info.Repo.License != nil {
+			license = info.Repo.License.SPDXID
+			if license == "" || license == "NOASSERTION" {
+				license = info.Repo.License.Name
+			}
+		}
+		printInfoField("License", license)
+		printInfoField("Stars", fmt.Sprint(info.Repo.Stars))
+		if info.Repo.Archived {
+			printInfoField("Archived", constants.YellowColor("yes"))
+		} else {
+			printInfoField("Archived", "no")
+		}
+		printInfoField("Homepage", info.Repo.HTMLURL)
+	}
+
+	latestRelease := info.LatestTag
+	if latestRelease == "" && info.Repo != nil {
+		latestRelease = "none"
+	}
+	if info.Installed && info.LatestTag != "" {
+		switch {
+		case info.ReleasesBehind == nil:
+			latestRelease += constants.YellowColor(fmt.Sprintf(" (%v is not a release anymore)", pkg.Tag))
+		case *info.ReleasesBehind == 0:
+			latestRelease += constants.GreenColor(" (up to date)")
+		case *info.ReleasesBehind == 1:
+			latestRelease += constants.YellowColor(" (1 release behind)")
+		default:
+			latestRelease += constants.YellowColor(fmt.Sprintf(" (%v releases behind)", *info.ReleasesBehind))
+		}
+	}
+	printInfoField("Latest release", latestRelease)
+}
@@ -3,26 +3,43 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
+	"strconv"
 
 	"github.com/marwanhawari/stew/constants"
 	stew "github.com/marwanhawari/stew/lib"
 )
 
 // Install is executed when you run `stew install`
-func Install(cliInput string) {
+func Install(cliInput string, cliOfflineFlag bool, cliMirrorPath string, cliVersion, cliVersionURL, cliVersionPath, cliVersionRegex string) {
 	userOS, userArch, _, systemInfo, err := stew.Initialize()
 	stew.CatchAndExit(err)
 
-	if filepath.Base(cliInput) == "Stewfile.lock.json" {
-		pkgs, err := stew.ReadStewLockFileContents(cliInput)
+	var unlock func()
+	systemInfo, unlock, err = stew.LockStewPath(systemInfo)
+	stew.CatchAndExit(err)
+	defer unlock()
+
+	installingFromLockFile := filepath.Base(cliInput) == "Stewfile.lock.json"
+	if (cliOfflineFlag || cliMirrorPath != "") && !installingFromLockFile {
+		stew.CatchAndExit(stew.OfflineInputError{})
+	}
+
+	if installingFromLockFile {
+		lockFile, err := stew.ReadLockFileJSON(cliInput)
 		stew.CatchAndExit(err)
-		if len(pkgs) == 0 {
+		if len(lockFile.Packages) == 0 {
 			stew.CatchAndExit(stew.EmptyCLIInputError{})
 		}
-		err = installFromLockFile(pkgs, userOS, userArch, systemInfo)
+		source := assetSource{offline: cliOfflineFlag, mirrorPath: cliMirrorPath, mirrorManifest: stew.MirrorManifest{}}
+		if cliMirrorPath != "" {
+			source.mirrorManifest, err = stew.ReadMirrorManifest(cliMirrorPath)
+			stew.CatchAndExit(err)
+		}
+		err = installFromLockFile(lockFile, userOS, userArch, systemInfo, source)
 		stew.CatchAndExit(err)
-	} else if filepath.Base(cliInput) == "Stewfile" {
+	} else if stew.IsStewfile(cliInput) {
 		pkgs, err := stew.ReadStewfileContents(cliInput)
 		stew.CatchAndExit(err)
 		if len(pkgs) == 0 {
@@ -32,28 +49,23 @@ func Install(cliInput string) {
 	} else {
 		pkg, err := stew.ParseCLIInput(cliInput)
 		stew.CatchAndExit(err)
+		pkg.VersionSource, err = stew.NewVersionSource(cliVersionURL, cliVersionPath, cliVersionRegex)
+		stew.CatchAndExit(err)
+		if (pkg.VersionSource != nil || cliVersion != "") && pkg.URLTemplate == "" {
+			stew.CatchAndExit(stew.InvalidVersionSourceError{Reason: "a version or version source can only be used with a URL template"})
+		}
+		if cliVersion != "" {
+			pkg.Tag = cliVersion
+		}
 		err = installOne(pkg, userOS, userArch, systemInfo, false)
 		stew.CatchAndExit(err)
 	}
 }
 
 func installOne(pkg stew.PackageData, userOS, userArch string, systemInfo stew.SystemInfo, installingFromLockFile bool) error {
-	sp := constants.LoadingSpinner
-
-	stewBinPath := systemInfo.StewBinPath
-	stewPkgPath := systemInfo.StewPkgPath
 	stewLockFilePath := systemInfo.StewLockFilePath
 	stewTmpPath := systemInfo.StewTmpPath
 
-	source := pkg.Source
-	owner := pkg.Owner
-	repo := pkg.Repo
-	tag := pkg.Tag
-	asset := pkg.Asset
-	desiredBinaryRename := pkg.Binary
-	expectedBinaryHash := pkg.BinaryHash
-	downloadURL := pkg.URL
-
 	lockFile, err := stew.NewLockFile(stewLockFilePath, userOS, userArch)
 	if err != nil {
 		return err
@@ -66,130 +78,394 @@ func installOne(pkg stew.PackageData, userOS, userArch string, systemInfo stew.S
 		return err
 	}
 
-	var githubProject stew.GithubProject
-	if source == "github" {
-		fmt.Println(constants.GreenColor(owner + "/" + repo))
-		sp.Start()
-		githubProject, err = stew.NewGithubProject(owner, repo)
-		sp.Stop()
-		if err != nil {
-			return err
-		}
+	pkg, err = resolvePackage(pkg, userOS, userArch)
+	if err != nil {
+		return err
+	}
 
-		releaseTags, err := stew.GetGithubReleasesTags(githubProject)
-		if err != nil {
-			return err
-		}
+	stagedPkg, err := downloadAndStage(pkg, systemInfo)
+	if err != nil {
+		return err
+	}
 
-		if tag == "" || tag == "latest" {
-			// Find first non-prerelease tag
-			for _, release := range githubProject.Releases {
-				if !release.Prerelease {
-					tag = release.TagName
-					break
-				}
+	var previousTag string
+	indexInLockFile, binaryFoundInLockFile := stew.FindBinaryInLockFile(lockFile, stagedPkg.Package.Binary)
+	if binaryFoundInLockFile {
+		if !installingFromLockFile {
+			if err = stew.ConfirmBinaryOverwrite(lockFile, stagedPkg.Package.Binary); err != nil {
+				return err
 			}
 		}
+		previousTag = lockFile.Packages[indexInLockFile].Tag
+	}
 
-		tagIndex, tagFound := stew.Contains(releaseTags, tag)
-		if !tagFound {
-			tag, err = stew.WarningPromptSelect(fmt.Sprintf("Could not find a release with the tag %v - please select a release:", constants.YellowColor(tag)), releaseTags)
-			if err != nil {
-				return err
+	packageData, err := installStaged(stagedPkg, systemInfo, userOS, userArch)
+	if err != nil {
+		return err
+	}
+
+	finishInstall(packageData, systemInfo, previousTag)
+	return nil
+}
+
+// finishInstall reports an installed package, runs its postInstall hook, and emits its result
+func finishInstall(packageData stew.PackageData, systemInfo stew.SystemInfo, previousTag string) {
+	fmt.Fprintf(stew.HumanOutput(), "✨ Successfully installed the %v binary in %v\n", constants.GreenColor(packageData.Binary), constants.GreenColor(systemInfo.StewBinPath))
+	hookErr := runPostHook(stew.PostInstallHook, packageData, systemInfo, previousTag)
+	stew.EmitResult(stew.NewSuccessResult(packageData).WithHookError(hookErr))
+}
+
+// downloadAndStage downloads the asset of a resolved package and verifies its binary in the tmp directory.
+// Nothing in the stew directory changes until the staged package is installed.
+func downloadAndStage(pkg stew.PackageData, systemInfo stew.SystemInfo) (stew.StagedPackage, error) {
+	stagingPath := filepath.Join(systemInfo.StewTmpPath, "staged")
+	if err := os.RemoveAll(stagingPath); err != nil {
+		return stew.StagedPackage{}, err
+	}
+	if err := os.MkdirAll(stagingPath, 0755); err != nil {
+		return stew.StagedPackage{}, err
+	}
+
+	downloadPath := filepath.Join(stagingPath, pkg.Asset)
+	assetHash, err := stew.DownloadAsset(systemInfo.StewCachePath, downloadPath, pkg.URL, pkg.AssetHash)
+	if err != nil {
+		return stew.StagedPackage{}, err
+	}
+	fmt.Fprintf(stew.HumanOutput(), "✅ Downloaded %v to %v\n", constants.GreenColor(pkg.Asset), constants.GreenColor(systemInfo.StewPkgPath))
+	pkg.AssetHash = assetHash
+
+	stagedPkg, err := stew.StagePackage(pkg, downloadPath, stagingPath)
+	if err != nil {
+		return stew.StagedPackage{}, err
+	}
+	stagedPkg.Package.Hooks = stew.PackageHooks(stagedPkg.Package)
+	return stagedPkg, nil
+}
+
+// installStaged installs a single staged package together with its lockfile entry.
+// If it can't be installed, the previous binary, asset, and lockfile are restored.
+func installStaged(stagedPkg stew.StagedPackage, systemInfo stew.SystemInfo, userOS, userArch string) (stew.PackageData, error) {
+	stagedPkgs := []stew.StagedPackage{stagedPkg}
+	err := stew.InstallStagedPackages(stagedPkgs, systemInfo, userOS, userArch)
+	if err != nil {
+		if _, rollbackFailed := err.(stew.RollbackError); !rollbackFailed {
+			fmt.Fprintf(stew.HumanOutput(), "↩️  Restored the previous binary and lockfile\n")
+		}
+		return stew.PackageData{}, err
+	}
+	return stagedPkgs[0].Package, os.RemoveAll(filepath.Join(systemInfo.StewTmpPath, "staged"))
+}
+
+// runPostHook runs a hook after a package was installed or upgraded. A failed hook is only reported, because the binary is already in place.
+func runPostHook(hook string, pkg stew.PackageData, systemInfo stew.SystemInfo, previousTag string) error {
+	err := stew.RunHook(hook, pkg, filepath.Join(systemInfo.StewBinPath, pkg.Binary), previousTag)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+	return err
+}
+
+// resolvePackage finds the release tag, asset, and download URL of a package
+func resolvePackage(pkg stew.PackageData, userOS, userArch string) (stew.PackageData, error) {
+	sp := constants.LoadingSpinner
+
+	source := pkg.Source
+	owner := pkg.Owner
+	repo := pkg.Repo
+	tag := pkg.Tag
+	asset := pkg.Asset
+
+	if source != "github" && pkg.URLTemplate != "" {
+		return resolveURLTemplate(pkg, userOS, userArch)
+	}
+
+	if source != "github" {
+		fmt.Fprintln(stew.HumanOutput(), constants.GreenColor(asset))
+		return stew.PackageData{
+			Source:      "other",
+			Asset:       asset,
+			Binary:      pkg.Binary,
+			URL:         pkg.URL,
+			BinaryHash:  pkg.BinaryHash,
+			AssetHash:   pkg.AssetHash,
+			Platforms:   pkg.Platforms,
+			Hooks:       pkg.Hooks,
+			Entrypoints: pkg.Entrypoints,
+			Wrappers:    pkg.Wrappers,
+		}, nil
+	}
+
+	fmt.Fprintln(stew.HumanOutput(), constants.GreenColor(owner+"/"+repo))
+	sp.Start()
+	githubProject, err := stew.NewGithubProject(owner, repo)
+	sp.Stop()
+	if err != nil {
+		return stew.PackageData{}, err
+	}
+
+	releaseTags, err := stew.GetGithubReleasesTags(githubProject)
+	if err != nil {
+		return stew.PackageData{}, err
+	}
+
+	if tag == "" || tag == "latest" {
+		// Find first non-prerelease tag
+		for _, release := range githubProject.Releases {
+			if !release.Prerelease {
+				tag = release.TagName
+				break
 			}
-			tagIndex, _ = stew.Contains(releaseTags, tag)
 		}
+	}
 
-		releaseAssets, err := stew.GetGithubReleasesAssets(githubProject, tag)
+	tagIndex, tagFound := stew.Contains(releaseTags, tag)
+	if !tagFound {
+		if stew.IsNonInteractive() {
+			return stew.PackageData{}, stew.NonInteractiveError{Prompt: "Could not find a release with the tag " + tag, Resolution: "Use one of the release tags with owner/repo@tag"}
+		}
+		tag, err = stew.WarningPromptSelect(fmt.Sprintf("Could not find a release with the tag %v - please select a release:", constants.YellowColor(tag)), releaseTags)
 		if err != nil {
-			return err
+			return stew.PackageData{}, err
 		}
+		tagIndex, _ = stew.Contains(releaseTags, tag)
+	}
 
-		if asset == "" {
-			asset, err = stew.DetectAsset(userOS, userArch, releaseAssets)
+	releaseAssets, err := stew.GetGithubReleasesAssets(githubProject, tag)
+	if err != nil {
+		return stew.PackageData{}, err
+	}
+
+	if asset == "" {
+		asset, err = stew.DetectAsset(userOS, userArch, releaseAssets)
+	}
+	if err != nil {
+		return stew.PackageData{}, err
+	}
+
+	assetIndex, assetFound := stew.Contains(releaseAssets, asset)
+	if !assetFound {
+		if stew.IsNonInteractive() {
+			return stew.PackageData{}, stew.NonInteractiveError{Prompt: "Could not find the asset " + asset, Resolution: "Use one of the release assets with the asset= option in a Stewfile"}
 		}
+		asset, err = stew.WarningPromptSelect(fmt.Sprintf("Could not find the asset %v - please select an asset:", constants.YellowColor(asset)), releaseAssets)
 		if err != nil {
-			return err
+			return stew.PackageData{}, err
 		}
+		assetIndex, _ = stew.Contains(releaseAssets, asset)
+	}
 
-		assetIndex, assetFound := stew.Contains(releaseAssets, asset)
-		if !assetFound {
-			asset, err = stew.WarningPromptSelect(fmt.Sprintf("Could not find the asset %v - please select an asset:", constants.YellowColor(asset)), releaseAssets)
-			if err != nil {
-				return err
-			}
-			assetIndex, _ = stew.Contains(releaseAssets, asset)
+	// The asset hash only applies to the asset of the same release
+	assetHash := pkg.AssetHash
+	if tag != pkg.Tag || asset != pkg.Asset {
+		assetHash = ""
+	}
+
+	return stew.PackageData{
+		Source:      "github",
+		Owner:       githubProject.Owner,
+		Repo:        githubProject.Repo,
+		Tag:         tag,
+		Asset:       asset,
+		Binary:      pkg.Binary,
+		URL:         githubProject.Releases[tagIndex].Assets[assetIndex].DownloadURL,
+		BinaryHash:  pkg.BinaryHash,
+		AssetHash:   assetHash,
+		Platforms:   pkg.Platforms,
+		Hooks:       pkg.Hooks,
+		Entrypoints: pkg.Entrypoints,
+		Wrappers:    pkg.Wrappers,
+	}, nil
+}
+
+// installFromLockFile downloads and verifies every binary in the lockfile before installing any of them.
+// Either all of the binaries are installed, or none of them are.
+func installFromLockFile(lockFile stew.LockFile, userOS, userArch string, systemInfo stew.SystemInfo, source assetSource) error {
+	stagedPkgs := []stew.StagedPackage{}
+	failedPkgs := []stew.PackageData{}
+	failedErrs := []error{}
+
+	for index, pkg := range lockFile.Packages {
+		stagedPkg, err := stageOne(pkg, lockFile.Os, lockFile.Arch, userOS, userArch, systemInfo, source, filepath.Join(systemInfo.StewTmpPath, "staged", strconv.Itoa(index)))
+		if err != nil {
+			failedPkgs = append(failedPkgs, pkg)
+			failedErrs = append(failedErrs, err)
+			continue
 		}
+		stagedPkgs = append(stagedPkgs, stagedPkg)
+	}
 
-		downloadURL = githubProject.Releases[tagIndex].Assets[assetIndex].DownloadURL
-	} else {
-		fmt.Println(constants.GreenColor(asset))
+	if len(failedPkgs) != 0 {
+		fmt.Fprintf(stew.HumanOutput(), "\n❌ The lockfile was not installed. These binaries failed:\n")
+		for index, pkg := range failedPkgs {
+			fmt.Fprintf(stew.HumanOutput(), "  %v: %v\n", constants.RedColor(lockFilePackageName(pkg)), failedErrs[index])
+			stew.EmitResult(stew.NewErrorResult(pkg.Binary, &failedPkgs[index], failedErrs[index]))
+		}
+		return stew.LockFileInstallError{Failed: len(failedPkgs), Total: len(lockFile.Packages)}
 	}
 
-	downloadPath := filepath.Join(stewPkgPath, asset)
-	err = stew.DownloadFile(downloadPath, downloadURL)
+	err := stew.InstallStagedPackages(stagedPkgs, systemInfo, userOS, userArch)
 	if err != nil {
+		if _, rollbackFailed := err.(stew.RollbackError); !rollbackFailed {
+			fmt.Fprintf(stew.HumanOutput(), "↩️  Restored the previous binaries and lockfile\n")
+		}
 		return err
 	}
-	fmt.Printf("✅ Downloaded %v to %v\n", constants.GreenColor(asset), constants.GreenColor(stewPkgPath))
 
-	binaryName, binaryHash, err := stew.InstallBinary(downloadPath, repo, systemInfo, &lockFile, installingFromLockFile, desiredBinaryRename, expectedBinaryHash)
+	for _, stagedPkg := range stagedPkgs {
+		fmt.Fprintf(stew.HumanOutput(), "✨ Successfully installed the %v binary in %v\n", constants.GreenColor(stagedPkg.Package.Binary), constants.GreenColor(systemInfo.StewBinPath))
+	}
+	// The hooks run once every binary is installed, so that a hook can use the other binaries of the lockfile
+	for _, stagedPkg := range stagedPkgs {
+		hookErr := runPostHook(stew.PostInstallHook, stagedPkg.Package, systemInfo, "")
+		stew.EmitResult(stew.NewSuccessResult(stagedPkg.Package).WithHookError(hookErr))
+	}
+	return nil
+}
+
+// assetSource contains where the assets of a lockfile install can come from besides the download cache and the network
+type assetSource struct {
+	offline        bool
+	mirrorPath     string
+	mirrorManifest stew.MirrorManifest
+}
+
+// stageOne downloads a package from the lockfile and verifies its binary in the stagingPath
+func stageOne(pkg stew.PackageData, lockFileOS, lockFileArch, userOS, userArch string, systemInfo stew.SystemInfo, source assetSource, stagingPath string) (stew.StagedPackage, error) {
+	platformPkg, err := stew.ResolvePackageForPlatform(pkg, lockFileOS, lockFileArch, userOS, userArch)
 	if err != nil {
-		if err := os.RemoveAll(downloadPath); err != nil {
-			return err
+		return stew.StagedPackage{}, err
+	}
+	if platformPkg.Asset == "" && pkg.Asset != "" {
+		fmt.Fprintf(stew.HumanOutput(), "%v The lockfile does not contain an asset of the %v binary for %v, detecting it instead\n", constants.YellowColor("WARNING:"), constants.YellowColor(pkg.Binary), constants.YellowColor(stew.PlatformKey(userOS, userArch)))
+	}
+
+	if err = os.MkdirAll(stagingPath, 0755); err != nil {
+		return stew.StagedPackage{}, err
+	}
+
+	// A mirrored or cached asset is installed without looking up the release, so that no network access is needed
+	var mirrorAssetPath string
+	if source.mirrorPath != "" && platformPkg.Asset != "" {
+		mirrorAssetPath, err = stew.FindMirrorAsset(source.mirrorPath, source.mirrorManifest, platformPkg)
+		if err != nil {
+			return stew.StagedPackage{}, err
 		}
-		return err
+	}
+	assetCached, err := stew.IsAssetCached(systemInfo.StewCachePath, platformPkg.AssetHash)
+	if err != nil {
+		return stew.StagedPackage{}, err
 	}
 
-	var packageData stew.PackageData
-	if source == "github" {
-		packageData = stew.PackageData{
-			Source:     "github",
-			Owner:      githubProject.Owner,
-			Repo:       githubProject.Repo,
-			Tag:        tag,
-			Asset:      asset,
-			Binary:     binaryName,
-			URL:        downloadURL,
-			BinaryHash: binaryHash,
+	switch {
+	case mirrorAssetPath != "":
+		fmt.Fprintln(stew.HumanOutput(), constants.GreenColor(lockFilePackageName(platformPkg)))
+		platformPkg.AssetHash, err = stew.CopyMirrorAsset(mirrorAssetPath, filepath.Join(stagingPath, platformPkg.Asset))
+		if err != nil {
+			return stew.StagedPackage{}, err
 		}
-	} else {
-		packageData = stew.PackageData{
-			Source:     "other",
-			Owner:      "",
-			Repo:       "",
-			Tag:        "",
-			Asset:      asset,
-			Binary:     binaryName,
-			URL:        downloadURL,
-			BinaryHash: binaryHash,
+		fmt.Fprintf(stew.HumanOutput(), "📦 Using the mirrored %v\n", constants.GreenColor(platformPkg.Asset))
+	case assetCached && platformPkg.Asset != "":
+		fmt.Fprintln(stew.HumanOutput(), constants.GreenColor(lockFilePackageName(platformPkg)))
+		downloadPath := filepath.Join(stagingPath, platformPkg.Asset)
+		copiedFromCache, err := stew.CopyCachedAsset(systemInfo.StewCachePath, downloadPath, platformPkg.URL, platformPkg.AssetHash)
+		if err != nil {
+			return stew.StagedPackage{}, err
+		}
+		// The cached asset was removed because it no longer matches its hash
+		if !copiedFromCache && source.offline {
+			return stew.StagedPackage{}, stew.AssetNotAvailableOfflineError{Asset: platformPkg.Asset}
+		}
+		if !copiedFromCache {
+			platformPkg.AssetHash, err = stew.DownloadAsset(systemInfo.StewCachePath, downloadPath, platformPkg.URL, platformPkg.AssetHash)
+			if err != nil {
+				return stew.StagedPackage{}, err
+			}
+		}
+	case source.offline:
+		asset := platformPkg.Asset
+		if asset == "" {
+			asset = lockFilePackageName(platformPkg) + " (" + stew.PlatformKey(userOS, userArch) + ")"
+		}
+		return stew.StagedPackage{}, stew.AssetNotAvailableOfflineError{Asset: asset}
+	default:
+		platformPkg, err = resolvePackage(platformPkg, userOS, userArch)
+		if err != nil {
+			return stew.StagedPackage{}, err
+		}
+		platformPkg.AssetHash, err = stew.DownloadAsset(systemInfo.StewCachePath, filepath.Join(stagingPath, platformPkg.Asset), platformPkg.URL, platformPkg.AssetHash)
+		if err != nil {
+			return stew.StagedPackage{}, err
 		}
 	}
+	downloadPath := filepath.Join(stagingPath, platformPkg.Asset)
 
-	indexInLockFile, binaryFoundInLockFile := stew.FindBinaryInLockFile(lockFile, binaryName)
-	if installingFromLockFile && binaryFoundInLockFile {
-		lockFile.Packages[indexInLockFile] = packageData
-	} else {
-		lockFile.Packages = append(lockFile.Packages, packageData)
+	stagedPkg, err := stew.StagePackage(platformPkg, downloadPath, stagingPath)
+	if err != nil {
+		return stew.StagedPackage{}, err
 	}
+	stagedPkg.Package.Hooks = stew.PackageHooks(stagedPkg.Package)
+	fmt.Fprintf(stew.HumanOutput(), "✅ Verified the %v binary\n", constants.GreenColor(stagedPkg.Package.Binary))
+	return stagedPkg, nil
+}
 
-	err = stew.WriteLockFileJSON(lockFile, stewLockFilePath)
+// resolveURLTemplate finds the latest version of a package installed from a URL template, unless the version is pinned, and renders its URL
+func resolveURLTemplate(pkg stew.PackageData, userOS, userArch string) (stew.PackageData, error) {
+	sp := constants.LoadingSpinner
+	fmt.Fprintln(stew.HumanOutput(), constants.GreenColor(pkg.URLTemplate))
+
+	version := pkg.Tag
+	if version == "" || version == "latest" {
+		if pkg.VersionSource == nil {
+			return stew.PackageData{}, stew.InvalidVersionSourceError{Reason: "a URL template needs a version or a version source"}
+		}
+		sp.Start()
+		latestVersion, err := stew.FindLatestVersion(*pkg.VersionSource)
+		sp.Stop()
+		if err != nil {
+			return stew.PackageData{}, err
+		}
+		version = latestVersion
+	}
+
+	url, err := stew.RenderURLTemplate(pkg.URLTemplate, version, userOS, userArch)
 	if err != nil {
-		return err
+		return stew.PackageData{}, err
 	}
 
-	fmt.Printf("✨ Successfully installed the %v binary in %v\n", constants.GreenColor(binaryName), constants.GreenColor(stewBinPath))
-	return nil
+	// The asset hash only applies to the asset of the same URL
+	assetHash := pkg.AssetHash
+	if url != pkg.URL {
+		assetHash = ""
+	}
+
+	return stew.PackageData{
+		Source:        "other",
+		Tag:           version,
+		Asset:         path.Base(url),
+		Binary:        pkg.Binary,
+		URL:           url,
+		BinaryHash:    pkg.BinaryHash,
+		AssetHash:     assetHash,
+		Platforms:     pkg.Platforms,
+		URLTemplate:   pkg.URLTemplate,
+		VersionSource: pkg.VersionSource,
+		Hooks:         pkg.Hooks,
+		Entrypoints:   pkg.Entrypoints,
+		Wrappers:      pkg.Wrappers,
+	}, nil
 }
 
-func installFromLockFile(pkgs []stew.PackageData, userOS, userArch string, systemInfo stew.SystemInfo) error {
-	for _, pkg := range pkgs {
-		err := installOne(pkg, userOS, userArch, systemInfo, true)
-		if err != nil {
-			return err
-		}
+func lockFilePackageName(pkg stew.PackageData) string {
+	if pkg.Binary != "" {
+		return pkg.Binary
 	}
-	return nil
+	if pkg.Source == "github" {
+		return pkg.Owner + "/" + pkg.Repo
+	}
+	if pkg.URLTemplate != "" {
+		return pkg.URLTemplate
+	}
+	return pkg.URL
 }
 
 func installFromStewfile(pkgs []stew.PackageData, userOS, userArch string, systemInfo stew.SystemInfo) {
@@ -197,6 +473,7 @@ func installFromStewfile(pkgs []stew.PackageData, userOS, userArch string, syste
 		err := installOne(pkg, userOS, userArch, systemInfo, false)
 		if err != nil {
 			fmt.Fprintln(os.Stderr, err)
+			stew.EmitResult(stew.NewErrorResult(pkg.Binary, &pkg, err))
 			continue
 		}
 	}
@@ -2,7 +2,6 @@ package cmd
 
 import (
 	"fmt"
-	"os"
 
 	"github.com/gookit/color"
 	"github.com/marwanhawari/stew/constants"
@@ -12,7 +11,7 @@ import (
 
 // List is executed when you run `stew list`
 func List(cliTagsFlag bool) {
-	if !term.IsTerminal(int(os.Stdout.Fd())) {
+	if !term.IsTerminal(int(stew.HumanOutput().Fd())) {
 		color.Disable()
 	}
 
@@ -29,15 +28,19 @@ func List(cliTagsFlag bool) {
 	}
 
 	for _, pkg := range lockFile.Packages {
+		if stew.IsStructuredOutput() {
+			stew.EmitResult(stew.NewSuccessResult(pkg))
+			continue
+		}
 		switch pkg.Source {
 		case "other":
-			fmt.Println(constants.GreenColor(pkg.Binary+":") + pkg.URL)
+			fmt.Fprintln(stew.HumanOutput(), constants.GreenColor(pkg.Binary+":")+pkg.URL)
 		case "github":
 			defaultLine := constants.GreenColor(pkg.Binary+":") + pkg.Owner + "/" + pkg.Repo
 			if cliTagsFlag {
-				fmt.Println(defaultLine + "@" + pkg.Tag)
+				fmt.Fprintln(stew.HumanOutput(), defaultLine+"@"+pkg.Tag)
 			} else {
-				fmt.Println(defaultLine)
+				fmt.Fprintln(stew.HumanOutput(), defaultLine)
 			}
 		}
 	}
@@ -0,0 +1,208 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/marwanhawari/stew/constants"
+	stew "github.com/marwanhawari/stew/lib"
+)
+
+// Lock is executed when you run `stew lock`
+func Lock(cliInput string, cliPlatforms []string, cliForceFlag bool) {
+	userOS, userArch, _, systemInfo, err := stew.Initialize()
+	stew.CatchAndExit(err)
+
+	var unlock func()
+	systemInfo, unlock, err = stew.LockStewPath(systemInfo)
+	stew.CatchAndExit(err)
+	defer unlock()
+
+	platforms := cliPlatforms
+	if len(platforms) == 0 {
+		platforms = []string{stew.PlatformKey(userOS, userArch)}
+	}
+	for _, platform := range platforms {
+		_, _, err := stew.ParsePlatform(platform)
+		stew.CatchAndExit(err)
+	}
+
+	var lockFile stew.LockFile
+	var lockFilePath string
+	switch {
+	case cliInput == "":
+		lockFilePath = systemInfo.StewLockFilePath
+		lockFile, err = stew.NewLockFile(lockFilePath, userOS, userArch)
+		stew.CatchAndExit(err)
+	case filepath.Base(cliInput) == "Stewfile.lock.json":
+		lockFilePath = cliInput
+		lockFile, err = stew.ReadLockFileJSON(lockFilePath)
+		stew.CatchAndExit(err)
+	case stew.IsStewfile(cliInput):
+		lockFilePath = filepath.Join(filepath.Dir(cliInput), "Stewfile.lock.json")
+		if !cliForceFlag {
+			err = confirmLockFileOverwrite(lockFilePath)
+			stew.CatchAndExit(err)
+		}
+		pkgs, err := stew.ReadStewfileContents(cliInput)
+		stew.CatchAndExit(err)
+		lockFile = stew.LockFile{Os: userOS, Arch: userArch, Packages: pkgs}
+	default:
+		stew.CatchAndExit(stew.UnrecognizedInputError{})
+	}
+
+	if len(lockFile.Packages) == 0 {
+		stew.CatchAndExit(stew.NoBinariesInstalledError{})
+	}
+
+	failed := 0
+	for index, pkg := range lockFile.Packages {
+		if pkg.Source != "github" {
+			fmt.Fprintf(stew.HumanOutput(), "%v (Installed from a URL)\n", constants.YellowColor(lockFilePackageName(pkg)))
+			continue
+		}
+		lockedPkg, err := lockOne(pkg, lockFile.Os, lockFile.Arch, platforms, systemInfo)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			failed++
+			continue
+		}
+		lockFile.Packages[index] = lockedPkg
+	}
+
+	err = stew.WriteLockFileJSON(lockFile, lockFilePath)
+	stew.CatchAndExit(err)
+
+	if failed > 0 {
+		stew.CatchAndExit(stew.LockPackagesError{Failed: failed, Total: len(lockFile.Packages)})
+	}
+}
+
+// confirmLockFileOverwrite asks before the lockfile next to a Stewfile is replaced
+func confirmLockFileOverwrite(lockFilePath string) error {
+	lockFileExists, err := stew.PathExists(lockFilePath)
+	if err != nil || !lockFileExists {
+		return err
+	}
+	message := fmt.Sprintf("The lockfile %v already exists, would you like to overwrite it?", constants.YellowColor(lockFilePath))
+	if stew.IsNonInteractive() {
+		return stew.NonInteractiveError{Prompt: message, Resolution: "Use stew lock --force to replace it"}
+	}
+	userChoosingToOverwrite, err := stew.WarningPromptConfirm(message)
+	if err != nil {
+		return err
+	}
+	if !userChoosingToOverwrite {
+		return stew.AbortLockFileOverwriteError{LockFilePath: lockFilePath}
+	}
+	return nil
+}
+
+func lockOne(pkg stew.PackageData, lockFileOS, lockFileArch string, platforms []string, systemInfo stew.SystemInfo) (stew.PackageData, error) {
+	sp := constants.LoadingSpinner
+	stewTmpPath := systemInfo.StewTmpPath
+
+	fmt.Fprintln(stew.HumanOutput(), constants.GreenColor(pkg.Owner+"/"+pkg.Repo))
+	sp.Start()
+	githubProject, err := stew.NewGithubProject(pkg.Owner, pkg.Repo)
+	sp.Stop()
+	if err != nil {
+		return stew.PackageData{}, err
+	}
+
+	releaseTags, err := stew.GetGithubReleasesTags(githubProject)
+	if err != nil {
+		return stew.PackageData{}, err
+	}
+
+	tag := pkg.Tag
+	if tag == "" || tag == "latest" {
+		// Find first non-prerelease tag
+		for _, release := range githubProject.Releases {
+			if !release.Prerelease {
+				tag = release.TagName
+				break
+			}
+		}
+	}
+
+	tagIndex, tagFound := stew.Contains(releaseTags, tag)
+	if !tagFound {
+		if stew.IsNonInteractive() {
+			return stew.PackageData{}, stew.NonInteractiveError{Prompt: "Could not find a release with the tag " + tag, Resolution: "Use one of the release tags with owner/repo@tag"}
+		}
+		tag, err = stew.WarningPromptSelect(fmt.Sprintf("Could not find a release with the tag %v - please select a release:", constants.YellowColor(tag)), releaseTags)
+		if err != nil {
+			return stew.PackageData{}, err
+		}
+		tagIndex, _ = stew.Contains(releaseTags, tag)
+	}
+
+	releaseAssets, err := stew.GetGithubReleasesAssets(githubProject, tag)
+	if err != nil {
+		return stew.PackageData{}, err
+	}
+
+	platformAssets := map[string]stew.PlatformAsset{}
+	if pkg.Tag == tag {
+		for platform, platformAsset := range pkg.Platforms {
+			platformAssets[platform] = platformAsset
+		}
+	}
+
+	lockFilePlatform := stew.PlatformKey(lockFileOS, lockFileArch)
+	lockedBinary := pkg.Binary
+	for _, platform := range platforms {
+		platformOS, platformArch, err := stew.ParsePlatform(platform)
+		if err != nil {
+			return stew.PackageData{}, err
+		}
+		fmt.Fprintf(stew.HumanOutput(), "🔒 Locking %v\n", constants.GreenColor(platform))
+
+		var asset string
+		if _, assetFound := stew.Contains(releaseAssets, pkg.Asset); assetFound && platform == lockFilePlatform {
+			asset = pkg.Asset
+		} else {
+			asset, err = stew.DetectAsset(platformOS, platformArch, releaseAssets)
+			if err != nil {
+				return stew.PackageData{}, err
+			}
+		}
+		assetIndex, _ := stew.Contains(releaseAssets, asset)
+		downloadURL := githubProject.Releases[tagIndex].Assets[assetIndex].DownloadURL
+
+		downloadPath := filepath.Join(stewTmpPath, asset)
+		assetHash, err := stew.DownloadAsset(systemInfo.StewCachePath, downloadPath, downloadURL, "")
+		if err != nil {
+			return stew.PackageData{}, err
+		}
+
+		// A binary that is named fzf on linux is named fzf.exe on windows
+		binaryName, binaryHash, err := stew.GetBinaryFromAsset(downloadPath, filepath.Join(stewTmpPath, "extracted"), stew.PlatformBinaryName(lockedBinary, platformOS), pkg.Entrypoints)
+		if err != nil {
+			return stew.PackageData{}, err
+		}
+		if err = os.RemoveAll(downloadPath); err != nil {
+			return stew.PackageData{}, err
+		}
+		if lockedBinary == "" {
+			lockedBinary = stew.PlatformBinaryName(binaryName, lockFileOS)
+		}
+
+		platformAssets[platform] = stew.PlatformAsset{Asset: asset, URL: downloadURL, Binary: binaryName, BinaryHash: binaryHash, AssetHash: assetHash}
+	}
+
+	pkg.Tag = tag
+	pkg.Binary = lockedBinary
+	pkg.Platforms = platformAssets
+	if platformAsset, found := platformAssets[lockFilePlatform]; found {
+		pkg.Asset = platformAsset.Asset
+		pkg.URL = platformAsset.URL
+		pkg.Binary = platformAsset.Binary
+		pkg.BinaryHash = platformAsset.BinaryHash
+		pkg.AssetHash = platformAsset.AssetHash
+	}
+
+	return pkg, nil
+}
@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/marwanhawari/stew/constants"
+	stew "github.com/marwanhawari/stew/lib"
+)
+
+// MirrorCreate is executed when you run `stew mirror create`
+func MirrorCreate(cliLockFilePath string, cliMirrorPath string) {
+	if cliLockFilePath == "" || cliMirrorPath == "" {
+		stew.CatchAndExit(stew.EmptyCLIInputError{})
+	}
+
+	_, _, _, systemInfo, err := stew.Initialize()
+	stew.CatchAndExit(err)
+
+	lockFile, err := stew.ReadLockFileJSON(cliLockFilePath)
+	stew.CatchAndExit(err)
+
+	err = os.MkdirAll(cliMirrorPath, 0755)
+	stew.CatchAndExit(err)
+	manifest, err := stew.ReadMirrorManifest(cliMirrorPath)
+	stew.CatchAndExit(err)
+
+	downloadDir, err := os.MkdirTemp("", "stew-mirror-")
+	stew.CatchAndExit(err)
+	defer os.RemoveAll(downloadDir)
+
+	failed, total := 0, 0
+	for _, pkg := range lockFile.Packages {
+		for _, platformPkg := range mirrorPackages(pkg) {
+			total++
+			fmt.Fprintln(stew.HumanOutput(), constants.GreenColor(platformPkg.Asset))
+			if platformPkg.URL == "" {
+				fmt.Fprintln(os.Stderr, stew.NoURLInLockFileError{Binary: lockFilePackageName(pkg)})
+				failed++
+				continue
+			}
+			downloadPath := filepath.Join(downloadDir, platformPkg.Asset)
+			assetHash, err := stew.DownloadAsset(systemInfo.StewCachePath, downloadPath, platformPkg.URL, platformPkg.AssetHash)
+			if err == nil {
+				err = stew.AddMirrorAsset(cliMirrorPath, &manifest, downloadPath, platformPkg.URL, assetHash)
+			}
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				failed++
+				continue
+			}
+			os.RemoveAll(downloadPath)
+		}
+	}
+
+	err = stew.WriteMirrorManifest(cliMirrorPath, manifest)
+	stew.CatchAndExit(err)
+
+	if failed > 0 {
+		// CatchAndExit exits without running the deferred cleanup
+		os.RemoveAll(downloadDir)
+		stew.CatchAndExit(stew.MirrorAssetsError{Failed: failed, Total: total})
+	}
+	fmt.Fprintf(stew.HumanOutput(), "✨ Mirrored %v assets in %v\n", constants.GreenColor(len(manifest.Entries)), constants.GreenColor(cliMirrorPath))
+}
+
+// mirrorPackages returns the package for the lockfile platform and one for each locked platform
+func mirrorPackages(pkg stew.PackageData) []stew.PackageData {
+	pkgs := []stew.PackageData{}
+	seenURLs := map[string]bool{}
+	if pkg.Asset != "" || pkg.URL != "" {
+		pkgs = append(pkgs, pkg)
+		seenURLs[pkg.URL] = true
+	}
+
+	platforms := make([]string, 0, len(pkg.Platforms))
+	for platform := range pkg.Platforms {
+		platforms = append(platforms, platform)
+	}
+	sort.Strings(platforms)
+	for _, platform := range platforms {
+		platformAsset := pkg.Platforms[platform]
+		if seenURLs[platformAsset.URL] {
+			continue
+		}
+		seenURLs[platformAsset.URL] = true
+		platformPkg := pkg
+		platformPkg.Asset = platformAsset.Asset
+		platformPkg.URL = platformAsset.URL
+		platformPkg.BinaryHash = platformAsset.BinaryHash
+		platformPkg.AssetHash = platformAsset.AssetHash
+		pkgs = append(pkgs, platformPkg)
+	}
+	return pkgs
+}
@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/marwanhawari/stew/constants"
+	stew "github.com/marwanhawari/stew/lib"
+)
+
+// Outdated is executed when you run `stew outdated`
+func Outdated(cliChangelogFlag bool) {
+	userOS, userArch, _, systemInfo, err := stew.Initialize()
+	stew.CatchAndExit(err)
+
+	lockFile, err := stew.NewLockFile(systemInfo.StewLockFilePath, userOS, userArch)
+	stew.CatchAndExit(err)
+
+	if len(lockFile.Packages) == 0 {
+		stew.CatchAndExit(stew.NoBinariesInstalledError{})
+	}
+
+	sp := constants.LoadingSpinner
+	var changelogs strings.Builder
+	outdatedCount := 0
+	for _, pkg := range lockFile.Packages {
+		if pkg.Source != "github" && pkg.VersionSource != nil {
+			if outdatedURLTemplate(pkg) {
+				outdatedCount++
+			}
+			continue
+		}
+		if pkg.Source != "github" {
+			continue
+		}
+
+		sp.Start()
+		githubProject, err := stew.NewGithubProject(pkg.Owner, pkg.Repo)
+		sp.Stop()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			stew.EmitResult(stew.NewErrorResult(pkg.Binary, &pkg, err))
+			continue
+		}
+
+		latestTag, latestTagFound := stew.LatestReleaseTag(githubProject.Releases)
+		if !latestTagFound || latestTag == pkg.Tag {
+			stew.EmitResult(stew.Result{Status: "current", Binary: pkg.Binary, Package: &pkg, Info: &stew.PackageInfo{Installed: true, LatestTag: latestTag}})
+			continue
+		}
+
+		info := stew.PackageInfo{Installed: true, LatestTag: latestTag}
+		line := fmt.Sprintf("%v %v → %v", constants.GreenColor(pkg.Binary), pkg.Tag, constants.GreenColor(latestTag))
+		if releasesBehind, tagFound := stew.ReleasesBehind(githubProject.Releases, pkg.Tag); tagFound {
+			info.ReleasesBehind = &releasesBehind
+			if releasesBehind > 1 {
+				line += constants.YellowColor(fmt.Sprintf(" (%v releases behind)", releasesBehind))
+			}
+		}
+		fmt.Fprintln(stew.HumanOutput(), line)
+		outdatedCount++
+		stew.EmitResult(stew.Result{Status: "outdated", Binary: pkg.Binary, Package: &pkg, Info: &info})
+
+		if cliChangelogFlag {
+			releases := stew.ReleasesBetween(githubProject.Releases, pkg.Tag, latestTag)
+			changelogs.WriteString(stew.FormatChangelog(pkg.Binary, pkg.Tag, latestTag, releases))
+		}
+	}
+
+	if outdatedCount == 0 {
+		fmt.Fprintln(stew.HumanOutput(), "✨ All binaries are up to date")
+		return
+	}
+	if cliChangelogFlag {
+		err = stew.ShowInPager(changelogs.String())
+		stew.CatchAndExit(err)
+	}
+}
+
+// outdatedURLTemplate checks if the version source of a package installed from a URL template has a newer version
+func outdatedURLTemplate(pkg stew.PackageData) bool {
+	sp := constants.LoadingSpinner
+
+	sp.Start()
+	latestVersion, err := stew.FindLatestVersion(*pkg.VersionSource)
+	sp.Stop()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		stew.EmitResult(stew.NewErrorResult(pkg.Binary, &pkg, err))
+		return false
+	}
+
+	info := stew.PackageInfo{Installed: true, LatestTag: latestVersion}
+	if stew.CompareVersions(latestVersion, pkg.Tag) <= 0 {
+		stew.EmitResult(stew.Result{Status: "current", Binary: pkg.Binary, Package: &pkg, Info: &info})
+		return false
+	}
+	fmt.Fprintf(stew.HumanOutput(), "%v %v → %v\n", constants.GreenColor(pkg.Binary), pkg.Tag, constants.GreenColor(latestVersion))
+	stew.EmitResult(stew.Result{Status: "outdated", Binary: pkg.Binary, Package: &pkg, Info: &info})
+	return true
+}
@@ -2,19 +2,22 @@ package cmd
 
 import (
 	"fmt"
-	"os"
-	"path/filepath"
 
 	"github.com/marwanhawari/stew/constants"
 	stew "github.com/marwanhawari/stew/lib"
 )
 
 // Rename is executed when you run `stew rename`
-func Rename(cliInput string) {
+func Rename(cliInput string, newBinaryName string) {
 
 	userOS, userArch, _, systemInfo, err := stew.Initialize()
 	stew.CatchAndExit(err)
 
+	var unlock func()
+	systemInfo, unlock, err = stew.LockStewPath(systemInfo)
+	stew.CatchAndExit(err)
+	defer unlock()
+
 	err = stew.ValidateCLIInput(cliInput)
 	stew.CatchAndExit(err)
 
@@ -32,9 +35,15 @@ func Rename(cliInput string) {
 	var renamedBinaryName string
 	for index, pkg := range lockFile.Packages {
 		if pkg.Binary == cliInput {
-			renamedBinaryName, err = stew.PromptRenameBinary(cliInput)
-			stew.CatchAndExit(err)
-			err = os.Rename(filepath.Join(stewBinPath, cliInput), filepath.Join(stewBinPath, renamedBinaryName))
+			renamedBinaryName = newBinaryName
+			if renamedBinaryName == "" {
+				if stew.IsNonInteractive() {
+					stew.CatchAndExit(stew.NonInteractiveError{Prompt: "Rename the binary?", Resolution: "Pass the new name with stew rename " + cliInput + " <new name>"})
+				}
+				renamedBinaryName, err = stew.PromptRenameBinary(cliInput)
+				stew.CatchAndExit(err)
+			}
+			err = stew.RenamePackage(systemInfo.StewPkgPath, stewBinPath, pkg, renamedBinaryName)
 			stew.CatchAndExit(err)
 
 			lockFile.Packages[index].Binary = renamedBinaryName
@@ -49,5 +58,5 @@ func Rename(cliInput string) {
 	err = stew.WriteLockFileJSON(lockFile, stewLockFilePath)
 	stew.CatchAndExit(err)
 
-	fmt.Printf("✨ Successfully renamed the %v binary to %v\n", constants.GreenColor(cliInput), constants.GreenColor(renamedBinaryName))
+	fmt.Fprintf(stew.HumanOutput(), "✨ Successfully renamed the %v binary to %v\n", constants.GreenColor(cliInput), constants.GreenColor(renamedBinaryName))
 }
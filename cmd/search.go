@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"fmt"
 	"net/url"
 	"strings"
 
@@ -8,9 +9,26 @@ import (
 	stew "github.com/marwanhawari/stew/lib"
 )
 
+const (
+	searchActionInstall = "Install the latest release"
+	searchActionBrowse  = "Browse the releases and assets"
+	searchActionReadme  = "View the description and README"
+	searchActionBack    = "Back to the search results"
+	searchPreviousPage  = "⬅️  Previous page of results"
+	searchNextPage      = "➡️  Next page of results"
+)
+
+// searchOptions contains the CLI flags that change which search results are shown
+type searchOptions struct {
+	check       bool
+	installable bool
+	sort        string
+}
+
 // Search is executed when you run `stew search`
-func Search(cliInput []string) {
-	sp := constants.LoadingSpinner
+func Search(cliInput []string, cliCheckFlag, cliInstallableFlag bool, cliSort string, cliPage int, cliInstallFlag, cliBrowseFlag, cliReadmeFlag bool) {
+	userOS, userArch, _, _, err := stew.Initialize()
+	stew.CatchAndExit(err)
 
 	if len(cliInput) == 0 {
 		stew.CatchAndExit(stew.EmptyCLIInputError{})
@@ -20,24 +38,147 @@ func Search(cliInput []string) {
 		stew.CatchAndExit(err)
 	}
 
+	actionFlags := []string{}
+	action := ""
+	if cliInstallFlag {
+		actionFlags = append(actionFlags, "--install")
+		action = searchActionInstall
+	}
+	if cliBrowseFlag {
+		actionFlags = append(actionFlags, "--browse")
+		action = searchActionBrowse
+	}
+	if cliReadmeFlag {
+		actionFlags = append(actionFlags, "--readme")
+		action = searchActionReadme
+	}
+	if len(actionFlags) > 1 {
+		stew.CatchAndExit(stew.ConflictingFlagsError{Flags: actionFlags})
+	}
+
 	searchQuery := url.QueryEscape(strings.Join(cliInput, " "))
+	options := searchOptions{check: cliCheckFlag, installable: cliInstallableFlag, sort: cliSort}
 
-	sp.Start()
-	githubSearch, err := stew.NewGithubSearch(searchQuery)
-	sp.Stop()
+	githubSearch, err := getSearchPage(searchQuery, cliPage, options, userOS, userArch)
 	stew.CatchAndExit(err)
 
-	if len(githubSearch.Items) == 0 {
+	if len(githubSearch.Items) == 0 && !githubSearch.HasNextPage() {
 		stew.CatchAndExit(stew.NoGithubSearchResultsError{SearchQuery: githubSearch.SearchQuery})
 	}
 
-	formattedSearchResults := stew.FormatSearchResults(githubSearch)
+	if stew.IsStructuredOutput() {
+		for _, searchResult := range githubSearch.Items {
+			stew.EmitResult(stew.Result{Status: "success", SearchResult: &searchResult})
+		}
+		return
+	}
+
+	if stew.IsNonInteractive() {
+		stew.CatchAndExit(stew.NonInteractiveError{Prompt: "stew search", Resolution: "Install the project directly with stew install owner/repo"})
+	}
 
-	githubProjectName, err := stew.PromptSelect("Choose a GitHub project:", formattedSearchResults)
-	stew.CatchAndExit(err)
+	for {
+		formattedSearchResults := stew.FormatSearchResults(githubSearch)
+		choices := append([]string{}, formattedSearchResults...)
+		if githubSearch.Page > 1 {
+			choices = append(choices, searchPreviousPage)
+		}
+		if githubSearch.HasNextPage() {
+			choices = append(choices, searchNextPage)
+		}
+
+		choice, err := stew.PromptSelect(fmt.Sprintf("Choose a GitHub project (page %v):", githubSearch.Page), choices)
+		stew.CatchAndExit(err)
+
+		switch choice {
+		case searchPreviousPage:
+			githubSearch, err = getSearchPage(searchQuery, githubSearch.Page-1, options, userOS, userArch)
+			stew.CatchAndExit(err)
+			continue
+		case searchNextPage:
+			githubSearch, err = getSearchPage(searchQuery, githubSearch.Page+1, options, userOS, userArch)
+			stew.CatchAndExit(err)
+			continue
+		}
+
+		searchResultIndex, _ := stew.Contains(formattedSearchResults, choice)
+		if runSearchAction(githubSearch.Items[searchResultIndex], action) {
+			return
+		}
+	}
+}
+
+// getSearchPage gets a page of search results, then checks, filters, and sorts them
+func getSearchPage(searchQuery string, page int, options searchOptions, userOS, userArch string) (stew.GithubSearch, error) {
+	sp := constants.LoadingSpinner
+
+	sp.Start()
+	githubSearch, err := stew.NewGithubSearchPage(searchQuery, page)
+	sp.Stop()
+	if err != nil {
+		return stew.GithubSearch{}, err
+	}
+
+	// Sorting by the latest release needs the release of each search result
+	if options.check || options.installable || options.sort == "release" {
+		sp.Start()
+		stew.CheckSearchResults(&githubSearch, userOS, userArch)
+		sp.Stop()
+	}
+	if options.installable {
+		stew.FilterInstallableSearchResults(&githubSearch)
+	}
+	if err = stew.SortSearchResults(&githubSearch, options.sort); err != nil {
+		return stew.GithubSearch{}, err
+	}
+	return githubSearch, nil
+}
+
+// runSearchAction runs an action on the selected search result, prompting for the action if it isn't set by a CLI flag.
+// It returns false if you go back to the search results.
+func runSearchAction(searchResult stew.GithubSearchResult, action string) bool {
+	fmt.Fprintln(stew.HumanOutput(), constants.GreenColor(searchResult.FullName))
+	for {
+		selectedAction := action
+		if selectedAction == "" {
+			var err error
+			selectedAction, err = stew.PromptSelect("What do you want to do?", []string{searchActionInstall, searchActionBrowse, searchActionReadme, searchActionBack})
+			stew.CatchAndExit(err)
+		}
 
-	searchResultIndex, _ := stew.Contains(formattedSearchResults, githubProjectName)
+		switch selectedAction {
+		case searchActionInstall:
+			Install(searchResult.FullName, false, "", "", "", "", "")
+			return true
+		case searchActionBrowse:
+			Browse(searchResult.FullName)
+			return true
+		case searchActionReadme:
+			printReadme(searchResult)
+			if action != "" {
+				return true
+			}
+		case searchActionBack:
+			return false
+		}
+	}
+}
 
-	Install(githubSearch.Items[searchResultIndex].FullName)
+func printReadme(searchResult stew.GithubSearchResult) {
+	sp := constants.LoadingSpinner
 
+	fmt.Fprintf(stew.HumanOutput(), "%v [⭐️%v]\n", constants.GreenColor(searchResult.FullName), searchResult.Stars)
+	if searchResult.Description != "" {
+		fmt.Fprintln(stew.HumanOutput(), searchResult.Description)
+	}
+
+	sp.Start()
+	readme, readmeFound, err := stew.GetGithubReadme(searchResult.FullName)
+	sp.Stop()
+	stew.CatchAndExit(err)
+	if !readmeFound {
+		fmt.Fprintln(stew.HumanOutput(), constants.YellowColor("This repo doesn't have a README"))
+		return
+	}
+	fmt.Fprintf(stew.HumanOutput(), "\n%v\n", strings.TrimSpace(readme))
 }
@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/marwanhawari/stew/constants"
+	stew "github.com/marwanhawari/stew/lib"
+)
+
+// SelfUpdate is executed when you run `stew self-update`
+func SelfUpdate(cliCheckFlag bool, cliToTag string) {
+	userOS, userArch, _, _, err := stew.Initialize()
+	stew.CatchAndExit(err)
+
+	executablePath, err := os.Executable()
+	stew.CatchAndExit(err)
+	executablePath, err = filepath.EvalSymlinks(executablePath)
+	stew.CatchAndExit(err)
+
+	if manager, upgradeCommand := stew.DetectPackageManager(executablePath); manager != "" {
+		stew.CatchAndExit(stew.ManagedByPackageManagerError{Path: executablePath, Manager: manager, UpgradeCommand: upgradeCommand})
+	}
+
+	sp := constants.LoadingSpinner
+	sp.Start()
+	githubProject, err := stew.NewStewGithubProject()
+	sp.Stop()
+	stew.CatchAndExit(err)
+
+	_, err = stew.GetGithubReleasesTags(githubProject)
+	stew.CatchAndExit(err)
+
+	tagIndex := -1
+	for index, release := range githubProject.Releases {
+		if cliToTag == "" && !release.Prerelease || cliToTag != "" && release.TagName == cliToTag {
+			tagIndex = index
+			break
+		}
+	}
+	if tagIndex == -1 && cliToTag != "" {
+		stew.CatchAndExit(stew.TagNotFoundError{Owner: githubProject.Owner, Repo: githubProject.Repo, Tag: cliToTag})
+	}
+	if tagIndex == -1 {
+		stew.CatchAndExit(stew.ReleasesNotFoundError{Owner: githubProject.Owner, Repo: githubProject.Repo})
+	}
+	release := githubProject.Releases[tagIndex]
+	tag := release.TagName
+
+	comparison := stew.CompareVersions(tag, constants.StewVersion)
+	if comparison == 0 {
+		fmt.Fprintf(stew.HumanOutput(), "✨ stew %v is already installed\n", constants.GreenColor(tag))
+		return
+	}
+	// Only an explicit --to tag can go back to an older version
+	if comparison < 0 && cliToTag == "" {
+		fmt.Fprintf(stew.HumanOutput(), "✨ stew %v is newer than the latest release %v\n", constants.GreenColor(constants.StewVersion), constants.GreenColor(tag))
+		return
+	}
+	if comparison < 0 && cliCheckFlag {
+		fmt.Fprintf(stew.HumanOutput(), "stew %v is older than the installed version %v. Run %v to downgrade\n", constants.YellowColor(tag), constants.GreenColor(constants.StewVersion), constants.GreenColor("stew self-update --to "+tag))
+		return
+	}
+	if cliCheckFlag {
+		fmt.Fprintf(stew.HumanOutput(), "stew %v is available. The installed version is %v. Run %v to update\n", constants.GreenColor(tag), constants.YellowColor(constants.StewVersion), constants.GreenColor("stew self-update"))
+		return
+	}
+
+	releaseAssets, err := stew.GetGithubReleasesAssets(githubProject, tag)
+	stew.CatchAndExit(err)
+	asset, err := stew.DetectAsset(userOS, userArch, releaseAssets)
+	stew.CatchAndExit(err)
+	checksumAsset, checksumFound := stew.FindChecksumAsset(releaseAssets, asset)
+	if !checksumFound {
+		stew.CatchAndExit(stew.ChecksumNotFoundError{Asset: asset, Tag: tag})
+	}
+
+	downloadDir, err := os.MkdirTemp("", "stew-self-update-")
+	stew.CatchAndExit(err)
+	defer os.RemoveAll(downloadDir)
+
+	assetIndex, _ := stew.Contains(releaseAssets, asset)
+	downloadPath := filepath.Join(downloadDir, asset)
+	err = stew.DownloadFile(downloadPath, release.Assets[assetIndex].DownloadURL)
+	stew.CatchAndExit(err)
+	fmt.Fprintf(stew.HumanOutput(), "✅ Downloaded %v\n", constants.GreenColor(asset))
+
+	checksumIndex, _ := stew.Contains(releaseAssets, checksumAsset)
+	checksumPath := filepath.Join(downloadDir, checksumAsset)
+	err = stew.DownloadFile(checksumPath, release.Assets[checksumIndex].DownloadURL)
+	stew.CatchAndExit(err)
+	checksumContents, err := os.ReadFile(checksumPath)
+	stew.CatchAndExit(err)
+	checksum, checksumFound := stew.ParseChecksum(string(checksumContents), asset)
+	if !checksumFound {
+		stew.CatchAndExit(stew.ChecksumNotFoundError{Asset: asset, Tag: tag})
+	}
+	assetHash, err := stew.CalculateFileHash(downloadPath)
+	stew.CatchAndExit(err)
+	if assetHash != checksum {
+		stew.CatchAndExit(stew.ChecksumMismatchError{Asset: asset, Checksum: checksum, Hash: assetHash})
+	}
+	fmt.Fprintf(stew.HumanOutput(), "🔒 Verified the checksum of %v\n", constants.GreenColor(asset))
+
+	binaryName := "stew"
+	if userOS == "windows" {
+		binaryName = "stew.exe"
+	}
+	stagedPkg, err := stew.StagePackage(stew.PackageData{Binary: binaryName}, downloadPath, filepath.Join(downloadDir, "staging"))
+	stew.CatchAndExit(err)
+
+	err = stew.ReplaceExecutable(stagedPkg.BinaryPath, executablePath)
+	stew.CatchAndExit(err)
+
+	fmt.Fprintf(stew.HumanOutput(), "✨ Successfully updated stew from %v to %v\n", constants.GreenColor(constants.StewVersion), constants.GreenColor(tag))
+}
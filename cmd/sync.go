@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/marwanhawari/stew/constants"
+	stew "github.com/marwanhawari/stew/lib"
+)
+
+// Sync is executed when you run `stew sync`
+func Sync(cliInput string, cliPruneFlag bool, cliDryRunFlag bool) {
+	userOS, userArch, _, systemInfo, err := stew.Initialize()
+	stew.CatchAndExit(err)
+
+	var unlock func()
+	systemInfo, unlock, err = stew.LockStewPath(systemInfo)
+	stew.CatchAndExit(err)
+	defer unlock()
+
+	stewfilePath := cliInput
+	if stewfilePath == "" {
+		stewfilePath = "Stewfile"
+	}
+
+	stewBinPath := systemInfo.StewBinPath
+	stewLockFilePath := systemInfo.StewLockFilePath
+
+	stewfilePkgs, err := stew.ReadStewfileContents(stewfilePath)
+	stew.CatchAndExit(err)
+
+	lockFile, err := stew.NewLockFile(stewLockFilePath, userOS, userArch)
+	stew.CatchAndExit(err)
+
+	plan := stew.NewSyncPlan(stewfilePkgs, lockFile)
+	printSyncPlan(stewfilePath, plan, cliPruneFlag)
+
+	if cliDryRunFlag || plan.IsEmpty() {
+		return
+	}
+
+	var failed bool
+	for _, pkg := range plan.Install {
+		if err := installOne(pkg, userOS, userArch, systemInfo, false); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			stew.EmitResult(stew.NewErrorResult(pkg.Binary, &pkg, err))
+			failed = true
+		}
+	}
+
+	for _, change := range plan.Change {
+		if err := installOne(change.Desired, userOS, userArch, systemInfo, true); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			stew.EmitResult(stew.NewErrorResult(change.Installed.Binary, &change.Desired, err))
+			failed = true
+		}
+	}
+
+	if cliPruneFlag && len(plan.Prune) != 0 {
+		lockFile, err = stew.NewLockFile(stewLockFilePath, userOS, userArch)
+		stew.CatchAndExit(err)
+		for _, pkg := range plan.Prune {
+			indexInLockFile, binaryFoundInLockFile := stew.FindBinaryInLockFile(lockFile, pkg.Binary)
+			if !binaryFoundInLockFile {
+				continue
+			}
+			if err := stew.RunHook(stew.PreUninstallHook, pkg, filepath.Join(stewBinPath, pkg.Binary), ""); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				stew.EmitResult(stew.NewErrorResult(pkg.Binary, &pkg, err))
+				failed = true
+				continue
+			}
+			err = stew.DeletePackage(systemInfo, lockFile.Packages[indexInLockFile])
+			stew.CatchAndExit(err)
+			lockFile.Packages, err = stew.RemovePackage(lockFile.Packages, indexInLockFile)
+			stew.CatchAndExit(err)
+			fmt.Fprintf(stew.HumanOutput(), "🗑️  Uninstalled the %v binary from %v\n", constants.GreenColor(pkg.Binary), constants.GreenColor(stewBinPath))
+			stew.EmitResult(stew.NewSuccessResult(pkg))
+		}
+		err = stew.WriteLockFileJSON(lockFile, stewLockFilePath)
+		stew.CatchAndExit(err)
+	}
+
+	if failed {
+		stew.FlushResults()
+		os.Exit(1)
+	}
+	fmt.Fprintf(stew.HumanOutput(), "✨ Successfully synced %v\n", constants.GreenColor(stewfilePath))
+}
+
+func printSyncPlan(stewfilePath string, plan stew.SyncPlan, cliPruneFlag bool) {
+	if plan.IsEmpty() {
+		fmt.Fprintf(stew.HumanOutput(), "✨ The installed binaries are already in sync with %v\n", constants.GreenColor(stewfilePath))
+		return
+	}
+
+	fmt.Fprintf(stew.HumanOutput(), "📋 Sync plan for %v\n", constants.GreenColor(stewfilePath))
+	for _, pkg := range plan.Install {
+		fmt.Fprintf(stew.HumanOutput(), "  %v %v\n", constants.GreenColor("+"), syncPackageName(pkg))
+	}
+	for _, change := range plan.Change {
+		fmt.Fprintf(stew.HumanOutput(), "  %v %v %v -> %v\n", constants.YellowColor("~"), change.Installed.Binary, syncPackageVersion(change.Installed), syncPackageVersion(change.Desired))
+	}
+	for _, pkg := range plan.Prune {
+		if cliPruneFlag {
+			fmt.Fprintf(stew.HumanOutput(), "  %v %v\n", constants.RedColor("-"), pkg.Binary)
+		} else {
+			fmt.Fprintf(stew.HumanOutput(), "  %v %v (not in the Stewfile, use --prune to uninstall)\n", constants.BoldColor("?"), pkg.Binary)
+		}
+	}
+}
+
+func syncPackageName(pkg stew.PackageData) string {
+	name := syncPackageVersion(pkg)
+	if pkg.Binary != "" {
+		name = pkg.Binary + ":" + name
+	}
+	return name
+}
+
+func syncPackageVersion(pkg stew.PackageData) string {
+	if pkg.Source != "github" && pkg.URLTemplate != "" {
+		if pkg.Tag != "" {
+			return pkg.URLTemplate + "@" + pkg.Tag
+		}
+		return pkg.URLTemplate
+	}
+	if pkg.Source != "github" {
+		return pkg.URL
+	}
+	version := pkg.Owner + "/" + pkg.Repo
+	if pkg.Tag != "" {
+		version += "@" + pkg.Tag
+	}
+	if pkg.Asset != "" {
+		version += " (" + pkg.Asset + ")"
+	}
+	return version
+}
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"fmt"
+	"path/filepath"
 
 	"github.com/marwanhawari/stew/constants"
 	stew "github.com/marwanhawari/stew/lib"
@@ -13,6 +14,11 @@ func Uninstall(cliFlag bool, binaryName string) {
 	userOS, userArch, _, systemInfo, err := stew.Initialize()
 	stew.CatchAndExit(err)
 
+	var unlock func()
+	systemInfo, unlock, err = stew.LockStewPath(systemInfo)
+	stew.CatchAndExit(err)
+	defer unlock()
+
 	if cliFlag && binaryName != "" {
 		stew.CatchAndExit(stew.CLIFlagAndInputError{})
 	} else if !cliFlag {
@@ -21,7 +27,6 @@ func Uninstall(cliFlag bool, binaryName string) {
 	}
 
 	stewBinPath := systemInfo.StewBinPath
-	stewPkgPath := systemInfo.StewPkgPath
 	stewLockFilePath := systemInfo.StewLockFilePath
 
 	lockFile, err := stew.NewLockFile(stewLockFilePath, userOS, userArch)
@@ -32,17 +37,26 @@ func Uninstall(cliFlag bool, binaryName string) {
 	}
 
 	if cliFlag {
+		// Every hook runs before any binary is deleted, so that a failed hook leaves all of them installed
+		for _, pkg := range lockFile.Packages {
+			err = stew.RunHook(stew.PreUninstallHook, pkg, filepath.Join(stewBinPath, pkg.Binary), "")
+			stew.CatchAndExit(err)
+		}
 		for _, pkg := range lockFile.Packages {
-			err = stew.DeleteAssetAndBinary(stewPkgPath, stewBinPath, pkg.Asset, pkg.Binary)
+			err = stew.DeletePackage(systemInfo, pkg)
 			stew.CatchAndExit(err)
+			stew.EmitResult(stew.NewSuccessResult(pkg))
 		}
 		lockFile.Packages = []stew.PackageData{}
 	} else {
 		var binaryFound bool
 		for index, pkg := range lockFile.Packages {
 			if pkg.Binary == binaryName {
-				err = stew.DeleteAssetAndBinary(stewPkgPath, stewBinPath, pkg.Asset, pkg.Binary)
+				err = stew.RunHook(stew.PreUninstallHook, pkg, filepath.Join(stewBinPath, pkg.Binary), "")
+				stew.CatchAndExit(err)
+				err = stew.DeletePackage(systemInfo, pkg)
 				stew.CatchAndExit(err)
+				stew.EmitResult(stew.NewSuccessResult(pkg))
 				lockFile.Packages, err = stew.RemovePackage(lockFile.Packages, index)
 				stew.CatchAndExit(err)
 				binaryFound = true
@@ -57,8 +71,8 @@ func Uninstall(cliFlag bool, binaryName string) {
 	err = stew.WriteLockFileJSON(lockFile, stewLockFilePath)
 	stew.CatchAndExit(err)
 	if cliFlag {
-		fmt.Printf("✨ Successfully uninstalled all binaries from %v\n", constants.GreenColor(stewBinPath))
+		fmt.Fprintf(stew.HumanOutput(), "✨ Successfully uninstalled all binaries from %v\n", constants.GreenColor(stewBinPath))
 	} else {
-		fmt.Printf("✨ Successfully uninstalled the %v binary from %v\n", constants.GreenColor(binaryName), constants.GreenColor(stewBinPath))
+		fmt.Fprintf(stew.HumanOutput(), "✨ Successfully uninstalled the %v binary from %v\n", constants.GreenColor(binaryName), constants.GreenColor(stewBinPath))
 	}
 }
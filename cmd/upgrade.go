@@ -3,18 +3,22 @@ package cmd
 import (
 	"fmt"
 	"os"
-	"path/filepath"
 
 	"github.com/marwanhawari/stew/constants"
 	stew "github.com/marwanhawari/stew/lib"
 )
 
 // Upgrade is executed when you run `stew upgrade`
-func Upgrade(upgradeAllCliFlag bool, binaryName string) {
+func Upgrade(upgradeAllCliFlag bool, binaryName string, cliChangelogFlag bool) {
 
 	userOS, userArch, stewConfig, systemInfo, err := stew.Initialize()
 	stew.CatchAndExit(err)
 
+	var unlock func()
+	systemInfo, unlock, err = stew.LockStewPath(systemInfo)
+	stew.CatchAndExit(err)
+	defer unlock()
+
 	if upgradeAllCliFlag && binaryName != "" {
 		stew.CatchAndExit(stew.CLIFlagAndInputError{})
 	} else if !upgradeAllCliFlag {
@@ -22,33 +26,25 @@ func Upgrade(upgradeAllCliFlag bool, binaryName string) {
 		stew.CatchAndExit(err)
 	}
 
-	stewTmpPath := systemInfo.StewTmpPath
 	stewLockFilePath := systemInfo.StewLockFilePath
 
 	lockFile, err := stew.NewLockFile(stewLockFilePath, userOS, userArch)
 	stew.CatchAndExit(err)
 
-	err = os.RemoveAll(stewTmpPath)
-	stew.CatchAndExit(err)
-	err = os.MkdirAll(stewTmpPath, 0755)
-	stew.CatchAndExit(err)
-
 	if len(lockFile.Packages) == 0 {
 		stew.CatchAndExit(stew.NoBinariesInstalledError{})
 	}
 
 	if upgradeAllCliFlag {
-		upgradeAll(userOS, userArch, lockFile, systemInfo, stewConfig)
+		upgradeAll(userOS, userArch, lockFile, systemInfo, stewConfig, cliChangelogFlag)
 	} else {
-		err := upgradeOne(binaryName, userOS, userArch, lockFile, systemInfo)
+		err := upgradeOne(binaryName, userOS, userArch, lockFile, systemInfo, cliChangelogFlag)
 		stew.CatchAndExit(err)
 	}
 }
 
-func upgradeOne(binaryName, userOS, userArch string, lockFile stew.LockFile, systemInfo stew.SystemInfo) error {
+func upgradeOne(binaryName, userOS, userArch string, lockFile stew.LockFile, systemInfo stew.SystemInfo, cliChangelogFlag bool) error {
 	sp := constants.LoadingSpinner
-	stewPkgPath := systemInfo.StewPkgPath
-	stewLockFilePath := systemInfo.StewLockFilePath
 
 	indexInLockFile, binaryFoundInLockFile := stew.FindBinaryInLockFile(lockFile, binaryName)
 	if !binaryFoundInLockFile {
@@ -56,7 +52,10 @@ func upgradeOne(binaryName, userOS, userArch string, lockFile stew.LockFile, sys
 	}
 
 	pkg := lockFile.Packages[indexInLockFile]
-	fmt.Println(constants.GreenColor(pkg.Binary))
+	fmt.Fprintln(stew.HumanOutput(), constants.GreenColor(pkg.Binary))
+	if pkg.Source == "other" && pkg.URLTemplate != "" {
+		return upgradeURLTemplate(pkg, userOS, userArch, systemInfo)
+	}
 	if pkg.Source == "other" {
 		return stew.InstalledFromURLError{Binary: pkg.Binary}
 	}
@@ -89,6 +88,12 @@ func upgradeOne(binaryName, userOS, userArch string, lockFile stew.LockFile, sys
 		return stew.AlreadyInstalledLatestTagError{Tag: tag}
 	}
 
+	if cliChangelogFlag {
+		if err := reviewChangelog(pkg, tag, githubProject, systemInfo); err != nil {
+			return err
+		}
+	}
+
 	// Make sure there are any assets at all
 	releaseAssets, err := stew.GetGithubReleasesAssets(githubProject, tag)
 	if err != nil {
@@ -100,43 +105,109 @@ func upgradeOne(binaryName, userOS, userArch string, lockFile stew.LockFile, sys
 		return err
 	}
 	assetIndex, _ := stew.Contains(releaseAssets, asset)
-	downloadURL := githubProject.Releases[tagIndex].Assets[assetIndex].DownloadURL
-	downloadPath := filepath.Join(stewPkgPath, asset)
-	err = stew.DownloadFile(downloadPath, downloadURL)
-	if err != nil {
-		return err
-	}
-	fmt.Printf("✅ Downloaded %v to %v\n", constants.GreenColor(asset), constants.GreenColor(stewPkgPath))
 
-	_, binaryHash, err := stew.InstallBinary(downloadPath, repo, systemInfo, &lockFile, true, pkg.Binary, "")
+	latestPkg := pkg
+	latestPkg.Tag = tag
+	latestPkg.Asset = asset
+	latestPkg.URL = githubProject.Releases[tagIndex].Assets[assetIndex].DownloadURL
+	latestPkg.BinaryHash = ""
+	latestPkg.AssetHash = ""
+	// The locked platforms belong to the previous tag
+	latestPkg.Platforms = nil
+	stagedPkg, err := downloadAndStage(latestPkg, systemInfo)
 	if err != nil {
-		if err := os.RemoveAll(downloadPath); err != nil {
-			return err
-		}
 		return err
 	}
-
-	lockFile.Packages[indexInLockFile].Tag = tag
-	lockFile.Packages[indexInLockFile].Asset = asset
-	lockFile.Packages[indexInLockFile].URL = downloadURL
-	lockFile.Packages[indexInLockFile].BinaryHash = binaryHash
-	if err := stew.WriteLockFileJSON(lockFile, stewLockFilePath); err != nil {
+	latestPkg, err = installStaged(stagedPkg, systemInfo, userOS, userArch)
+	if err != nil {
 		return err
 	}
 
-	fmt.Printf("✨ Successfully upgraded the %v binary from %v to %v\n", constants.GreenColor(pkg.Binary), constants.GreenColor(pkg.Tag), constants.GreenColor(tag))
+	fmt.Fprintf(stew.HumanOutput(), "✨ Successfully upgraded the %v binary from %v to %v\n", constants.GreenColor(pkg.Binary), constants.GreenColor(pkg.Tag), constants.GreenColor(tag))
+	hookErr := runPostHook(stew.PostUpgradeHook, latestPkg, systemInfo, pkg.Tag)
+	upgradeResult := stew.NewSuccessResult(latestPkg).WithHookError(hookErr)
+	upgradeResult.PreviousTag = pkg.Tag
+	stew.EmitResult(upgradeResult)
 	return nil
 }
 
-func upgradeAll(userOS, userArch string, lockFile stew.LockFile, systemInfo stew.SystemInfo, stewConfig stew.StewConfig) {
+func upgradeAll(userOS, userArch string, lockFile stew.LockFile, systemInfo stew.SystemInfo, stewConfig stew.StewConfig, cliChangelogFlag bool) {
 	for _, pkg := range lockFile.Packages {
 		if _, packageIsExcluded := stew.Contains(stewConfig.ExcludedFromUpgradeAll, pkg.Binary); packageIsExcluded {
-			fmt.Printf("%v (Excluded)\n", constants.YellowColor(pkg.Binary))
+			fmt.Fprintf(stew.HumanOutput(), "%v (Excluded)\n", constants.YellowColor(pkg.Binary))
+			stew.EmitResult(stew.Result{Status: "skipped", Binary: pkg.Binary, Package: &pkg})
+			continue
+		}
+		if pkg.Source == "other" && pkg.URLTemplate == "" {
+			fmt.Fprintf(stew.HumanOutput(), "%v (Installed from a URL)\n", constants.YellowColor(pkg.Binary))
+			stew.EmitResult(stew.Result{Status: "skipped", Binary: pkg.Binary, Package: &pkg})
 			continue
 		}
-		if err := upgradeOne(pkg.Binary, userOS, userArch, lockFile, systemInfo); err != nil {
+		if err := upgradeOne(pkg.Binary, userOS, userArch, lockFile, systemInfo, cliChangelogFlag); err != nil {
 			fmt.Fprintln(os.Stderr, err)
+			stew.EmitResult(stew.NewErrorResult(pkg.Binary, &pkg, err))
 			continue
 		}
 	}
 }
+
+// reviewChangelog writes the release notes between the installed tag and the new tag to the changelogs directory.
+// In the interactive mode they are also shown in a pager before you confirm the upgrade.
+func reviewChangelog(pkg stew.PackageData, tag string, githubProject stew.GithubProject, systemInfo stew.SystemInfo) error {
+	releases := stew.ReleasesBetween(githubProject.Releases, pkg.Tag, tag)
+	changelog := stew.FormatChangelog(pkg.Binary, pkg.Tag, tag, releases)
+	changelogPath, err := stew.WriteChangelog(systemInfo.StewPath, pkg.Binary, changelog)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(stew.HumanOutput(), "📝 Wrote the changelog to %v\n", constants.GreenColor(changelogPath))
+
+	if stew.IsNonInteractive() || stew.IsStructuredOutput() {
+		return nil
+	}
+	if err = stew.ShowInPager(changelog); err != nil {
+		return err
+	}
+	confirmed, err := stew.WarningPromptConfirm(fmt.Sprintf("Upgrade %v from %v to %v?", constants.YellowColor(pkg.Binary), constants.YellowColor(pkg.Tag), constants.YellowColor(tag)))
+	if err != nil {
+		return err
+	}
+	if !confirmed {
+		return stew.UpgradeCanceledError{Binary: pkg.Binary}
+	}
+	return nil
+}
+
+// upgradeURLTemplate upgrades a package installed from a URL template to the latest version from its version source
+func upgradeURLTemplate(pkg stew.PackageData, userOS, userArch string, systemInfo stew.SystemInfo) error {
+	latestPkg := pkg
+	latestPkg.Tag = ""
+	latestPkg, err := resolveURLTemplate(latestPkg, userOS, userArch)
+	if err != nil {
+		return err
+	}
+	// The version source can lag behind the installed version, which must not be downgraded
+	if stew.CompareVersions(latestPkg.Tag, pkg.Tag) <= 0 {
+		return stew.AlreadyInstalledLatestTagError{Tag: pkg.Tag}
+	}
+
+	latestPkg.BinaryHash = ""
+	latestPkg.AssetHash = ""
+	// The locked platforms belong to the previous version
+	latestPkg.Platforms = nil
+	stagedPkg, err := downloadAndStage(latestPkg, systemInfo)
+	if err != nil {
+		return err
+	}
+	latestPkg, err = installStaged(stagedPkg, systemInfo, userOS, userArch)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(stew.HumanOutput(), "✨ Successfully upgraded the %v binary from %v to %v\n", constants.GreenColor(pkg.Binary), constants.GreenColor(pkg.Tag), constants.GreenColor(latestPkg.Tag))
+	hookErr := runPostHook(stew.PostUpgradeHook, latestPkg, systemInfo, pkg.Tag)
+	upgradeResult := stew.NewSuccessResult(latestPkg).WithHookError(hookErr)
+	upgradeResult.PreviousTag = pkg.Tag
+	stew.EmitResult(upgradeResult)
+	return nil
+}
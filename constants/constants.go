@@ -49,6 +49,9 @@ var RegexURL = `^(http|ftp|https):\/\/([\w_-]+(?:(?:\.[\w_-]+)+))([\w.,@?^=%&:\/
 // RegexChecksum is a regular expression for matching checksum files
 var RegexChecksum = `\.(sha(256|512)(sum)?)$`
 
+// StewVersion is the version of stew
+var StewVersion = "v0.6.0"
+
 // StewOwner is the username of the stew github repo owner
 var StewOwner = `marwanhawari`
 
@@ -0,0 +1,436 @@
+package stew
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bodgit/sevenzip"
+	"github.com/klauspost/compress/zstd"
+	"github.com/mholt/archiver"
+	"github.com/ulikunitz/xz"
+	"github.com/ulikunitz/xz/lzma"
+)
+
+// The formats of the assets that are detected from their magic bytes, so that misnamed assets can still be extracted
+const (
+	unknownFormat    = ""
+	tarFormat        = "tar"
+	compressedFormat = "compressed"
+	zipFormat        = "zip"
+	rarFormat        = "rar"
+	sevenZipFormat   = "7z"
+	debFormat        = "deb"
+	rpmFormat        = "rpm"
+)
+
+// The extensions of single file compressed binaries, which are removed from the name of the binary
+var compressionExtensions = []string{".gz", ".xz", ".bz2", ".zst", ".zstd"}
+
+// detectAssetFormat detects the format of a downloaded asset from its magic bytes. Assets in an unknown format are returned as unknownFormat.
+func detectAssetFormat(filePath string) (string, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	magic := make([]byte, 512)
+	readSize, err := io.ReadFull(file, magic)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", err
+	}
+	magic = magic[:readSize]
+
+	switch {
+	case bytes.HasPrefix(magic, []byte("PK\x03\x04")), bytes.HasPrefix(magic, []byte("PK\x05\x06")):
+		return zipFormat, nil
+	case bytes.HasPrefix(magic, []byte("Rar!\x1a\x07")):
+		return rarFormat, nil
+	case bytes.HasPrefix(magic, []byte{'7', 'z', 0xbc, 0xaf, 0x27, 0x1c}):
+		return sevenZipFormat, nil
+	case bytes.HasPrefix(magic, []byte("!<arch>\ndebian-binary")):
+		return debFormat, nil
+	case bytes.HasPrefix(magic, []byte{0xed, 0xab, 0xee, 0xdb}):
+		return rpmFormat, nil
+	case compressionOf(magic) != "":
+		return compressedFormat, nil
+	case isTarHeader(magic):
+		return tarFormat, nil
+	}
+	return unknownFormat, nil
+}
+
+// compressionOf returns the compression that a stream starts with, or an empty string if it isn't compressed
+func compressionOf(magic []byte) string {
+	switch {
+	case bytes.HasPrefix(magic, []byte{0x1f, 0x8b}):
+		return "gzip"
+	case bytes.HasPrefix(magic, []byte{0xfd, '7', 'z', 'X', 'Z', 0x00}):
+		return "xz"
+	case bytes.HasPrefix(magic, []byte("BZh")) && len(magic) > 3 && magic[3] >= '1' && magic[3] <= '9':
+		return "bzip2"
+	case bytes.HasPrefix(magic, []byte{0x28, 0xb5, 0x2f, 0xfd}):
+		return "zstd"
+	}
+	return ""
+}
+
+// isTarHeader checks if a stream starts with a tar header
+func isTarHeader(magic []byte) bool {
+	return len(magic) >= 262 && string(magic[257:262]) == "ustar"
+}
+
+// extractAsset extracts a downloaded asset into the destPath based on its magic bytes.
+// It returns false if the asset is not an archive, like a binary or a single file compressed binary, which is left to the caller.
+func extractAsset(filePath, destPath string) (bool, error) {
+	format, err := detectAssetFormat(filePath)
+	if err != nil {
+		return false, err
+	}
+	switch format {
+	case debFormat, rpmFormat:
+		return true, extractSystemPackage(filePath, destPath, format)
+	case zipFormat:
+		return true, archiver.DefaultZip.Unarchive(filePath, destPath)
+	case rarFormat:
+		return true, archiver.DefaultRar.Unarchive(filePath, destPath)
+	case sevenZipFormat:
+		return true, extractSevenZip(filePath, destPath)
+	case tarFormat, compressedFormat:
+		isTar, err := containsTar(filePath)
+		if err != nil || !isTar {
+			return false, err
+		}
+		return true, extractTar(filePath, destPath)
+	}
+	// Other archives that archiver supports, like .tar.lz4 and .tar.sz, are only detected from their extension
+	if isArchiveFile(filePath) {
+		return true, archiver.Unarchive(filePath, destPath)
+	}
+	return false, nil
+}
+
+// containsTar checks if an asset is a tar archive once it is decompressed
+func containsTar(filePath string) (bool, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+	stream, err := decompressStream(file)
+	if err != nil {
+		return false, err
+	}
+	defer stream.Close()
+
+	header := make([]byte, 512)
+	readSize, err := io.ReadFull(stream, header)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return false, err
+	}
+	return isTarHeader(header[:readSize]), nil
+}
+
+// extractTar extracts a tar archive that is either uncompressed or compressed with gzip, xz, bzip2, or zstd
+func extractTar(filePath, destPath string) error {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	stream, err := decompressStream(file)
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+	return extractTarStream(stream, destPath)
+}
+
+// decompressFile writes the decompressed contents of a single file compressed binary to the binaryPath
+func decompressFile(filePath, binaryPath string) error {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	stream, err := decompressStream(file)
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+	return writeFileAtomic(binaryPath, stream, 0755)
+}
+
+// decompressedName removes the compression extension from the name of a single file compressed binary
+func decompressedName(filePath string) string {
+	fileName := filepath.Base(filePath)
+	for _, extension := range compressionExtensions {
+		if strings.HasSuffix(strings.ToLower(fileName), extension) && len(fileName) > len(extension) {
+			return fileName[:len(fileName)-len(extension)]
+		}
+	}
+	return fileName
+}
+
+// decompressStream detects the compression of a stream from its magic bytes and decompresses it. Uncompressed streams are returned as they are.
+func decompressStream(stream io.Reader) (io.ReadCloser, error) {
+	reader := bufio.NewReader(stream)
+	magic, _ := reader.Peek(6)
+	switch compressionOf(magic) {
+	case "gzip":
+		gzipReader, err := gzip.NewReader(reader)
+		if err != nil {
+			return nil, err
+		}
+		return gzipReader, nil
+	case "xz":
+		xzReader, err := xz.NewReader(reader)
+		if err != nil {
+			return nil, err
+		}
+		return io.NopCloser(xzReader), nil
+	case "bzip2":
+		return io.NopCloser(bzip2.NewReader(reader)), nil
+	case "zstd":
+		zstdReader, err := zstd.NewReader(reader)
+		if err != nil {
+			return nil, err
+		}
+		return zstdReader.IOReadCloser(), nil
+	}
+	// rpm packages can also use the legacy lzma format, which doesn't have a reliable magic
+	if bytes.HasPrefix(magic, []byte{0x5d, 0x00, 0x00}) {
+		lzmaReader, err := lzma.NewReader(reader)
+		if err != nil {
+			return nil, err
+		}
+		return io.NopCloser(lzmaReader), nil
+	}
+	return io.NopCloser(reader), nil
+}
+
+// extractSevenZip extracts the directories, files, and symlinks of a 7z archive into the destPath
+func extractSevenZip(filePath, destPath string) error {
+	archive, err := sevenzip.OpenReader(filePath)
+	if err != nil {
+		return err
+	}
+	defer archive.Close()
+	for _, file := range archive.File {
+		if err = extractSevenZipEntry(file, destPath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sevenZipUnixAttributes are the high bits of the 7z attributes that contain the unix mode of a file
+const sevenZipUnixAttributes = 0xf0000000
+
+func extractSevenZipEntry(file *sevenzip.File, destPath string) error {
+	mode := file.Mode()
+	if mode.IsDir() {
+		return extractDirEntry(destPath, file.Name)
+	}
+	contents, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer contents.Close()
+	if mode&os.ModeSymlink != 0 {
+		linkTarget, err := io.ReadAll(contents)
+		if err != nil {
+			return err
+		}
+		return extractSymlinkEntry(destPath, file.Name, string(linkTarget))
+	}
+	perm := mode.Perm()
+	// Archives made on Windows only have MS-DOS attributes, which can't mark a file as executable.
+	// The binary could not be found without the executable bit, so every file is made executable instead.
+	if file.Attributes&sevenZipUnixAttributes == 0 {
+		perm |= 0111
+	}
+	return extractFileEntry(destPath, file.Name, contents, perm)
+}
+
+// extractTarStream extracts the directories, files, and symlinks of a tar stream into the destPath
+func extractTarStream(stream io.Reader, destPath string) error {
+	tarReader := tar.NewReader(stream)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		switch header.Typeflag {
+		case tar.TypeDir:
+			err = extractDirEntry(destPath, header.Name)
+		case tar.TypeReg:
+			err = extractFileEntry(destPath, header.Name, tarReader, header.FileInfo().Mode().Perm())
+		case tar.TypeSymlink:
+			err = extractSymlinkEntry(destPath, header.Name, header.Linkname)
+		case tar.TypeLink:
+			err = extractHardlinkEntry(destPath, header.Name, header.Linkname)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// entryPath returns the path of an archive entry inside of the destPath. Absolute paths and .. elements can't leave the destPath.
+func entryPath(destPath, name string) (string, error) {
+	path := filepath.Join(destPath, filepath.Clean(string(filepath.Separator)+filepath.FromSlash(name)))
+	if path == filepath.Clean(destPath) {
+		return "", UnsafeArchiveEntryError{Entry: name}
+	}
+	if err := os.MkdirAll(destPath, 0755); err != nil {
+		return "", err
+	}
+	relativeParentPath, err := filepath.Rel(destPath, filepath.Dir(path))
+	if err != nil || relativeParentPath == "." {
+		return path, err
+	}
+	// Each parent is checked before the next one is created, so that a symlink that was extracted earlier can't redirect the entry out of the destPath
+	parentPath := filepath.Clean(destPath)
+	for _, element := range strings.Split(relativeParentPath, string(filepath.Separator)) {
+		parentPath = filepath.Join(parentPath, element)
+		_, err := os.Lstat(parentPath)
+		if os.IsNotExist(err) {
+			if err = os.Mkdir(parentPath, 0755); err != nil {
+				return "", err
+			}
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		parentInside, err := resolvesInside(destPath, parentPath)
+		if err != nil {
+			return "", err
+		}
+		if !parentInside {
+			return "", UnsafeArchiveEntryError{Entry: name}
+		}
+	}
+	return path, nil
+}
+
+// resolvesInside checks if a path is still inside of the rootPath once their symlinks are resolved
+func resolvesInside(rootPath, path string) (bool, error) {
+	resolvedRootPath, err := filepath.EvalSymlinks(rootPath)
+	if err != nil {
+		return false, err
+	}
+	resolvedPath, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return false, err
+	}
+	return resolvedPath == resolvedRootPath || strings.HasPrefix(resolvedPath, resolvedRootPath+string(filepath.Separator)), nil
+}
+
+func extractDirEntry(destPath, name string) error {
+	// Packages usually start with an entry for their root, which is the destPath
+	if filepath.Clean(string(filepath.Separator)+filepath.FromSlash(name)) == string(filepath.Separator) {
+		return nil
+	}
+	path, err := entryPath(destPath, name)
+	if err != nil {
+		return err
+	}
+	return os.MkdirAll(path, 0755)
+}
+
+func extractFileEntry(destPath, name string, contents io.Reader, perm os.FileMode) error {
+	path, err := entryPath(destPath, name)
+	if err != nil {
+		return err
+	}
+	if err = os.RemoveAll(path); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, perm|0200)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(file, contents); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+func extractSymlinkEntry(destPath, name, linkTarget string) error {
+	path, err := entryPath(destPath, name)
+	if err != nil {
+		return err
+	}
+	if err = os.RemoveAll(path); err != nil {
+		return err
+	}
+	// An absolute target points to where the package would be installed on the system, which is inside of the destPath here
+	if strings.HasPrefix(linkTarget, "/") {
+		if linkTarget, err = filepath.Rel(filepath.Dir(path), filepath.Join(destPath, filepath.Clean(filepath.FromSlash(linkTarget)))); err != nil {
+			return err
+		}
+	}
+	// A relative target is followed from the real directory of the link, so it is checked from there
+	resolvedDestPath, err := filepath.EvalSymlinks(destPath)
+	if err != nil {
+		return err
+	}
+	resolvedDirPath, err := filepath.EvalSymlinks(filepath.Dir(path))
+	if err != nil {
+		return err
+	}
+	targetPath := filepath.Join(resolvedDirPath, filepath.FromSlash(linkTarget))
+	if targetPath != resolvedDestPath && !strings.HasPrefix(targetPath, resolvedDestPath+string(filepath.Separator)) {
+		return UnsafeArchiveEntryError{Entry: name}
+	}
+	if err = os.Symlink(linkTarget, path); err != nil {
+		return err
+	}
+	// The target can still go through other links, so a target that already exists has to resolve inside of the destPath too
+	if _, err = os.Stat(path); err != nil {
+		return nil
+	}
+	linkInside, err := resolvesInside(destPath, path)
+	if err != nil {
+		return err
+	}
+	if !linkInside {
+		os.Remove(path)
+		return UnsafeArchiveEntryError{Entry: name}
+	}
+	return nil
+}
+
+func extractHardlinkEntry(destPath, name, linkName string) error {
+	linkedPath, err := entryPath(destPath, linkName)
+	if err != nil {
+		return err
+	}
+	// Only the contents of a regular file are copied, so that a hard link can't read a file outside of the destPath through a symlink
+	if linkedInfo, err := os.Lstat(linkedPath); err != nil || !linkedInfo.Mode().IsRegular() {
+		return UnsafeArchiveEntryError{Entry: name}
+	}
+	linkedFile, err := os.Open(linkedPath)
+	if err != nil {
+		return err
+	}
+	defer linkedFile.Close()
+	linkedInfo, err := linkedFile.Stat()
+	if err != nil {
+		return err
+	}
+	return extractFileEntry(destPath, name, linkedFile, linkedInfo.Mode().Perm())
+}
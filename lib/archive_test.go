@@ -0,0 +1,312 @@
+package stew
+
+import (
+	"archive/tar"
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"testing"
+	"unicode/utf16"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+func newTestTar(t *testing.T, files map[string]string) []byte {
+	var data bytes.Buffer
+	tarWriter := tar.NewWriter(&data)
+	for name, contents := range files {
+		tarWriter.WriteHeader(&tar.Header{Name: name, Mode: 0755, Typeflag: tar.TypeReg, Size: int64(len(contents))})
+		tarWriter.Write([]byte(contents))
+	}
+	tarWriter.Close()
+	return data.Bytes()
+}
+
+func compressTestZstd(t *testing.T, payload []byte) []byte {
+	encoder, err := zstd.NewWriter(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer encoder.Close()
+	return encoder.EncodeAll(payload, nil)
+}
+
+// unixTest7zAttributes have the unix mode 0755 in their high bits, like the attributes of a 7z archive made on linux
+const unixTest7zAttributes = uint32(0100755<<16 | 0x8000)
+
+// msdosTest7zAttributes only have the MS-DOS archive bit, like the attributes of a 7z archive made on Windows
+const msdosTest7zAttributes = uint32(0x20)
+
+// newTest7z builds a 7z archive that stores each file uncompressed in its own folder
+func newTest7z(t *testing.T, names []string, contents []string, attributes uint32) []byte {
+	var header bytes.Buffer
+	header.Write([]byte{0x01, 0x04, 0x06, 0x00, byte(len(names)), 0x09})
+	for _, fileContents := range contents {
+		header.WriteByte(byte(len(fileContents)))
+	}
+	header.Write([]byte{0x00, 0x07, 0x0b, byte(len(names)), 0x00})
+	for range names {
+		// One coder with the copy method
+		header.Write([]byte{0x01, 0x01, 0x00})
+	}
+	header.WriteByte(0x0c)
+	for _, fileContents := range contents {
+		header.WriteByte(byte(len(fileContents)))
+	}
+	header.Write([]byte{0x00, 0x00, 0x05, byte(len(names))})
+
+	var namesProperty bytes.Buffer
+	namesProperty.WriteByte(0x00)
+	for _, name := range names {
+		for _, char := range utf16.Encode([]rune(name + "\x00")) {
+			binary.Write(&namesProperty, binary.LittleEndian, char)
+		}
+	}
+	header.Write([]byte{0x11, byte(namesProperty.Len())})
+	header.Write(namesProperty.Bytes())
+
+	header.Write([]byte{0x15, byte(2 + 4*len(names)), 0x01, 0x00})
+	for range names {
+		binary.Write(&header, binary.LittleEndian, attributes)
+	}
+	header.Write([]byte{0x00, 0x00})
+
+	var packedStreams bytes.Buffer
+	for _, fileContents := range contents {
+		packedStreams.WriteString(fileContents)
+	}
+
+	startHeader := make([]byte, 20)
+	binary.LittleEndian.PutUint64(startHeader[0:8], uint64(packedStreams.Len()))
+	binary.LittleEndian.PutUint64(startHeader[8:16], uint64(header.Len()))
+	binary.LittleEndian.PutUint32(startHeader[16:20], crc32.ChecksumIEEE(header.Bytes()))
+
+	var archive bytes.Buffer
+	archive.Write([]byte{'7', 'z', 0xbc, 0xaf, 0x27, 0x1c, 0x00, 0x04})
+	binary.Write(&archive, binary.LittleEndian, crc32.ChecksumIEEE(startHeader))
+	archive.Write(startHeader)
+	archive.Write(packedStreams.Bytes())
+	archive.Write(header.Bytes())
+	return archive.Bytes()
+}
+
+func TestDetectAssetFormat(t *testing.T) {
+	tarContents := newTestTar(t, map[string]string{"tool": "tool"})
+	tests := []struct {
+		name     string
+		contents []byte
+		want     string
+	}{
+		{
+			name:     "test1",
+			contents: tarContents,
+			want:     tarFormat,
+		},
+		{
+			name:     "test2",
+			contents: compressTestPayload(t, tarContents, "gz"),
+			want:     compressedFormat,
+		},
+		{
+			name:     "test3",
+			contents: compressTestZstd(t, []byte("tool")),
+			want:     compressedFormat,
+		},
+		{
+			name:     "test4",
+			contents: []byte("PK\x03\x04"),
+			want:     zipFormat,
+		},
+		{
+			name:     "test5",
+			contents: newTest7z(t, []string{"tool"}, []string{"tool"}, unixTest7zAttributes),
+			want:     sevenZipFormat,
+		},
+		{
+			name:     "test6",
+			contents: []byte("!<arch>\ndebian-binary   "),
+			want:     debFormat,
+		},
+		{
+			name:     "test7",
+			contents: []byte{0xed, 0xab, 0xee, 0xdb, 0x03, 0x00},
+			want:     rpmFormat,
+		},
+		{
+			name:     "test8",
+			contents: []byte("#!/bin/sh\necho tool\n"),
+			want:     unknownFormat,
+		},
+		{
+			name:     "test9",
+			contents: []byte{},
+			want:     unknownFormat,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filePath := filepath.Join(t.TempDir(), "asset")
+			os.WriteFile(filePath, tt.contents, 0644)
+			got, err := detectAssetFormat(filePath)
+			if err != nil {
+				t.Fatalf("detectAssetFormat() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("detectAssetFormat() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_extractBinary_Formats(t *testing.T) {
+	SetNonInteractive(true)
+	defer SetNonInteractive(false)
+
+	tarContents := newTestTar(t, map[string]string{"tool-v1.0.0/tool": "tool v1.0.0"})
+	tests := []struct {
+		name     string
+		asset    string
+		contents []byte
+		wantPath string
+	}{
+		{
+			name:     "test1",
+			asset:    "tool-v1.0.0-linux-amd64.tar.zst",
+			contents: compressTestZstd(t, tarContents),
+			wantPath: "tool-v1.0.0/tool",
+		},
+		{
+			name:     "test2",
+			asset:    "tool-v1.0.0-linux-amd64",
+			contents: compressTestPayload(t, tarContents, "gz"),
+			wantPath: "tool-v1.0.0/tool",
+		},
+		{
+			name:     "test3",
+			asset:    "tool-linux-amd64.gz",
+			contents: compressTestPayload(t, []byte("tool v1.0.0"), "gz"),
+			wantPath: "tool-linux-amd64",
+		},
+		{
+			name:     "test4",
+			asset:    "tool.xz",
+			contents: compressTestPayload(t, []byte("tool v1.0.0"), "xz"),
+			wantPath: "tool",
+		},
+		{
+			name:     "test5",
+			asset:    "tool.zst",
+			contents: compressTestZstd(t, []byte("tool v1.0.0")),
+			wantPath: "tool",
+		},
+		{
+			name:     "test6",
+			asset:    "tool-v1.0.0-linux-amd64.7z",
+			contents: newTest7z(t, []string{"tool-v1.0.0/tool", "tool-v1.0.0/README"}, []string{"tool v1.0.0", "readme"}, unixTest7zAttributes),
+			wantPath: "tool-v1.0.0/tool",
+		},
+		{
+			name:     "test7",
+			asset:    "tool-v1.0.0-windows-amd64.7z",
+			contents: newTest7z(t, []string{"tool-v1.0.0/tool", "tool-v1.0.0/README"}, []string{"tool v1.0.0", "readme"}, msdosTest7zAttributes),
+			wantPath: "tool-v1.0.0/tool",
+		},
+		{
+			name:     "test8",
+			asset:    "tool",
+			contents: []byte("tool v1.0.0"),
+			wantPath: "tool",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir := t.TempDir()
+			extractionPath := filepath.Join(tempDir, "extracted")
+			os.MkdirAll(extractionPath, 0755)
+			assetPath := filepath.Join(tempDir, tt.asset)
+			os.WriteFile(assetPath, tt.contents, 0644)
+
+			if err := extractBinary(assetPath, extractionPath, ""); err != nil {
+				t.Fatalf("extractBinary() error = %v", err)
+			}
+			binaryPath := filepath.Join(extractionPath, filepath.FromSlash(tt.wantPath))
+			if got, _ := os.ReadFile(binaryPath); string(got) != "tool v1.0.0" {
+				t.Errorf("extractBinary() %v = %v, want tool v1.0.0", tt.wantPath, string(got))
+			}
+			if isExecutable, _ := isExecutableFile(binaryPath); !isExecutable {
+				t.Errorf("extractBinary() %v is not executable", tt.wantPath)
+			}
+		})
+	}
+}
+
+func Test_entryPath_SymlinkedParent(t *testing.T) {
+	tempDir := t.TempDir()
+	destPath := filepath.Join(tempDir, "extracted")
+	outsidePath := filepath.Join(tempDir, "outside")
+	os.MkdirAll(destPath, 0755)
+	os.MkdirAll(outsidePath, 0755)
+	os.Symlink(outsidePath, filepath.Join(destPath, "x"))
+
+	if _, err := entryPath(destPath, "x/a/b"); err == nil {
+		t.Errorf("entryPath() error = nil, want UnsafeArchiveEntryError")
+	}
+	if exists, _ := PathExists(filepath.Join(outsidePath, "a")); exists {
+		t.Errorf("entryPath() created a directory outside of the destPath")
+	}
+}
+
+func Test_extractTarStream_Symlinks(t *testing.T) {
+	tests := []struct {
+		name    string
+		links   [][2]string
+		wantErr bool
+	}{
+		{
+			name:    "test1",
+			links:   [][2]string{{"bin/tool", "../lib/tool"}},
+			wantErr: false,
+		},
+		{
+			name:    "test2",
+			links:   [][2]string{{"bin/tool", "/usr/lib/tool"}},
+			wantErr: false,
+		},
+		{
+			name:    "test3",
+			links:   [][2]string{{"tool", "../outside"}},
+			wantErr: true,
+		},
+		{
+			name:    "test4",
+			links:   [][2]string{{"bin/tool", "../../../outside"}},
+			wantErr: true,
+		},
+		{
+			name:    "test5",
+			links:   [][2]string{{"a", "."}, {"a/b/tool", "../../outside"}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var data bytes.Buffer
+			tarWriter := tar.NewWriter(&data)
+			for _, link := range tt.links {
+				tarWriter.WriteHeader(&tar.Header{Name: link[0], Mode: 0777, Typeflag: tar.TypeSymlink, Linkname: link[1]})
+			}
+			tarWriter.Close()
+			destPath := filepath.Join(t.TempDir(), "extracted")
+
+			err := extractTarStream(&data, destPath)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("extractTarStream() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if _, ok := err.(UnsafeArchiveEntryError); tt.wantErr && !ok {
+				t.Errorf("extractTarStream() error = %T, want UnsafeArchiveEntryError", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,318 @@
+package stew
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/marwanhawari/stew/constants"
+)
+
+// CacheEntry describes an asset in the download cache. The same asset can have an entry for each URL it was downloaded from.
+type CacheEntry struct {
+	SHA256   string    `json:"sha256"`
+	URL      string    `json:"url"`
+	Asset    string    `json:"asset"`
+	Size     int64     `json:"size"`
+	LastUsed time.Time `json:"lastUsed"`
+}
+
+// ShortHash returns the first 12 characters of the sha256 hash, or all of it if an edited index has a shorter hash
+func (entry CacheEntry) ShortHash() string {
+	if len(entry.SHA256) <= 12 {
+		return entry.SHA256
+	}
+	return entry.SHA256[:12]
+}
+
+// cacheLockWaitTimeout is how long to wait for another stew process to finish updating the download cache.
+// The cache is shared by every stew path, but it is only locked while its index is updated or pruned.
+const cacheLockWaitTimeout = time.Minute
+
+// lockCache takes an advisory lock on the download cache so that only one stew process changes its index at a time
+func lockCache(stewCachePath string) (func(), error) {
+	if err := os.MkdirAll(stewCachePath, 0755); err != nil {
+		return nil, err
+	}
+	lockFile, unlock, err := waitForFileLock(filepath.Join(stewCachePath, ".lock"), cacheLockWaitTimeout)
+	if err != nil {
+		return nil, err
+	}
+	if lockFile == nil {
+		return nil, CacheLockedError{StewCachePath: stewCachePath}
+	}
+	return unlock, nil
+}
+
+func cacheIndexPath(stewCachePath string) string {
+	return filepath.Join(stewCachePath, "index.json")
+}
+
+func cacheBlobPath(stewCachePath, assetHash string) string {
+	return filepath.Join(stewCachePath, "sha256", assetHash)
+}
+
+// ReadCacheIndex reads the entries of the download cache
+func ReadCacheIndex(stewCachePath string) ([]CacheEntry, error) {
+	indexBytes, err := os.ReadFile(cacheIndexPath(stewCachePath))
+	if os.IsNotExist(err) {
+		return []CacheEntry{}, nil
+	}
+	if err != nil {
+		return []CacheEntry{}, err
+	}
+
+	var entries []CacheEntry
+	if err = json.Unmarshal(indexBytes, &entries); err != nil {
+		return []CacheEntry{}, err
+	}
+	return entries, nil
+}
+
+func writeCacheIndex(stewCachePath string, entries []CacheEntry) error {
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].LastUsed.After(entries[j].LastUsed)
+	})
+	indexBytes, err := json.MarshalIndent(entries, "", "\t")
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(stewCachePath, 0755); err != nil {
+		return err
+	}
+	return writeFileAtomic(cacheIndexPath(stewCachePath), bytes.NewReader(indexBytes), 0644)
+}
+
+// IsAssetCached checks if an asset with the sha256 hash is in the download cache
+func IsAssetCached(stewCachePath, assetHash string) (bool, error) {
+	if assetHash == "" {
+		return false, nil
+	}
+	return PathExists(cacheBlobPath(stewCachePath, assetHash))
+}
+
+// DownloadAsset copies the asset from the download cache if an asset with the expected hash is cached.
+// Otherwise it downloads the asset and adds it to the cache. It returns the sha256 hash of the asset.
+func DownloadAsset(stewCachePath, downloadPath, url, expectedAssetHash string) (string, error) {
+	asset := filepath.Base(downloadPath)
+	if err := os.MkdirAll(filepath.Dir(downloadPath), 0755); err != nil {
+		return "", err
+	}
+
+	copiedFromCache, err := CopyCachedAsset(stewCachePath, downloadPath, url, expectedAssetHash)
+	if err != nil {
+		return "", err
+	}
+	if copiedFromCache {
+		return expectedAssetHash, nil
+	}
+
+	if err := DownloadFile(downloadPath, url); err != nil {
+		return "", err
+	}
+	assetHash, err := CalculateFileHash(downloadPath)
+	if err != nil {
+		return "", err
+	}
+	if expectedAssetHash != "" && assetHash != expectedAssetHash {
+		if err := os.RemoveAll(downloadPath); err != nil {
+			return "", err
+		}
+		return "", AssetHashMismatchError{Asset: asset, ExpectedHash: expectedAssetHash, Hash: assetHash}
+	}
+
+	return assetHash, addToCache(stewCachePath, downloadPath, url, assetHash)
+}
+
+// CopyCachedAsset copies the asset with the sha256 hash from the download cache to the downloadPath.
+// It returns false without downloading anything if the asset isn't cached or the cached asset no longer matches its hash.
+func CopyCachedAsset(stewCachePath, downloadPath, url, assetHash string) (bool, error) {
+	if assetHash == "" {
+		return false, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(downloadPath), 0755); err != nil {
+		return false, err
+	}
+	copiedFromCache, err := copyFromCache(stewCachePath, assetHash, downloadPath)
+	if err != nil || !copiedFromCache {
+		return false, err
+	}
+	fmt.Fprintf(humanOutput, "📦 Using the cached %v\n", constants.GreenColor(filepath.Base(downloadPath)))
+	return true, addToCache(stewCachePath, downloadPath, url, assetHash)
+}
+
+// copyFromCache copies a cached asset to the downloadPath. A cached asset that no longer matches its hash is removed.
+func copyFromCache(stewCachePath, assetHash, downloadPath string) (bool, error) {
+	blobPath := cacheBlobPath(stewCachePath, assetHash)
+	assetCached, err := PathExists(blobPath)
+	if err != nil || !assetCached {
+		return false, err
+	}
+
+	cachedHash, err := CalculateFileHash(blobPath)
+	if err != nil {
+		return false, err
+	}
+	if cachedHash != assetHash {
+		return false, os.RemoveAll(blobPath)
+	}
+
+	blob, err := os.Open(blobPath)
+	if err != nil {
+		return false, err
+	}
+	defer blob.Close()
+	if err = writeFileAtomic(downloadPath, blob, 0644); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func addToCache(stewCachePath, assetPath, url, assetHash string) error {
+	unlock, err := lockCache(stewCachePath)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	blobPath := cacheBlobPath(stewCachePath, assetHash)
+	assetCached, err := PathExists(blobPath)
+	if err != nil {
+		return err
+	}
+	if !assetCached {
+		if err = os.MkdirAll(filepath.Dir(blobPath), 0755); err != nil {
+			return err
+		}
+		asset, err := os.Open(assetPath)
+		if err != nil {
+			return err
+		}
+		defer asset.Close()
+		if err = writeFileAtomic(blobPath, asset, 0644); err != nil {
+			return err
+		}
+	}
+
+	assetInfo, err := os.Stat(assetPath)
+	if err != nil {
+		return err
+	}
+
+	entries, err := ReadCacheIndex(stewCachePath)
+	if err != nil {
+		return err
+	}
+	entry := CacheEntry{SHA256: assetHash, URL: url, Asset: filepath.Base(assetPath), Size: assetInfo.Size(), LastUsed: time.Now().UTC()}
+	entryFound := false
+	for index := range entries {
+		if entries[index].SHA256 == assetHash && entries[index].URL == url {
+			entries[index] = entry
+			entryFound = true
+		}
+	}
+	if !entryFound {
+		entries = append(entries, entry)
+	}
+	return writeCacheIndex(stewCachePath, entries)
+}
+
+// CacheSize returns the number of cached assets and their total size in bytes
+func CacheSize(stewCachePath string) (int, int64, error) {
+	blobs, err := os.ReadDir(filepath.Join(stewCachePath, "sha256"))
+	if os.IsNotExist(err) {
+		return 0, 0, nil
+	}
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var count int
+	var size int64
+	for _, blob := range blobs {
+		blobInfo, err := blob.Info()
+		if err != nil {
+			return 0, 0, err
+		}
+		if !blobInfo.Mode().IsRegular() || strings.HasPrefix(blob.Name(), ".") {
+			continue
+		}
+		count++
+		size += blobInfo.Size()
+	}
+	return count, size, nil
+}
+
+// PruneCache removes the cached assets that haven't been used in the olderThan duration and returns the removed entries.
+// A zero duration removes every cached asset.
+func PruneCache(stewCachePath string, olderThan time.Duration) ([]CacheEntry, error) {
+	unlock, err := lockCache(stewCachePath)
+	if err != nil {
+		return []CacheEntry{}, err
+	}
+	defer unlock()
+
+	entries, err := ReadCacheIndex(stewCachePath)
+	if err != nil {
+		return []CacheEntry{}, err
+	}
+
+	cutoff := time.Now().Add(-olderThan)
+	lastUsed := map[string]time.Time{}
+	for _, entry := range entries {
+		if entry.LastUsed.After(lastUsed[entry.SHA256]) {
+			lastUsed[entry.SHA256] = entry.LastUsed
+		}
+	}
+
+	keptEntries := []CacheEntry{}
+	prunedEntries := []CacheEntry{}
+	for _, entry := range entries {
+		if olderThan != 0 && lastUsed[entry.SHA256].After(cutoff) {
+			keptEntries = append(keptEntries, entry)
+			continue
+		}
+		if err = os.RemoveAll(cacheBlobPath(stewCachePath, entry.SHA256)); err != nil {
+			return []CacheEntry{}, err
+		}
+		prunedEntries = append(prunedEntries, entry)
+	}
+
+	return prunedEntries, writeCacheIndex(stewCachePath, keptEntries)
+}
+
+// ParseAge parses a duration like 12h or 30d. Days aren't supported by time.ParseDuration.
+func ParseAge(age string) (time.Duration, error) {
+	if days, found := strings.CutSuffix(age, "d"); found {
+		numDays, err := strconv.Atoi(days)
+		if err != nil || numDays < 0 {
+			return 0, InvalidAgeError{Age: age}
+		}
+		return time.Duration(numDays) * 24 * time.Hour, nil
+	}
+	duration, err := time.ParseDuration(age)
+	if err != nil || duration < 0 {
+		return 0, InvalidAgeError{Age: age}
+	}
+	return duration, nil
+}
+
+// FormatBytes formats a number of bytes in a human readable way
+func FormatBytes(numBytes int64) string {
+	const unit = 1024
+	if numBytes < unit {
+		return fmt.Sprintf("%d B", numBytes)
+	}
+	div, exp := int64(unit), 0
+	for n := numBytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(numBytes)/float64(div), "KMGTPE"[exp])
+}
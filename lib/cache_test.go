@@ -0,0 +1,221 @@
+package stew
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDownloadAsset_Cached(t *testing.T) {
+	stewCachePath := filepath.Join(t.TempDir(), "cache")
+	assetPath := filepath.Join(t.TempDir(), "ppath-v0.0.3-linux-amd64.tar.gz")
+	os.WriteFile(assetPath, []byte("asset"), 0644)
+	assetHash, _ := CalculateFileHash(assetPath)
+
+	url := "https://github.com/marwanhawari/ppath/releases/download/v0.0.3/ppath-v0.0.3-linux-amd64.tar.gz"
+	if err := addToCache(stewCachePath, assetPath, url, assetHash); err != nil {
+		t.Fatalf("addToCache() error = %v", err)
+	}
+
+	// The URL can't be downloaded, so the asset has to come from the cache
+	downloadPath := filepath.Join(t.TempDir(), "ppath-v0.0.3-linux-amd64.tar.gz")
+	got, err := DownloadAsset(stewCachePath, downloadPath, "http://localhost:0/ppath", assetHash)
+	if err != nil {
+		t.Fatalf("DownloadAsset() error = %v", err)
+	}
+	if got != assetHash {
+		t.Errorf("DownloadAsset() = %v, want %v", got, assetHash)
+	}
+	if contents, _ := os.ReadFile(downloadPath); string(contents) != "asset" {
+		t.Errorf("DownloadAsset() contents = %v, want asset", string(contents))
+	}
+
+	entries, _ := ReadCacheIndex(stewCachePath)
+	if len(entries) != 2 {
+		t.Errorf("ReadCacheIndex() has %v entries, want 2", len(entries))
+	}
+}
+
+func TestCopyCachedAsset_Corrupted(t *testing.T) {
+	stewCachePath := filepath.Join(t.TempDir(), "cache")
+	assetPath := filepath.Join(t.TempDir(), "ppath-v0.0.3-linux-amd64.tar.gz")
+	os.WriteFile(assetPath, []byte("asset"), 0644)
+	assetHash, _ := CalculateFileHash(assetPath)
+	if err := addToCache(stewCachePath, assetPath, "https://example.com/ppath", assetHash); err != nil {
+		t.Fatalf("addToCache() error = %v", err)
+	}
+	os.WriteFile(cacheBlobPath(stewCachePath, assetHash), []byte("corrupted"), 0644)
+
+	downloadPath := filepath.Join(t.TempDir(), "ppath-v0.0.3-linux-amd64.tar.gz")
+	copied, err := CopyCachedAsset(stewCachePath, downloadPath, "https://example.com/ppath", assetHash)
+	if err != nil {
+		t.Fatalf("CopyCachedAsset() error = %v", err)
+	}
+	if copied {
+		t.Errorf("CopyCachedAsset() = true, want false")
+	}
+	if cached, _ := IsAssetCached(stewCachePath, assetHash); cached {
+		t.Errorf("IsAssetCached() = true, want the corrupted asset to be removed")
+	}
+	if exists, _ := PathExists(downloadPath); exists {
+		t.Errorf("CopyCachedAsset() created %v", downloadPath)
+	}
+}
+
+func TestAddToCache_Concurrent(t *testing.T) {
+	stewCachePath := filepath.Join(t.TempDir(), "cache")
+	assetPath := filepath.Join(t.TempDir(), "ppath-v0.0.3-linux-amd64.tar.gz")
+	os.WriteFile(assetPath, []byte("asset"), 0644)
+	assetHash, _ := CalculateFileHash(assetPath)
+
+	// Every process adds its own entry, so none of them can be lost while another one updates the index
+	var wg sync.WaitGroup
+	for index := 0; index < 8; index++ {
+		wg.Add(1)
+		go func(index int) {
+			defer wg.Done()
+			if err := addToCache(stewCachePath, assetPath, "https://example.com/"+strconv.Itoa(index), assetHash); err != nil {
+				t.Errorf("addToCache() error = %v", err)
+			}
+		}(index)
+	}
+	wg.Wait()
+
+	entries, _ := ReadCacheIndex(stewCachePath)
+	if len(entries) != 8 {
+		t.Errorf("ReadCacheIndex() has %v entries, want 8", len(entries))
+	}
+}
+
+func TestPruneCache(t *testing.T) {
+	stewCachePath := t.TempDir()
+	os.MkdirAll(filepath.Join(stewCachePath, "sha256"), 0755)
+	os.WriteFile(cacheBlobPath(stewCachePath, "old"), []byte("old"), 0644)
+	os.WriteFile(cacheBlobPath(stewCachePath, "new"), []byte("new"), 0644)
+	writeCacheIndex(stewCachePath, []CacheEntry{
+		{SHA256: "old", URL: "https://example.com/old", Asset: "old", Size: 3, LastUsed: time.Now().Add(-48 * time.Hour)},
+		{SHA256: "new", URL: "https://example.com/new", Asset: "new", Size: 3, LastUsed: time.Now()},
+	})
+
+	pruned, err := PruneCache(stewCachePath, 24*time.Hour)
+	if err != nil {
+		t.Fatalf("PruneCache() error = %v", err)
+	}
+	if len(pruned) != 1 || pruned[0].SHA256 != "old" {
+		t.Errorf("PruneCache() = %v, want the old entry", pruned)
+	}
+	count, size, _ := CacheSize(stewCachePath)
+	if count != 1 || size != 3 {
+		t.Errorf("CacheSize() = %v, %v, want 1, 3", count, size)
+	}
+
+	pruned, err = PruneCache(stewCachePath, 0)
+	if err != nil {
+		t.Fatalf("PruneCache() error = %v", err)
+	}
+	if len(pruned) != 1 || pruned[0].SHA256 != "new" {
+		t.Errorf("PruneCache() = %v, want the new entry", pruned)
+	}
+}
+
+func TestParseAge(t *testing.T) {
+	tests := []struct {
+		name    string
+		age     string
+		want    time.Duration
+		wantErr bool
+	}{
+		{
+			name: "test1",
+			age:  "30d",
+			want: 30 * 24 * time.Hour,
+		},
+		{
+			name: "test2",
+			age:  "12h",
+			want: 12 * time.Hour,
+		},
+		{
+			name:    "test3",
+			age:     "a week",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseAge(tt.age)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseAge() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("ParseAge() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCacheEntry_ShortHash(t *testing.T) {
+	tests := []struct {
+		name   string
+		sha256 string
+		want   string
+	}{
+		{
+			name:   "test1",
+			sha256: "a8d758f4b3aa1c5c550836d2f5598579203fa678a780cb5ed13073a05eb4fc49",
+			want:   "a8d758f4b3aa",
+		},
+		{
+			name:   "test2",
+			sha256: "a8d758",
+			want:   "a8d758",
+		},
+		{
+			name:   "test3",
+			sha256: "",
+			want:   "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := (CacheEntry{SHA256: tt.sha256}).ShortHash(); got != tt.want {
+				t.Errorf("CacheEntry.ShortHash() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	tests := []struct {
+		name     string
+		numBytes int64
+		want     string
+	}{
+		{
+			name:     "test1",
+			numBytes: 512,
+			want:     "512 B",
+		},
+		{
+			name:     "test2",
+			numBytes: 1536,
+			want:     "1.5 KiB",
+		},
+		{
+			name:     "test3",
+			numBytes: 3 * 1024 * 1024,
+			want:     "3.0 MiB",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FormatBytes(tt.numBytes); got != tt.want {
+				t.Errorf("FormatBytes() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
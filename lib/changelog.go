@@ -0,0 +1,86 @@
+package stew
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// ReleasesBetween returns the releases after fromTag up to and including toTag, newest first.
+// Drafts and prereleases are skipped unless the prerelease is toTag. Every release up to toTag is returned if fromTag isn't a release anymore.
+func ReleasesBetween(releases GithubAPIResponse, fromTag, toTag string) GithubAPIResponse {
+	betweenReleases := GithubAPIResponse{}
+	toTagFound := false
+	for _, release := range releases {
+		if release.TagName == fromTag {
+			break
+		}
+		if release.TagName == toTag {
+			toTagFound = true
+		}
+		if !toTagFound || release.Draft || (release.Prerelease && release.TagName != toTag) {
+			continue
+		}
+		betweenReleases = append(betweenReleases, release)
+	}
+	return betweenReleases
+}
+
+// FormatChangelog formats the release notes of the releases between two tags of a binary as markdown
+func FormatChangelog(binary, fromTag, toTag string, releases GithubAPIResponse) string {
+	var changelog strings.Builder
+	fmt.Fprintf(&changelog, "# %v %v → %v\n\n", binary, fromTag, toTag)
+	if len(releases) == 0 {
+		changelog.WriteString("No release notes found.\n\n")
+	}
+	for _, release := range releases {
+		fmt.Fprintf(&changelog, "## %v (%v)\n\n", release.TagName, formatDate(release.PublishedAt))
+		body := strings.TrimSpace(strings.ReplaceAll(release.Body, "\r\n", "\n"))
+		if body == "" {
+			body = "No release notes."
+		}
+		fmt.Fprintf(&changelog, "%v\n\n", body)
+	}
+	return changelog.String()
+}
+
+// WriteChangelog writes the changelog of a binary to the changelogs directory in the stew path and returns its path
+func WriteChangelog(stewPath, binary, changelog string) (string, error) {
+	changelogPath := filepath.Join(stewPath, "changelogs", binary+".md")
+	if err := os.MkdirAll(filepath.Dir(changelogPath), 0755); err != nil {
+		return "", err
+	}
+	if err := writeFileAtomic(changelogPath, strings.NewReader(changelog), 0644); err != nil {
+		return "", err
+	}
+	return changelogPath, nil
+}
+
+// ShowInPager shows the contents in the PAGER, which defaults to less. The contents are printed if the human readable output isn't a terminal or there isn't a pager.
+func ShowInPager(contents string) error {
+	if nonInteractive || !term.IsTerminal(int(humanOutput.Fd())) {
+		fmt.Fprint(humanOutput, contents)
+		return nil
+	}
+
+	pager := strings.Fields(os.Getenv("PAGER"))
+	if len(pager) == 0 {
+		pager = []string{"less", "-R"}
+	}
+	pagerPath, err := exec.LookPath(pager[0])
+	if err != nil {
+		fmt.Fprint(humanOutput, contents)
+		return nil
+	}
+
+	command := exec.Command(pagerPath, pager[1:]...)
+	command.Stdin = bytes.NewBufferString(contents)
+	command.Stdout = humanOutput
+	command.Stderr = os.Stderr
+	return command.Run()
+}
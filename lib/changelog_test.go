@@ -0,0 +1,82 @@
+package stew
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestReleasesBetween(t *testing.T) {
+	releases := GithubAPIResponse{
+		{TagName: "v5-rc1", Prerelease: true},
+		{TagName: "v4"},
+		{TagName: "v3-rc1", Prerelease: true},
+		{TagName: "v3", Draft: true},
+		{TagName: "v2"},
+		{TagName: "v1"},
+	}
+	tests := []struct {
+		name    string
+		fromTag string
+		toTag   string
+		want    []string
+	}{
+		{
+			name:    "test1",
+			fromTag: "v1",
+			toTag:   "v4",
+			want:    []string{"v4", "v2"},
+		},
+		{
+			name:    "test2",
+			fromTag: "v2",
+			toTag:   "v5-rc1",
+			want:    []string{"v5-rc1", "v4"},
+		},
+		{
+			name:    "test3",
+			fromTag: "v0",
+			toTag:   "v2",
+			want:    []string{"v2", "v1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := []string{}
+			for _, release := range ReleasesBetween(releases, tt.fromTag, tt.toTag) {
+				got = append(got, release.TagName)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ReleasesBetween() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatChangelog(t *testing.T) {
+	releases := GithubAPIResponse{
+		{TagName: "v2", PublishedAt: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), Body: "- Fixed a bug\r\n"},
+		{TagName: "v1.1"},
+	}
+	want := "# ppath v1 → v2\n\n## v2 (2024-02-01)\n\n- Fixed a bug\n\n## v1.1 (-)\n\nNo release notes.\n\n"
+	if got := FormatChangelog("ppath", "v1", "v2", releases); got != want {
+		t.Errorf("FormatChangelog() = %q, want %q", got, want)
+	}
+}
+
+func TestWriteChangelog(t *testing.T) {
+	stewPath := t.TempDir()
+	got, err := WriteChangelog(stewPath, "ppath", "# ppath v1 → v2\n")
+	if err != nil {
+		t.Fatalf("WriteChangelog() error = %v", err)
+	}
+	want := filepath.Join(stewPath, "changelogs", "ppath.md")
+	if got != want {
+		t.Errorf("WriteChangelog() = %v, want %v", got, want)
+	}
+	if contents, _ := os.ReadFile(want); string(contents) != "# ppath v1 → v2\n" {
+		t.Errorf("WriteChangelog() contents = %q", string(contents))
+	}
+}
@@ -1,6 +1,7 @@
 package stew
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -56,6 +57,30 @@ func GetDefaultStewBinPath(userOS string) (string, error) {
 	return stewBinPath, nil
 }
 
+// GetDefaultStewCachePath will return the default path of the download cache that is shared by every stew path
+func GetDefaultStewCachePath(userOS string) (string, error) {
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	var stewCachePath string
+	switch userOS {
+	case "windows":
+		stewCachePath = filepath.Join(homeDir, "AppData", "Local", "stew", "Cache")
+	default:
+		xdgCacheHomePath := os.Getenv("XDG_CACHE_HOME")
+		if xdgCacheHomePath == "" {
+			stewCachePath = filepath.Join(homeDir, ".cache", "stew")
+		} else {
+			stewCachePath = filepath.Join(xdgCacheHomePath, "stew")
+		}
+	}
+
+	return stewCachePath, nil
+}
+
 // GetStewConfigFilePath will return the stew config file path
 func GetStewConfigFilePath(userOS string) (string, error) {
 
@@ -85,6 +110,43 @@ type StewConfig struct {
 	StewPath               string   `json:"stewPath"`
 	StewBinPath            string   `json:"stewBinPath"`
 	ExcludedFromUpgradeAll []string `json:"excludedFromUpgradeAll"`
+	StewCachePath          string   `json:"stewCachePath,omitempty"`
+	// URLRewrites are applied to every HTTP request, e.g. to download through an artifact proxy
+	URLRewrites []URLRewriteRule `json:"urlRewrites,omitempty"`
+	HTTP        *HTTPConfig      `json:"http,omitempty"`
+	// CredentialHelper is a command that is run with a host as its last argument to get the credential for the host
+	CredentialHelper string `json:"credentialHelper,omitempty"`
+	// NetrcDefaultHosts are the hosts that the default entry of the netrc file is used for
+	NetrcDefaultHosts []string `json:"netrcDefaultHosts,omitempty"`
+	// Hooks are keyed by the binary name and replace the hooks of the package from the Stewfile
+	Hooks map[string]Hooks `json:"hooks,omitempty"`
+	// LinkMode is copy to copy the binaries to the stewBinPath, or symlink to keep them in the package store and symlink them there
+	LinkMode string `json:"linkMode,omitempty"`
+	// ManPages and Completions install the man pages and shell completions of .deb and .rpm packages in the share directory of the stewPath
+	ManPages    bool `json:"manPages,omitempty"`
+	Completions bool `json:"completions,omitempty"`
+}
+
+// HTTPConfig configures the HTTP client that is shared by every request.
+// The proxy is always taken from the HTTPS_PROXY, HTTP_PROXY, and NO_PROXY environment variables.
+type HTTPConfig struct {
+	// CABundle is a PEM file with certificates that are trusted in addition to the system certificates
+	CABundle   string `json:"caBundle,omitempty"`
+	ClientCert string `json:"clientCert,omitempty"`
+	ClientKey  string `json:"clientKey,omitempty"`
+	// The timeouts are durations like 30s
+	ConnectTimeout  string `json:"connectTimeout,omitempty"`
+	ResponseTimeout string `json:"responseTimeout,omitempty"`
+	UserAgent       string `json:"userAgent,omitempty"`
+}
+
+// URLRewriteRule replaces the start of the URLs that have the Prefix, or the part of the URLs that match the Regex.
+// The Headers are added to the rewritten requests, and environment variables in their values are expanded.
+type URLRewriteRule struct {
+	Prefix      string            `json:"prefix,omitempty"`
+	Regex       string            `json:"regex,omitempty"`
+	Replacement string            `json:"replacement"`
+	Headers     map[string]string `json:"headers,omitempty"`
 }
 
 func ReadStewConfigJSON(stewConfigFilePath string) (StewConfig, error) {
@@ -111,7 +173,7 @@ func WriteStewConfigJSON(stewConfigFileJSON StewConfig, outputPath string) error
 		return err
 	}
 
-	err = os.WriteFile(outputPath, stewConfigFileBytes, 0644)
+	err = writeFileAtomic(outputPath, bytes.NewReader(stewConfigFileBytes), 0644)
 	if err != nil {
 		return err
 	}
@@ -158,6 +220,11 @@ func NewStewConfig(userOS string) (StewConfig, error) {
 		if len(stewConfig.ExcludedFromUpgradeAll) == 0 {
 			stewConfig.ExcludedFromUpgradeAll = defaultExcludedFromUpgradeAll
 		}
+	} else if nonInteractive {
+		stewConfig.StewPath = defaultStewPath
+		stewConfig.StewBinPath = defaultStewBinPath
+		stewConfig.ExcludedFromUpgradeAll = defaultExcludedFromUpgradeAll
+		fmt.Fprintf(humanOutput, "📄 Updated %v\n", constants.GreenColor(stewConfigFilePath))
 	} else {
 		defaultInstalledPackages := []PackageData{}
 		selectedStewPath, selectedStewBinPath, excludedFromUpgradeAll, err := PromptConfig(defaultStewPath, defaultStewBinPath, defaultInstalledPackages, defaultExcludedFromUpgradeAll)
@@ -167,7 +234,7 @@ func NewStewConfig(userOS string) (StewConfig, error) {
 		stewConfig.StewPath = selectedStewPath
 		stewConfig.StewBinPath = selectedStewBinPath
 		stewConfig.ExcludedFromUpgradeAll = excludedFromUpgradeAll
-		fmt.Printf("📄 Updated %v\n", constants.GreenColor(stewConfigFilePath))
+		fmt.Fprintf(humanOutput, "📄 Updated %v\n", constants.GreenColor(stewConfigFilePath))
 	}
 
 	pathVariable := os.Getenv("PATH")
@@ -217,6 +284,7 @@ type SystemInfo struct {
 	StewPkgPath      string
 	StewLockFilePath string
 	StewTmpPath      string
+	StewCachePath    string
 }
 
 // NewSystemInfo creates a new instance of the SystemInfo struct
@@ -227,6 +295,7 @@ func NewSystemInfo(stewConfig StewConfig) SystemInfo {
 	systemInfo.StewPkgPath = filepath.Join(stewConfig.StewPath, "pkg")
 	systemInfo.StewLockFilePath = filepath.Join(stewConfig.StewPath, "Stewfile.lock.json")
 	systemInfo.StewTmpPath = filepath.Join(stewConfig.StewPath, "tmp")
+	systemInfo.StewCachePath = stewConfig.StewCachePath
 	return systemInfo
 }
 
@@ -238,7 +307,33 @@ func Initialize() (string, string, StewConfig, SystemInfo, error) {
 	if err != nil {
 		return "", "", StewConfig{}, SystemInfo{}, err
 	}
+	if err = SetURLRewriteRules(stewConfig.URLRewrites); err != nil {
+		return "", "", StewConfig{}, SystemInfo{}, err
+	}
+	if err = SetHTTPConfig(stewConfig.HTTP); err != nil {
+		return "", "", StewConfig{}, SystemInfo{}, err
+	}
+	if err = SetConfigHooks(stewConfig.Hooks); err != nil {
+		return "", "", StewConfig{}, SystemInfo{}, err
+	}
+	if err = SetLinkMode(stewConfig.LinkMode); err != nil {
+		return "", "", StewConfig{}, SystemInfo{}, err
+	}
+	SetSystemPackageExtras(stewConfig.ManPages, stewConfig.Completions)
+	stewCredentialsFilePath, err := GetStewCredentialsFilePath(userOS)
+	if err != nil {
+		return "", "", StewConfig{}, SystemInfo{}, err
+	}
+	if err = SetCredentialSources(stewCredentialsFilePath, stewConfig.CredentialHelper, stewConfig.NetrcDefaultHosts); err != nil {
+		return "", "", StewConfig{}, SystemInfo{}, err
+	}
 	systemInfo := NewSystemInfo(stewConfig)
+	if systemInfo.StewCachePath == "" {
+		systemInfo.StewCachePath, err = GetDefaultStewCachePath(userOS)
+		if err != nil {
+			return "", "", StewConfig{}, SystemInfo{}, err
+		}
+	}
 
 	return userOS, userArch, stewConfig, systemInfo, nil
 }
@@ -279,8 +374,8 @@ func PromptConfig(suggestedStewPath string, suggestedStewBinPath string, install
 
 func ValidateStewBinPath(stewBinPath, pathVariable string) bool {
 	if !strings.Contains(pathVariable, stewBinPath) {
-		fmt.Printf("%v The stewBinPath %v is not in your PATH variable.\nYou need to add %v to PATH.\n", constants.YellowColor("WARNING:"), constants.YellowColor(stewBinPath), constants.YellowColor(stewBinPath))
-		fmt.Printf("Add the following line to your ~/.zshrc or ~/.bashrc file then start a new terminal session:\n\nexport PATH=\"%v:$PATH\"\n\n", stewBinPath)
+		fmt.Fprintf(humanOutput, "%v The stewBinPath %v is not in your PATH variable.\nYou need to add %v to PATH.\n", constants.YellowColor("WARNING:"), constants.YellowColor(stewBinPath), constants.YellowColor(stewBinPath))
+		fmt.Fprintf(humanOutput, "Add the following line to your ~/.zshrc or ~/.bashrc file then start a new terminal session:\n\nexport PATH=\"%v:$PATH\"\n\n", stewBinPath)
 		return false
 	}
 
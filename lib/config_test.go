@@ -50,3 +50,49 @@ func TestGetDefaultStewPath(t *testing.T) {
 		})
 	}
 }
+
+func TestGetDefaultStewCachePath(t *testing.T) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		t.Errorf("Could not get os.UserHomeDir()")
+	}
+	t.Setenv("XDG_CACHE_HOME", "")
+	type args struct {
+		userOS string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "test1",
+			args: args{
+				userOS: "darwin",
+			},
+			want:    filepath.Join(homeDir, ".cache", "stew"),
+			wantErr: false,
+		},
+		{
+			name: "test2",
+			args: args{
+				userOS: "windows",
+			},
+			want:    filepath.Join(homeDir, "AppData", "Local", "stew", "Cache"),
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GetDefaultStewCachePath(tt.args.userOS)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetDefaultStewCachePath() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("GetDefaultStewCachePath() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,368 @@
+package stew
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/kballard/go-shellquote"
+)
+
+// Credential is the authorization that is sent to a host
+type Credential struct {
+	Host string
+	// Source describes where the credential was found, e.g. the netrc file
+	Source   string
+	Scheme   string
+	Token    string
+	Username string
+	Password string
+}
+
+// AuthStatus describes the credential that is used for a host without revealing it
+type AuthStatus struct {
+	Host   string `json:"host"`
+	Kind   string `json:"kind,omitempty"`
+	Source string `json:"source,omitempty"`
+}
+
+// Header returns the value of the Authorization header for the credential
+func (c Credential) Header() string {
+	if c.Token == "" {
+		return "Basic " + base64.StdEncoding.EncodeToString([]byte(c.Username+":"+c.Password))
+	}
+	scheme := c.Scheme
+	if scheme == "" {
+		scheme = "Bearer"
+	}
+	return scheme + " " + c.Token
+}
+
+// Kind describes the type of the credential without revealing it
+func (c Credential) Kind() string {
+	if c.Token == "" {
+		return "basic auth for " + c.Username
+	}
+	scheme := strings.ToLower(c.Scheme)
+	if scheme == "" {
+		scheme = "bearer"
+	}
+	if scheme == "token" {
+		return "token"
+	}
+	return scheme + " token"
+}
+
+// CredentialsFileEntry contains the credential of a host in the stew credentials file. Either the token or the username and password are set.
+type CredentialsFileEntry struct {
+	Scheme   string `json:"scheme,omitempty"`
+	Token    string `json:"token,omitempty"`
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+}
+
+// CredentialsFile contains the credentials of each host
+type CredentialsFile struct {
+	Hosts map[string]CredentialsFileEntry `json:"hosts"`
+}
+
+var credentialsFilePath string
+var credentialHelper []string
+var netrcDefaultHosts []string
+var credentialCache = map[string]*Credential{}
+
+// credentialCacheMutex is held for the whole lookup, so concurrent requests to a host run the gh CLI and the credential helper only once
+var credentialCacheMutex sync.Mutex
+
+// GetStewCredentialsFilePath will return the path of the stew credentials file, which is next to the stew config file
+func GetStewCredentialsFilePath(userOS string) (string, error) {
+	stewConfigFilePath, err := GetStewConfigFilePath(userOS)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(stewConfigFilePath), "credentials.json"), nil
+}
+
+// SetCredentialSources sets the stew credentials file and the credential helper command that are used to find credentials,
+// and the hosts that the default entry of the netrc file is sent to.
+// The credential helper command is split into arguments like a shell would, so arguments with spaces can be quoted.
+func SetCredentialSources(stewCredentialsFilePath string, stewCredentialHelper string, stewNetrcDefaultHosts []string) error {
+	helper, err := shellquote.Split(stewCredentialHelper)
+	if err != nil {
+		return CredentialHelperError{Command: stewCredentialHelper, Err: err}
+	}
+	credentialsFilePath = stewCredentialsFilePath
+	credentialHelper = helper
+	netrcDefaultHosts = stewNetrcDefaultHosts
+	credentialCacheMutex.Lock()
+	defer credentialCacheMutex.Unlock()
+	credentialCache = map[string]*Credential{}
+	return nil
+}
+
+// ReadCredentialsFile reads the stew credentials file. It must only be readable by the user.
+func ReadCredentialsFile(path string) (CredentialsFile, error) {
+	fileInfo, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return CredentialsFile{Hosts: map[string]CredentialsFileEntry{}}, nil
+	}
+	if err != nil {
+		return CredentialsFile{}, err
+	}
+	if runtime.GOOS != "windows" && fileInfo.Mode().Perm()&0077 != 0 {
+		return CredentialsFile{}, InsecureCredentialsFileError{Path: path, Mode: fileInfo.Mode().Perm()}
+	}
+
+	credentialsBytes, err := os.ReadFile(path)
+	if err != nil {
+		return CredentialsFile{}, err
+	}
+	var credentialsFile CredentialsFile
+	if err = json.Unmarshal(credentialsBytes, &credentialsFile); err != nil {
+		return CredentialsFile{}, err
+	}
+	if credentialsFile.Hosts == nil {
+		credentialsFile.Hosts = map[string]CredentialsFileEntry{}
+	}
+	return credentialsFile, nil
+}
+
+// FindCredential looks up the credential for a host in this order: the GITHUB_TOKEN environment variable,
+// the stew credentials file, the netrc file, the gh CLI, and the credential helper.
+// The GITHUB_TOKEN and gh CLI tokens are only used for api.github.com.
+func FindCredential(host string) (*Credential, error) {
+	credentialCacheMutex.Lock()
+	defer credentialCacheMutex.Unlock()
+	if credential, found := credentialCache[host]; found {
+		return credential, nil
+	}
+
+	lookups := []func(string) (*Credential, error){
+		credentialFromGithubToken,
+		credentialFromCredentialsFile,
+		credentialFromNetrc,
+		credentialFromGhCLI,
+		credentialFromHelper,
+	}
+	for _, lookup := range lookups {
+		credential, err := lookup(host)
+		if err != nil {
+			return nil, err
+		}
+		if credential != nil {
+			credential.Host = host
+			credentialCache[host] = credential
+			return credential, nil
+		}
+	}
+
+	credentialCache[host] = nil
+	return nil, nil
+}
+
+func credentialFromGithubToken(host string) (*Credential, error) {
+	githubToken := os.Getenv("GITHUB_TOKEN")
+	if host != "api.github.com" || githubToken == "" {
+		return nil, nil
+	}
+	return &Credential{Source: "GITHUB_TOKEN environment variable", Scheme: "token", Token: githubToken}, nil
+}
+
+func credentialFromCredentialsFile(host string) (*Credential, error) {
+	if credentialsFilePath == "" {
+		return nil, nil
+	}
+	credentialsFile, err := ReadCredentialsFile(credentialsFilePath)
+	if err != nil {
+		return nil, err
+	}
+	entry, found := credentialsFile.Hosts[host]
+	if !found {
+		return nil, nil
+	}
+	return &Credential{Source: credentialsFilePath, Scheme: entry.Scheme, Token: entry.Token, Username: entry.Username, Password: entry.Password}, nil
+}
+
+func getNetrcPath() (string, error) {
+	if netrcPath := os.Getenv("NETRC"); netrcPath != "" {
+		return netrcPath, nil
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	netrcPath := filepath.Join(homeDir, ".netrc")
+	if runtime.GOOS == "windows" {
+		if netrcExists, _ := PathExists(netrcPath); !netrcExists {
+			netrcPath = filepath.Join(homeDir, "_netrc")
+		}
+	}
+	return netrcPath, nil
+}
+
+func credentialFromNetrc(host string) (*Credential, error) {
+	netrcPath, err := getNetrcPath()
+	if err != nil {
+		return nil, err
+	}
+	netrcBytes, err := os.ReadFile(netrcPath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	machines := parseNetrc(string(netrcBytes))
+	machine, found := machines[host]
+	// The default entry would match every host, so it is only sent to the hosts that are configured for it
+	if _, defaultHost := Contains(netrcDefaultHosts, host); !found && defaultHost {
+		machine, found = machines[""]
+	}
+	if !found || (machine.Username == "" && machine.Password == "") {
+		return nil, nil
+	}
+	return &Credential{Source: netrcPath, Username: machine.Username, Password: machine.Password}, nil
+}
+
+// parseNetrc returns the login and password of each machine in a netrc file. The default entry has an empty machine name.
+func parseNetrc(netrc string) map[string]Credential {
+	machines := map[string]Credential{}
+	lines := strings.Split(netrc, "\n")
+	var tokens []string
+	for index := 0; index < len(lines); index++ {
+		fields := strings.Fields(lines[index])
+		if len(fields) > 0 && fields[0] == "macdef" {
+			// Macro definitions continue until the next empty line
+			for index < len(lines) && strings.TrimSpace(lines[index]) != "" {
+				index++
+			}
+			continue
+		}
+		tokens = append(tokens, fields...)
+	}
+
+	machine, inMachine := "", false
+	for index := 0; index < len(tokens); index++ {
+		var value string
+		if index+1 < len(tokens) {
+			value = tokens[index+1]
+		}
+		switch tokens[index] {
+		case "machine":
+			machine, inMachine = value, true
+			index++
+		case "default":
+			machine, inMachine = "", true
+		case "login":
+			if inMachine {
+				credential := machines[machine]
+				credential.Username = value
+				machines[machine] = credential
+			}
+			index++
+		case "password":
+			if inMachine {
+				credential := machines[machine]
+				credential.Password = value
+				machines[machine] = credential
+			}
+			index++
+		case "account":
+			index++
+		}
+	}
+	return machines
+}
+
+func credentialFromGhCLI(host string) (*Credential, error) {
+	if host != "api.github.com" {
+		return nil, nil
+	}
+	ghPath, err := exec.LookPath("gh")
+	if err != nil {
+		return nil, nil
+	}
+	output, err := exec.Command(ghPath, "auth", "token", "--hostname", "github.com").Output()
+	if err != nil {
+		// gh is installed but not logged in
+		return nil, nil
+	}
+	token := strings.TrimSpace(string(output))
+	if token == "" {
+		return nil, nil
+	}
+	return &Credential{Source: "gh CLI", Scheme: "token", Token: token}, nil
+}
+
+// credentialFromHelper runs the credential helper with the host as its last argument.
+// The helper prints a JSON object like a credentials file entry, or nothing if it has no credential for the host.
+func credentialFromHelper(host string) (*Credential, error) {
+	if len(credentialHelper) == 0 {
+		return nil, nil
+	}
+	command := exec.Command(credentialHelper[0], append(credentialHelper[1:], host)...)
+	var stderr bytes.Buffer
+	command.Stderr = &stderr
+	output, err := command.Output()
+	if err != nil {
+		return nil, CredentialHelperError{Command: strings.Join(credentialHelper, " "), Err: fmt.Errorf("%v %v", err, strings.TrimSpace(stderr.String()))}
+	}
+	if len(bytes.TrimSpace(output)) == 0 {
+		return nil, nil
+	}
+
+	var entry CredentialsFileEntry
+	if err = json.Unmarshal(output, &entry); err != nil {
+		return nil, CredentialHelperError{Command: strings.Join(credentialHelper, " "), Err: err}
+	}
+	if entry.Token == "" && entry.Username == "" && entry.Password == "" {
+		return nil, nil
+	}
+	return &Credential{Source: "credential helper", Scheme: entry.Scheme, Token: entry.Token, Username: entry.Username, Password: entry.Password}, nil
+}
+
+// KnownCredentialHosts returns the hosts that have a credential in the stew credentials file or the netrc file, plus api.github.com
+func KnownCredentialHosts() ([]string, error) {
+	hosts := map[string]bool{"api.github.com": true}
+	for _, host := range netrcDefaultHosts {
+		hosts[host] = true
+	}
+
+	if credentialsFilePath != "" {
+		credentialsFile, err := ReadCredentialsFile(credentialsFilePath)
+		if err != nil {
+			return []string{}, err
+		}
+		for host := range credentialsFile.Hosts {
+			hosts[host] = true
+		}
+	}
+
+	netrcPath, err := getNetrcPath()
+	if err != nil {
+		return []string{}, err
+	}
+	if netrcBytes, err := os.ReadFile(netrcPath); err == nil {
+		for host := range parseNetrc(string(netrcBytes)) {
+			if host != "" {
+				hosts[host] = true
+			}
+		}
+	}
+
+	sortedHosts := []string{}
+	for host := range hosts {
+		sortedHosts = append(sortedHosts, host)
+	}
+	sort.Strings(sortedHosts)
+	return sortedHosts, nil
+}
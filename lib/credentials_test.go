@@ -0,0 +1,199 @@
+package stew
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"sync"
+	"testing"
+)
+
+func TestParseNetrc(t *testing.T) {
+	netrc := `machine files.example.com
+	login alice
+	password secret
+
+macdef init
+	cd /pub
+
+default login anonymous password guest
+`
+	want := map[string]Credential{
+		"files.example.com": {Username: "alice", Password: "secret"},
+		"":                  {Username: "anonymous", Password: "guest"},
+	}
+	if got := parseNetrc(netrc); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseNetrc() = %v, want %v", got, want)
+	}
+}
+
+func TestCredential_Header(t *testing.T) {
+	tests := []struct {
+		name       string
+		credential Credential
+		want       string
+	}{
+		{
+			name:       "test1",
+			credential: Credential{Scheme: "token", Token: "abc"},
+			want:       "token abc",
+		},
+		{
+			name:       "test2",
+			credential: Credential{Token: "abc"},
+			want:       "Bearer abc",
+		},
+		{
+			name:       "test3",
+			credential: Credential{Username: "alice", Password: "secret"},
+			want:       "Basic YWxpY2U6c2VjcmV0",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.credential.Header(); got != tt.want {
+				t.Errorf("Credential.Header() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindCredential(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Setenv("GITHUB_TOKEN", "github-token")
+	t.Setenv("PATH", "")
+
+	netrcPath := filepath.Join(tempDir, ".netrc")
+	os.WriteFile(netrcPath, []byte("machine files.example.com login alice password secret\nmachine artifactory.example.com login bob password secret\ndefault login carol password secret\n"), 0600)
+	t.Setenv("NETRC", netrcPath)
+
+	stewCredentialsFilePath := filepath.Join(tempDir, "credentials.json")
+	os.WriteFile(stewCredentialsFilePath, []byte(`{"hosts":{"artifactory.example.com":{"token":"artifactory-token"}}}`), 0600)
+	SetCredentialSources(stewCredentialsFilePath, "", []string{"mirror.example.com"})
+	defer SetCredentialSources("", "", nil)
+
+	tests := []struct {
+		name string
+		host string
+		want *Credential
+	}{
+		{
+			name: "test1",
+			host: "api.github.com",
+			want: &Credential{Host: "api.github.com", Source: "GITHUB_TOKEN environment variable", Scheme: "token", Token: "github-token"},
+		},
+		{
+			name: "test2",
+			host: "artifactory.example.com",
+			want: &Credential{Host: "artifactory.example.com", Source: stewCredentialsFilePath, Token: "artifactory-token"},
+		},
+		{
+			name: "test3",
+			host: "files.example.com",
+			want: &Credential{Host: "files.example.com", Source: netrcPath, Username: "alice", Password: "secret"},
+		},
+		{
+			name: "test4",
+			host: "example.com",
+			want: nil,
+		},
+		{
+			name: "test5",
+			host: "mirror.example.com",
+			want: &Credential{Host: "mirror.example.com", Source: netrcPath, Username: "carol", Password: "secret"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FindCredential(tt.host)
+			if err != nil {
+				t.Errorf("FindCredential() error = %v", err)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FindCredential() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindCredential_Helper(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the test helper is a shell script")
+	}
+	tempDir := t.TempDir()
+	t.Setenv("NETRC", filepath.Join(tempDir, ".netrc"))
+	// The helper path and its argument contain spaces, so they have to be quoted
+	helperPath := filepath.Join(tempDir, "credential helper")
+	os.WriteFile(helperPath, []byte("#!/bin/sh\nif [ \"$2\" = \"files.example.com\" ]; then echo '{\"username\":\"alice\",\"password\":\"'\"$1\"'\"}'; fi\n"), 0755)
+	if err := SetCredentialSources("", "'"+helperPath+"' 'top secret'", nil); err != nil {
+		t.Fatalf("SetCredentialSources() error = %v", err)
+	}
+	defer SetCredentialSources("", "", nil)
+
+	got, err := FindCredential("files.example.com")
+	if err != nil {
+		t.Fatalf("FindCredential() error = %v", err)
+	}
+	want := &Credential{Host: "files.example.com", Source: "credential helper", Username: "alice", Password: "top secret"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FindCredential() = %v, want %v", got, want)
+	}
+
+	got, err = FindCredential("example.com")
+	if err != nil || got != nil {
+		t.Errorf("FindCredential() = %v, %v, want nil", got, err)
+	}
+}
+
+func TestSetCredentialSources_InvalidHelper(t *testing.T) {
+	defer SetCredentialSources("", "", nil)
+	err := SetCredentialSources("", "helper 'unterminated", nil)
+	if _, ok := err.(CredentialHelperError); !ok {
+		t.Errorf("SetCredentialSources() error = %v, want a CredentialHelperError", err)
+	}
+}
+
+func TestFindCredential_Concurrent(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the test helper is a shell script")
+	}
+	tempDir := t.TempDir()
+	t.Setenv("NETRC", filepath.Join(tempDir, ".netrc"))
+	callsPath := filepath.Join(tempDir, "calls")
+	helperPath := filepath.Join(tempDir, "helper")
+	os.WriteFile(helperPath, []byte("#!/bin/sh\necho \"$1\" >> '"+callsPath+"'\necho '{\"token\":\"secret\"}'\n"), 0755)
+	SetCredentialSources("", helperPath, nil)
+	defer SetCredentialSources("", "", nil)
+
+	var wg sync.WaitGroup
+	for index := 0; index < 8; index++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if got, err := FindCredential("files.example.com"); err != nil || got == nil || got.Token != "secret" {
+				t.Errorf("FindCredential() = %v, %v, want the secret token", got, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if calls, _ := os.ReadFile(callsPath); string(calls) != "files.example.com\n" {
+		t.Errorf("FindCredential() ran the credential helper for %q, want once", string(calls))
+	}
+}
+
+func TestReadCredentialsFile_Insecure(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file permissions are not checked on Windows")
+	}
+	stewCredentialsFilePath := filepath.Join(t.TempDir(), "credentials.json")
+	os.WriteFile(stewCredentialsFilePath, []byte(`{"hosts":{}}`), 0644)
+
+	_, err := ReadCredentialsFile(stewCredentialsFilePath)
+	want := InsecureCredentialsFileError{Path: stewCredentialsFilePath, Mode: 0644}
+	if err != want {
+		t.Errorf("ReadCredentialsFile() error = %v, want %v", err, want)
+	}
+}
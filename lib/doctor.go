@@ -0,0 +1,196 @@
+package stew
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// The kinds of problems that stew doctor finds
+const (
+	MissingBinaryProblem   = "missing binary"
+	DanglingLinkProblem    = "dangling link"
+	ModifiedBinaryProblem  = "modified binary"
+	OrphanedLinkProblem    = "orphaned link"
+	OrphanedPackageProblem = "orphaned package"
+	OrphanedAssetProblem   = "orphaned asset"
+)
+
+// DoctorProblem is a problem with an installed binary, a link in the stewBinPath, or a file in the stewPkgPath
+type DoctorProblem struct {
+	Kind   string `json:"kind"`
+	Binary string `json:"binary,omitempty"`
+	Path   string `json:"path"`
+	// Target is the missing file that a dangling link points to
+	Target string `json:"target,omitempty"`
+	// Fixable is set if Fix can remove the path
+	Fixable bool `json:"fixable"`
+}
+
+// Diagnose checks that every binary in the lockfile is installed and unmodified, and finds the links, packages, and assets that no binary in the lockfile uses
+func Diagnose(lockFile LockFile, systemInfo SystemInfo) ([]DoctorProblem, error) {
+	problems := []DoctorProblem{}
+	installedBinaries := map[string]bool{}
+	usedStorePaths := map[string]bool{}
+
+	for _, pkg := range lockFile.Packages {
+		if IsDirectoryPackage(pkg) {
+			storePath := PackageStorePath(systemInfo.StewPkgPath, pkg.Binary, pkg.Tag)
+			usedStorePaths[storePath] = true
+			entrypointProblems, err := diagnoseEntrypoints(pkg, storePath, systemInfo.StewBinPath)
+			if err != nil {
+				return []DoctorProblem{}, err
+			}
+			problems = append(problems, entrypointProblems...)
+			for _, name := range EntrypointNames(pkg) {
+				installedBinaries[name] = true
+			}
+			continue
+		}
+
+		installedBinaries[pkg.Binary] = true
+		binaryPath := filepath.Join(systemInfo.StewBinPath, pkg.Binary)
+		linkTarget, err := readBinaryLink(binaryPath)
+		if err != nil {
+			return []DoctorProblem{}, err
+		}
+
+		installedFilePath := binaryPath
+		if linkTarget != "" {
+			installedFilePath = linkTarget
+			storePath, err := LinkedStorePath(systemInfo.StewPkgPath, systemInfo.StewBinPath, pkg.Binary)
+			if err != nil {
+				return []DoctorProblem{}, err
+			}
+			if storePath != "" {
+				usedStorePaths[storePath] = true
+			}
+		}
+		fileExists, err := PathExists(installedFilePath)
+		if err != nil {
+			return []DoctorProblem{}, err
+		}
+		switch {
+		case !fileExists && linkTarget != "":
+			problems = append(problems, DoctorProblem{Kind: DanglingLinkProblem, Binary: pkg.Binary, Path: binaryPath, Target: linkTarget})
+			continue
+		case !fileExists:
+			problems = append(problems, DoctorProblem{Kind: MissingBinaryProblem, Binary: pkg.Binary, Path: binaryPath})
+			continue
+		}
+
+		if pkg.BinaryHash == "" {
+			continue
+		}
+		binaryHash, err := CalculateFileHash(installedFilePath)
+		if err != nil {
+			return []DoctorProblem{}, err
+		}
+		if binaryHash != pkg.BinaryHash {
+			problems = append(problems, DoctorProblem{Kind: ModifiedBinaryProblem, Binary: pkg.Binary, Path: installedFilePath})
+		}
+	}
+
+	binEntries, err := os.ReadDir(systemInfo.StewBinPath)
+	if err != nil && !os.IsNotExist(err) {
+		return []DoctorProblem{}, err
+	}
+	for _, binEntry := range binEntries {
+		if installedBinaries[binEntry.Name()] {
+			continue
+		}
+		storePath, err := LinkedStorePath(systemInfo.StewPkgPath, systemInfo.StewBinPath, binEntry.Name())
+		if err != nil {
+			return []DoctorProblem{}, err
+		}
+		// Only the links into the package store were created by stew
+		if storePath == "" {
+			continue
+		}
+		linkPath := filepath.Join(systemInfo.StewBinPath, binEntry.Name())
+		linkTarget, err := readBinaryLink(linkPath)
+		if err != nil {
+			return []DoctorProblem{}, err
+		}
+		targetExists, err := PathExists(linkTarget)
+		if err != nil {
+			return []DoctorProblem{}, err
+		}
+		if targetExists {
+			problems = append(problems, DoctorProblem{Kind: OrphanedLinkProblem, Binary: binEntry.Name(), Path: linkPath, Fixable: true})
+		} else {
+			problems = append(problems, DoctorProblem{Kind: DanglingLinkProblem, Binary: binEntry.Name(), Path: linkPath, Target: linkTarget, Fixable: true})
+		}
+	}
+
+	pkgEntries, err := os.ReadDir(systemInfo.StewPkgPath)
+	if err != nil && !os.IsNotExist(err) {
+		return []DoctorProblem{}, err
+	}
+	for _, pkgEntry := range pkgEntries {
+		pkgEntryPath := filepath.Join(systemInfo.StewPkgPath, pkgEntry.Name())
+		if !pkgEntry.IsDir() {
+			if !assetInLockFile(lockFile, pkgEntry.Name()) {
+				problems = append(problems, DoctorProblem{Kind: OrphanedAssetProblem, Path: pkgEntryPath, Fixable: true})
+			}
+			continue
+		}
+		versionEntries, err := os.ReadDir(pkgEntryPath)
+		if err != nil {
+			return []DoctorProblem{}, err
+		}
+		for _, versionEntry := range versionEntries {
+			storePath := filepath.Join(pkgEntryPath, versionEntry.Name())
+			if !usedStorePaths[storePath] {
+				problems = append(problems, DoctorProblem{Kind: OrphanedPackageProblem, Binary: pkgEntry.Name(), Path: storePath, Fixable: true})
+			}
+		}
+	}
+
+	return problems, nil
+}
+
+// diagnoseEntrypoints checks that every entrypoint of a directory package is linked and that its binary is unmodified
+func diagnoseEntrypoints(pkg PackageData, storePath, stewBinPath string) ([]DoctorProblem, error) {
+	problems := []DoctorProblem{}
+	for index, name := range EntrypointNames(pkg) {
+		entrypointPath := EntrypointPath(stewBinPath, name, pkg.Wrappers)
+		entrypointTarget := filepath.Join(storePath, filepath.FromSlash(pkg.Entrypoints[index]))
+		if _, err := os.Lstat(entrypointPath); os.IsNotExist(err) {
+			problems = append(problems, DoctorProblem{Kind: MissingBinaryProblem, Binary: name, Path: entrypointPath})
+			continue
+		} else if err != nil {
+			return []DoctorProblem{}, err
+		}
+		targetExists, err := PathExists(entrypointTarget)
+		if err != nil {
+			return []DoctorProblem{}, err
+		}
+		if !targetExists {
+			problems = append(problems, DoctorProblem{Kind: DanglingLinkProblem, Binary: name, Path: entrypointPath, Target: entrypointTarget})
+			continue
+		}
+
+		if index != 0 || pkg.BinaryHash == "" {
+			continue
+		}
+		binaryHash, err := CalculateFileHash(entrypointTarget)
+		if err != nil {
+			return []DoctorProblem{}, err
+		}
+		if binaryHash != pkg.BinaryHash {
+			problems = append(problems, DoctorProblem{Kind: ModifiedBinaryProblem, Binary: name, Path: entrypointTarget})
+		}
+	}
+	return problems, nil
+}
+
+// Fix removes the path of a fixable problem
+func (p DoctorProblem) Fix() error {
+	if !p.Fixable {
+		return nil
+	}
+	if p.Kind == OrphanedPackageProblem {
+		return removeStorePath(p.Path)
+	}
+	return os.RemoveAll(p.Path)
+}
@@ -0,0 +1,92 @@
+package stew
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+func TestDiagnose(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks need extra privileges on windows")
+	}
+	systemInfo := newTestTransactionSystemInfo(t)
+
+	rgStorePath := newTestLinkedBinary(t, systemInfo, "rg", "v1.0.0", "v1")
+	fdStorePath := newTestLinkedBinary(t, systemInfo, "fd", "v1.0.0", "v1")
+	os.RemoveAll(fdStorePath)
+	batStorePath := newTestLinkedBinary(t, systemInfo, "bat", "v1.0.0", "v1")
+	oldRgStorePath := filepath.Join(systemInfo.StewPkgPath, "rg", "v0.9.0")
+	os.MkdirAll(oldRgStorePath, 0755)
+	os.WriteFile(filepath.Join(systemInfo.StewBinPath, "jq"), []byte("changed"), 0755)
+	os.WriteFile(filepath.Join(systemInfo.StewBinPath, "notstew"), []byte("notstew"), 0755)
+	os.WriteFile(filepath.Join(systemInfo.StewPkgPath, "jq-v1.0.0.tar.gz"), []byte("jq"), 0644)
+	os.WriteFile(filepath.Join(systemInfo.StewPkgPath, "old-v1.0.0.tar.gz"), []byte("old"), 0644)
+
+	lockFile := LockFile{Os: "linux", Arch: "amd64", Packages: []PackageData{
+		{Binary: "rg", Tag: "v1.0.0"},
+		{Binary: "fd", Tag: "v1.0.0"},
+		{Binary: "jq", Tag: "v1.0.0", Asset: "jq-v1.0.0.tar.gz", BinaryHash: "unknown"},
+		{Binary: "yq", Tag: "v1.0.0"},
+	}}
+
+	got, err := Diagnose(lockFile, systemInfo)
+	if err != nil {
+		t.Fatalf("Diagnose() error = %v", err)
+	}
+	want := []DoctorProblem{
+		{Kind: DanglingLinkProblem, Binary: "fd", Path: filepath.Join(systemInfo.StewBinPath, "fd"), Target: filepath.Join(fdStorePath, "bin", "fd")},
+		{Kind: ModifiedBinaryProblem, Binary: "jq", Path: filepath.Join(systemInfo.StewBinPath, "jq")},
+		{Kind: MissingBinaryProblem, Binary: "yq", Path: filepath.Join(systemInfo.StewBinPath, "yq")},
+		{Kind: OrphanedLinkProblem, Binary: "bat", Path: filepath.Join(systemInfo.StewBinPath, "bat"), Fixable: true},
+		{Kind: OrphanedPackageProblem, Binary: "bat", Path: batStorePath, Fixable: true},
+		{Kind: OrphanedAssetProblem, Path: filepath.Join(systemInfo.StewPkgPath, "old-v1.0.0.tar.gz"), Fixable: true},
+		{Kind: OrphanedPackageProblem, Binary: "rg", Path: oldRgStorePath, Fixable: true},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Diagnose() = %v, want %v", got, want)
+	}
+
+	for _, problem := range got {
+		if err := problem.Fix(); err != nil {
+			t.Fatalf("DoctorProblem.Fix() error = %v", err)
+		}
+	}
+	for _, path := range []string{filepath.Join(systemInfo.StewBinPath, "bat"), filepath.Join(systemInfo.StewPkgPath, "bat"), oldRgStorePath} {
+		if exists, _ := PathExists(path); exists {
+			t.Errorf("DoctorProblem.Fix() did not remove %v", path)
+		}
+	}
+	for _, path := range []string{rgStorePath, filepath.Join(systemInfo.StewBinPath, "jq"), filepath.Join(systemInfo.StewBinPath, "notstew")} {
+		if exists, _ := PathExists(path); !exists {
+			t.Errorf("DoctorProblem.Fix() removed %v", path)
+		}
+	}
+}
+
+func TestDiagnose_DirectoryPackage(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the entrypoints are always wrapper scripts on windows")
+	}
+	systemInfo := newTestTransactionSystemInfo(t)
+	pkg := PackageData{Binary: "node", Tag: "v20.0.0", Entrypoints: []string{"bin/node", "bin/npm", "bin/npx"}}
+	storePath, err := linkPackage(filepath.Join(newTestDirectoryPackage(t, systemInfo, "v20.0.0"), "node-v20.0.0-linux-x64"), systemInfo.StewPkgPath, systemInfo.StewBinPath, PackageData{Binary: "node", Tag: "v20.0.0", Entrypoints: []string{"bin/node", "bin/npm"}})
+	if err != nil {
+		t.Fatalf("linkPackage() error = %v", err)
+	}
+	os.Remove(filepath.Join(storePath, "bin", "npm"))
+
+	got, err := Diagnose(LockFile{Os: "linux", Arch: "amd64", Packages: []PackageData{pkg}}, systemInfo)
+	if err != nil {
+		t.Fatalf("Diagnose() error = %v", err)
+	}
+	want := []DoctorProblem{
+		{Kind: DanglingLinkProblem, Binary: "npm", Path: filepath.Join(systemInfo.StewBinPath, "npm"), Target: filepath.Join(storePath, "bin", "npm")},
+		{Kind: MissingBinaryProblem, Binary: "npx", Path: filepath.Join(systemInfo.StewBinPath, "npx")},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Diagnose() = %v, want %v", got, want)
+	}
+}
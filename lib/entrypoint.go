@@ -0,0 +1,203 @@
+package stew
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// IsDirectoryPackage checks if a package keeps its whole extracted directory in the package store and links its entrypoints in the stewBinPath
+func IsDirectoryPackage(pkg PackageData) bool {
+	return len(pkg.Entrypoints) > 0
+}
+
+// ValidateEntrypoints checks that every entrypoint is a path inside of the package
+func ValidateEntrypoints(entrypoints []string) error {
+	for _, entrypoint := range entrypoints {
+		cleanedEntrypoint := filepath.Clean(filepath.FromSlash(entrypoint))
+		if entrypoint == "" || filepath.IsAbs(cleanedEntrypoint) || cleanedEntrypoint == "." || cleanedEntrypoint == ".." || strings.HasPrefix(cleanedEntrypoint, ".."+string(filepath.Separator)) {
+			return InvalidEntrypointError{Entrypoint: entrypoint}
+		}
+	}
+	return nil
+}
+
+// EntrypointNames returns the names that the entrypoints of a package are linked with.
+// The first entrypoint is linked with the name of the binary, and the others keep their own names.
+func EntrypointNames(pkg PackageData) []string {
+	names := []string{}
+	for index, entrypoint := range pkg.Entrypoints {
+		if index == 0 && pkg.Binary != "" {
+			names = append(names, pkg.Binary)
+			continue
+		}
+		names = append(names, filepath.Base(filepath.FromSlash(entrypoint)))
+	}
+	return names
+}
+
+// EntrypointPath returns the path of a linked entrypoint in the stewBinPath. Wrapper scripts on Windows are cmd files.
+func EntrypointPath(stewBinPath, name string, wrappers bool) string {
+	if usesWrappers(wrappers) && runtime.GOOS == "windows" {
+		return filepath.Join(stewBinPath, strings.TrimSuffix(name, ".exe")+".cmd")
+	}
+	return filepath.Join(stewBinPath, name)
+}
+
+// EntrypointPaths returns the paths of all of the linked entrypoints of a directory package
+func EntrypointPaths(stewBinPath string, pkg PackageData) []string {
+	paths := []string{}
+	for _, name := range EntrypointNames(pkg) {
+		paths = append(paths, EntrypointPath(stewBinPath, name, pkg.Wrappers))
+	}
+	return paths
+}
+
+// Symlinks need extra privileges on Windows, so the entrypoints always get wrapper scripts there
+func usesWrappers(wrappers bool) bool {
+	return wrappers || runtime.GOOS == "windows"
+}
+
+// packageRoot returns the root of the extracted files of a directory package.
+// Most archives have a single top level directory like tool-v1.0.0-linux-amd64, which is used as the root so that entrypoints don't depend on the version.
+func packageRoot(extractionPath string) (string, error) {
+	entries, err := os.ReadDir(extractionPath)
+	if err != nil {
+		return "", err
+	}
+	if len(entries) == 1 && entries[0].IsDir() {
+		return filepath.Join(extractionPath, entries[0].Name()), nil
+	}
+	return extractionPath, nil
+}
+
+// findEntrypoints finds the entrypoints of a directory package in its extracted files.
+// It returns the package root and the path, name, and hash of the first entrypoint, which is the binary of the package.
+func findEntrypoints(extractionPath, desiredBinaryRename string, entrypoints []string, expectedBinaryHash string) (string, string, string, string, error) {
+	rootPath, err := packageRoot(extractionPath)
+	if err != nil {
+		return "", "", "", "", err
+	}
+	for _, entrypoint := range entrypoints {
+		entrypointInfo, err := os.Stat(filepath.Join(rootPath, filepath.FromSlash(entrypoint)))
+		if os.IsNotExist(err) || (err == nil && entrypointInfo.IsDir()) {
+			return "", "", "", "", EntrypointNotFoundError{Entrypoint: entrypoint}
+		}
+		if err != nil {
+			return "", "", "", "", err
+		}
+	}
+
+	binaryPath := filepath.Join(rootPath, filepath.FromSlash(entrypoints[0]))
+	binaryName := EntrypointNames(PackageData{Binary: desiredBinaryRename, Entrypoints: entrypoints})[0]
+	binaryHash, err := CalculateFileHash(binaryPath)
+	if err != nil {
+		return "", "", "", "", err
+	}
+	if expectedBinaryHash != "" && expectedBinaryHash != binaryHash {
+		return "", "", "", "", BinaryMismatchError{BinaryName: binaryName}
+	}
+	return rootPath, binaryPath, binaryName, binaryHash, nil
+}
+
+// linkPackage moves the root of a directory package into the package store and links each of its entrypoints in the stewBinPath.
+// It returns the package store directory.
+func linkPackage(rootPath, stewPkgPath, stewBinPath string, pkg PackageData) (string, error) {
+	storePath := PackageStorePath(stewPkgPath, pkg.Binary, pkg.Tag)
+	if err := moveToStore(rootPath, storePath); err != nil {
+		return "", err
+	}
+	return storePath, linkEntrypoints(storePath, stewBinPath, pkg)
+}
+
+// linkEntrypoints links each entrypoint of a directory package in the stewBinPath with a symlink or a wrapper script
+func linkEntrypoints(storePath, stewBinPath string, pkg PackageData) error {
+	for index, name := range EntrypointNames(pkg) {
+		entrypointTarget := filepath.Join(storePath, filepath.FromSlash(pkg.Entrypoints[index]))
+		if err := os.Chmod(entrypointTarget, 0755); err != nil {
+			return err
+		}
+		entrypointPath := EntrypointPath(stewBinPath, name, pkg.Wrappers)
+		var err error
+		if usesWrappers(pkg.Wrappers) {
+			err = writeFileAtomic(entrypointPath, strings.NewReader(wrapperScript(entrypointTarget)), 0755)
+		} else {
+			err = replaceLink(entrypointTarget, entrypointPath)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// wrapperScript runs the entrypoint from its own path, for tools that find their files relative to how they were called instead of following symlinks
+func wrapperScript(entrypointTarget string) string {
+	if runtime.GOOS == "windows" {
+		return "@echo off\r\n\"" + entrypointTarget + "\" %*\r\n"
+	}
+	return "#!/bin/sh\nexec '" + strings.ReplaceAll(entrypointTarget, "'", `'\''`) + "' \"$@\"\n"
+}
+
+// installedStorePath returns the package store directory of an installed binary, or an empty string if it isn't in the package store
+func installedStorePath(lockFile LockFile, stewPkgPath, stewBinPath, binary string) (string, error) {
+	if indexInLockFile, binaryFoundInLockFile := FindBinaryInLockFile(lockFile, binary); binaryFoundInLockFile && IsDirectoryPackage(lockFile.Packages[indexInLockFile]) {
+		return PackageStorePath(stewPkgPath, binary, lockFile.Packages[indexInLockFile].Tag), nil
+	}
+	return LinkedStorePath(stewPkgPath, stewBinPath, binary)
+}
+
+// staleEntrypointPaths returns the linked entrypoints of the previous install of a package that the new install doesn't replace
+func staleEntrypointPaths(stewBinPath string, previousPkg, pkg PackageData) []string {
+	currentPaths := map[string]bool{filepath.Join(stewBinPath, pkg.Binary): true}
+	for _, entrypointPath := range EntrypointPaths(stewBinPath, pkg) {
+		currentPaths[entrypointPath] = true
+	}
+	stalePaths := []string{}
+	for _, entrypointPath := range EntrypointPaths(stewBinPath, previousPkg) {
+		if !currentPaths[entrypointPath] {
+			stalePaths = append(stalePaths, entrypointPath)
+		}
+	}
+	return stalePaths
+}
+
+// DeletePackage removes an installed package with its man pages and completions. A directory package's entrypoints and package store directory are all removed.
+func DeletePackage(systemInfo SystemInfo, pkg PackageData) error {
+	if err := removeExtraFiles(systemInfo.StewPath, pkg.ExtraFiles, []string{}); err != nil {
+		return err
+	}
+	if !IsDirectoryPackage(pkg) {
+		return DeleteAssetAndBinary(systemInfo.StewPkgPath, systemInfo.StewBinPath, pkg.Asset, pkg.Binary)
+	}
+	for _, entrypointPath := range EntrypointPaths(systemInfo.StewBinPath, pkg) {
+		if err := os.RemoveAll(entrypointPath); err != nil {
+			return err
+		}
+	}
+	return removeStorePath(PackageStorePath(systemInfo.StewPkgPath, pkg.Binary, pkg.Tag))
+}
+
+// RenamePackage renames an installed package. A directory package is moved to the store directory of its new name and its entrypoints are linked again.
+func RenamePackage(stewPkgPath, stewBinPath string, pkg PackageData, newBinary string) error {
+	if !IsDirectoryPackage(pkg) {
+		return RenameBinary(stewPkgPath, stewBinPath, pkg.Binary, newBinary)
+	}
+	storePath := PackageStorePath(stewPkgPath, pkg.Binary, pkg.Tag)
+	renamedPkg := pkg
+	renamedPkg.Binary = newBinary
+	newStorePath := PackageStorePath(stewPkgPath, newBinary, pkg.Tag)
+	if err := moveToStore(storePath, newStorePath); err != nil {
+		return err
+	}
+	if err := linkEntrypoints(newStorePath, stewBinPath, renamedPkg); err != nil {
+		return err
+	}
+	for _, entrypointPath := range staleEntrypointPaths(stewBinPath, pkg, renamedPkg) {
+		if err := os.RemoveAll(entrypointPath); err != nil {
+			return err
+		}
+	}
+	return removeStorePath(storePath)
+}
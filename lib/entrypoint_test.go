@@ -0,0 +1,225 @@
+package stew
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func newTestDirectoryPackage(t *testing.T, systemInfo SystemInfo, version string) string {
+	extractionPath := filepath.Join(systemInfo.StewTmpPath, "extracted")
+	rootPath := filepath.Join(extractionPath, "node-"+version+"-linux-x64")
+	os.MkdirAll(filepath.Join(rootPath, "bin"), 0755)
+	os.MkdirAll(filepath.Join(rootPath, "lib"), 0755)
+	os.WriteFile(filepath.Join(rootPath, "bin", "node"), []byte("node "+version), 0644)
+	os.WriteFile(filepath.Join(rootPath, "bin", "npm"), []byte("npm "+version), 0644)
+	os.WriteFile(filepath.Join(rootPath, "lib", "npm-cli.js"), []byte("npm-cli"), 0644)
+	return extractionPath
+}
+
+func TestValidateEntrypoints(t *testing.T) {
+	tests := []struct {
+		name        string
+		entrypoints []string
+		wantErr     bool
+	}{
+		{
+			name:        "test1",
+			entrypoints: []string{"bin/node", "bin/npm"},
+			wantErr:     false,
+		},
+		{
+			name:        "test2",
+			entrypoints: []string{"bin/node", "../bin/npm"},
+			wantErr:     true,
+		},
+		{
+			name:        "test3",
+			entrypoints: []string{"/usr/bin/node"},
+			wantErr:     true,
+		},
+		{
+			name:        "test4",
+			entrypoints: []string{""},
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateEntrypoints(tt.entrypoints); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateEntrypoints() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestEntrypointNames(t *testing.T) {
+	tests := []struct {
+		name string
+		pkg  PackageData
+		want []string
+	}{
+		{
+			name: "test1",
+			pkg:  PackageData{Entrypoints: []string{"bin/node", "bin/npm"}},
+			want: []string{"node", "npm"},
+		},
+		{
+			name: "test2",
+			pkg:  PackageData{Binary: "node20", Entrypoints: []string{"bin/node", "bin/npm"}},
+			want: []string{"node20", "npm"},
+		},
+		{
+			name: "test3",
+			pkg:  PackageData{Binary: "rg"},
+			want: []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := EntrypointNames(tt.pkg); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("EntrypointNames() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindEntrypoints(t *testing.T) {
+	systemInfo := newTestTransactionSystemInfo(t)
+	extractionPath := newTestDirectoryPackage(t, systemInfo, "v20.0.0")
+	wantRootPath := filepath.Join(extractionPath, "node-v20.0.0-linux-x64")
+	wantBinaryHash, _ := CalculateFileHash(filepath.Join(wantRootPath, "bin", "node"))
+
+	rootPath, binaryPath, binaryName, binaryHash, err := findEntrypoints(extractionPath, "", []string{"bin/node", "bin/npm"}, "")
+	if err != nil {
+		t.Fatalf("findEntrypoints() error = %v", err)
+	}
+	if rootPath != wantRootPath || binaryPath != filepath.Join(wantRootPath, "bin", "node") || binaryName != "node" || binaryHash != wantBinaryHash {
+		t.Errorf("findEntrypoints() = %v, %v, %v, %v", rootPath, binaryPath, binaryName, binaryHash)
+	}
+
+	if _, _, _, _, err = findEntrypoints(extractionPath, "", []string{"bin/node", "bin/npx"}, ""); !reflect.DeepEqual(err, EntrypointNotFoundError{Entrypoint: "bin/npx"}) {
+		t.Errorf("findEntrypoints() error = %v, want %v", err, EntrypointNotFoundError{Entrypoint: "bin/npx"})
+	}
+	if _, _, _, _, err = findEntrypoints(extractionPath, "", []string{"lib"}, ""); !reflect.DeepEqual(err, EntrypointNotFoundError{Entrypoint: "lib"}) {
+		t.Errorf("findEntrypoints() error = %v, want %v", err, EntrypointNotFoundError{Entrypoint: "lib"})
+	}
+	if _, _, _, _, err = findEntrypoints(extractionPath, "node20", []string{"bin/node"}, "wronghash"); !reflect.DeepEqual(err, BinaryMismatchError{BinaryName: "node20"}) {
+		t.Errorf("findEntrypoints() error = %v, want %v", err, BinaryMismatchError{BinaryName: "node20"})
+	}
+}
+
+func TestLinkPackage(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the entrypoints are always wrapper scripts on windows")
+	}
+	tests := []struct {
+		name     string
+		wrappers bool
+	}{
+		{
+			name:     "test1",
+			wrappers: false,
+		},
+		{
+			name:     "test2",
+			wrappers: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			systemInfo := newTestTransactionSystemInfo(t)
+			extractionPath := newTestDirectoryPackage(t, systemInfo, "v20.0.0")
+			pkg := PackageData{Binary: "node", Tag: "v20.0.0", Entrypoints: []string{"bin/node", "bin/npm"}, Wrappers: tt.wrappers}
+
+			storePath, err := linkPackage(filepath.Join(extractionPath, "node-v20.0.0-linux-x64"), systemInfo.StewPkgPath, systemInfo.StewBinPath, pkg)
+			if err != nil {
+				t.Fatalf("linkPackage() error = %v", err)
+			}
+			if want := filepath.Join(systemInfo.StewPkgPath, "node", "v20.0.0"); storePath != want {
+				t.Errorf("linkPackage() store path = %v, want %v", storePath, want)
+			}
+			if exists, _ := PathExists(filepath.Join(storePath, "lib", "npm-cli.js")); !exists {
+				t.Errorf("linkPackage() did not keep the whole package")
+			}
+			for _, entrypoint := range []string{"node", "npm"} {
+				entrypointPath := filepath.Join(systemInfo.StewBinPath, entrypoint)
+				entrypointTarget := filepath.Join(storePath, "bin", entrypoint)
+				linkTarget, _ := readBinaryLink(entrypointPath)
+				contents, _ := os.ReadFile(entrypointPath)
+				if tt.wrappers && !strings.Contains(string(contents), entrypointTarget) {
+					t.Errorf("linkPackage() wrapper %v = %v, want it to run %v", entrypoint, string(contents), entrypointTarget)
+				}
+				if !tt.wrappers && linkTarget != entrypointTarget {
+					t.Errorf("linkPackage() link %v = %v, want %v", entrypoint, linkTarget, entrypointTarget)
+				}
+			}
+
+			renamedPkg := pkg
+			renamedPkg.Binary = "node20"
+			if err = RenamePackage(systemInfo.StewPkgPath, systemInfo.StewBinPath, pkg, "node20"); err != nil {
+				t.Fatalf("RenamePackage() error = %v", err)
+			}
+			for _, path := range []string{filepath.Join(systemInfo.StewBinPath, "node"), filepath.Join(systemInfo.StewPkgPath, "node")} {
+				if exists, _ := PathExists(path); exists {
+					t.Errorf("RenamePackage() did not remove %v", path)
+				}
+			}
+			for _, entrypoint := range []string{"node20", "npm"} {
+				if exists, _ := PathExists(filepath.Join(systemInfo.StewBinPath, entrypoint)); !exists {
+					t.Errorf("RenamePackage() did not link the %v entrypoint", entrypoint)
+				}
+			}
+
+			if err = DeletePackage(systemInfo, renamedPkg); err != nil {
+				t.Fatalf("DeletePackage() error = %v", err)
+			}
+			for _, dirPath := range []string{systemInfo.StewBinPath, systemInfo.StewPkgPath} {
+				if entries, _ := os.ReadDir(dirPath); len(entries) != 0 {
+					t.Errorf("DeletePackage() left %v in %v", entries, dirPath)
+				}
+			}
+		})
+	}
+}
+
+func TestInstallStagedPackages_DirectoryPackage(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the entrypoints are always wrapper scripts on windows")
+	}
+	systemInfo := newTestTransactionSystemInfo(t)
+	previousPkg := PackageData{Source: "github", Owner: "nodejs", Repo: "node", Tag: "v19.0.0", Binary: "node", Entrypoints: []string{"bin/node", "bin/npx"}}
+	previousStorePath, err := linkPackage(filepath.Join(newTestDirectoryPackage(t, systemInfo, "v19.0.0"), "node-v19.0.0-linux-x64"), systemInfo.StewPkgPath, systemInfo.StewBinPath, PackageData{Binary: "node", Tag: "v19.0.0", Entrypoints: []string{"bin/node", "bin/npm"}})
+	if err != nil {
+		t.Fatalf("linkPackage() error = %v", err)
+	}
+	os.Rename(filepath.Join(systemInfo.StewBinPath, "npm"), filepath.Join(systemInfo.StewBinPath, "npx"))
+	WriteLockFileJSON(LockFile{Os: "linux", Arch: "amd64", Packages: []PackageData{previousPkg}}, systemInfo.StewLockFilePath)
+
+	downloadedFilePath := filepath.Join(systemInfo.StewTmpPath, "node-v20.0.0-linux-x64.tar.gz")
+	os.WriteFile(downloadedFilePath, []byte("asset"), 0644)
+	stagedPkg := StagedPackage{
+		Package:        PackageData{Source: "github", Owner: "nodejs", Repo: "node", Tag: "v20.0.0", Asset: "node-v20.0.0-linux-x64.tar.gz", Binary: "node", Entrypoints: []string{"bin/node", "bin/npm"}},
+		AssetPath:      downloadedFilePath,
+		ExtractionPath: filepath.Join(newTestDirectoryPackage(t, systemInfo, "v20.0.0"), "node-v20.0.0-linux-x64"),
+	}
+	stagedPkg.BinaryPath = filepath.Join(stagedPkg.ExtractionPath, "bin", "node")
+
+	if err = InstallStagedPackages([]StagedPackage{stagedPkg}, systemInfo, "linux", "amd64"); err != nil {
+		t.Fatalf("InstallStagedPackages() error = %v", err)
+	}
+	for entrypoint, want := range map[string]string{"node": "node v20.0.0", "npm": "npm v20.0.0"} {
+		if got, _ := os.ReadFile(filepath.Join(systemInfo.StewBinPath, entrypoint)); string(got) != want {
+			t.Errorf("InstallStagedPackages() entrypoint %v = %v, want %v", entrypoint, string(got), want)
+		}
+	}
+	if exists, _ := PathExists(previousStorePath); exists {
+		t.Errorf("InstallStagedPackages() did not remove the previous store path")
+	}
+	if _, err = os.Lstat(filepath.Join(systemInfo.StewBinPath, "npx")); !os.IsNotExist(err) {
+		t.Errorf("InstallStagedPackages() did not remove the npx entrypoint")
+	}
+}
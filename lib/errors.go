@@ -2,6 +2,8 @@ package stew
 
 import (
 	"fmt"
+	"os"
+	"strings"
 
 	"github.com/marwanhawari/stew/constants"
 )
@@ -34,6 +36,17 @@ func (e AssetsNotFoundError) Error() string {
 	return fmt.Sprintf("%v Could not find any assets for release %v", constants.RedColor("Error:"), constants.RedColor(e.Tag))
 }
 
+// TagNotFoundError occurs if a GitHub repo doesn't have a release with a tag
+type TagNotFoundError struct {
+	Owner string
+	Repo  string
+	Tag   string
+}
+
+func (e TagNotFoundError) Error() string {
+	return fmt.Sprintf("%v Could not find the release %v of %v", constants.RedColor("Error:"), constants.RedColor(e.Tag), constants.RedColor("https://github.com/"+e.Owner+"/"+e.Repo))
+}
+
 // NoPackagesInLockfileError occurs if you try to remove packages from a lockfile without any packages
 type NoPackagesInLockfileError struct {
 }
@@ -177,5 +190,412 @@ type SelfInstallError struct {
 }
 
 func (e SelfInstallError) Error() string {
-	return fmt.Sprintf("%v Stew cannot self-install/self-upgrade. You should upgrade stew with the original install method, whether it was manually or through a package manager", constants.RedColor("Error:"))
+	return fmt.Sprintf("%v Stew cannot install or upgrade itself like other binaries. Use %v instead", constants.RedColor("Error:"), constants.GreenColor("stew self-update"))
+}
+
+// InvalidPlatformError occurs if a platform is not in the os/arch format
+type InvalidPlatformError struct {
+	Platform string
+}
+
+func (e InvalidPlatformError) Error() string {
+	return fmt.Sprintf("%v The platform %v must be in the os/arch format, e.g. linux/amd64", constants.RedColor("Error:"), constants.RedColor(e.Platform))
+}
+
+// PlatformNotInLockFileError occurs if a lockfile package has no asset for the current platform and cannot be detected again
+type PlatformNotInLockFileError struct {
+	Binary   string
+	Platform string
+}
+
+func (e PlatformNotInLockFileError) Error() string {
+	return fmt.Sprintf("%v The lockfile does not contain an asset of the %v binary for %v", constants.RedColor("Error:"), constants.RedColor(e.Binary), constants.RedColor(e.Platform))
+}
+
+// UnrecognizedStewfileOptionError occurs if a Stewfile line contains an unknown option
+type UnrecognizedStewfileOptionError struct {
+	Option string
+}
+
+func (e UnrecognizedStewfileOptionError) Error() string {
+	return fmt.Sprintf("%v The Stewfile option %v is not recognized", constants.RedColor("Error:"), constants.RedColor(e.Option))
+}
+
+// InvalidStewfileFormatError occurs if an unsupported Stewfile format is requested
+type InvalidStewfileFormatError struct {
+	Format string
+}
+
+func (e InvalidStewfileFormatError) Error() string {
+	return fmt.Sprintf("%v The Stewfile format %v is not supported. Use line, toml, or json", constants.RedColor("Error:"), constants.RedColor(e.Format))
+}
+
+// InvalidOutputFormatError occurs if an unsupported output format is requested
+type InvalidOutputFormatError struct {
+	Format string
+}
+
+func (e InvalidOutputFormatError) Error() string {
+	return fmt.Sprintf("%v The output format %v is not supported. Use text, json, or jsonl", constants.RedColor("Error:"), constants.RedColor(e.Format))
+}
+
+// NonInteractiveError occurs if stew needs to prompt for input in the non-interactive mode
+type NonInteractiveError struct {
+	Prompt     string
+	Resolution string
+}
+
+func (e NonInteractiveError) Error() string {
+	message := fmt.Sprintf("%v Cannot prompt in non-interactive mode: %v", constants.RedColor("Error:"), constants.RedColor(e.Prompt))
+	if e.Resolution != "" {
+		message += "\n" + e.Resolution
+	}
+	return message
+}
+
+// StewPathLockedError occurs if another stew process is changing the stew path
+type StewPathLockedError struct {
+	StewPath string
+	PID      string
+}
+
+func (e StewPathLockedError) Error() string {
+	process := "Another stew process"
+	if e.PID != "" {
+		process += " (pid " + e.PID + ")"
+	}
+	return fmt.Sprintf("%v %v is using the stew path %v. Wait for it to finish or use the --wait flag, e.g. stew --wait 1m", constants.RedColor("Error:"), process, constants.RedColor(e.StewPath))
+}
+
+// RollbackError occurs if the previous binaries could not be restored after a failed install
+type RollbackError struct {
+	Err         error
+	RollbackErr error
+}
+
+func (e RollbackError) Error() string {
+	return fmt.Sprintf("%v\n%v Could not restore the previous binaries and lockfile: %v", e.Err, constants.RedColor("Error:"), e.RollbackErr)
+}
+
+// LockFileInstallError occurs if any of the binaries in a lockfile could not be installed
+type LockFileInstallError struct {
+	Failed int
+	Total  int
+}
+
+func (e LockFileInstallError) Error() string {
+	return fmt.Sprintf("%v Could not install %v of the %v binaries in the lockfile. No binaries were changed", constants.RedColor("Error:"), constants.RedColor(e.Failed), constants.RedColor(e.Total))
+}
+
+// LockPackagesError occurs if any of the binaries could not be locked
+type LockPackagesError struct {
+	Failed int
+	Total  int
+}
+
+func (e LockPackagesError) Error() string {
+	return fmt.Sprintf("%v Could not lock %v of the %v binaries", constants.RedColor("Error:"), constants.RedColor(e.Failed), constants.RedColor(e.Total))
+}
+
+// MirrorAssetsError occurs if any of the assets could not be mirrored
+type MirrorAssetsError struct {
+	Failed int
+	Total  int
+}
+
+func (e MirrorAssetsError) Error() string {
+	return fmt.Sprintf("%v Could not mirror %v of the %v assets", constants.RedColor("Error:"), constants.RedColor(e.Failed), constants.RedColor(e.Total))
+}
+
+// AbortLockFileOverwriteError occurs if you choose not to replace an existing lockfile with stew lock
+type AbortLockFileOverwriteError struct {
+	LockFilePath string
+}
+
+func (e AbortLockFileOverwriteError) Error() string {
+	return fmt.Sprintf("%v Overwrite of %v aborted. Use %v to replace it", constants.RedColor("Error:"), constants.RedColor(e.LockFilePath), constants.GreenColor("stew lock --force"))
+}
+
+// UnsafeMirrorPathError occurs if the path of a mirrored asset would be outside of the mirror directory
+type UnsafeMirrorPathError struct {
+	Path string
+}
+
+func (e UnsafeMirrorPathError) Error() string {
+	return fmt.Sprintf("%v The mirrored asset %v is outside of the mirror directory", constants.RedColor("Error:"), constants.RedColor(e.Path))
+}
+
+// AssetHashMismatchError occurs if a downloaded asset does not match the hash in the lockfile
+type AssetHashMismatchError struct {
+	Asset        string
+	ExpectedHash string
+	Hash         string
+}
+
+func (e AssetHashMismatchError) Error() string {
+	return fmt.Sprintf("%v The asset %v has the hash %v but the lockfile expects %v", constants.RedColor("Error:"), constants.RedColor(e.Asset), constants.RedColor(e.Hash), constants.RedColor(e.ExpectedHash))
+}
+
+// InvalidAgeError occurs if an age can't be parsed as a duration
+type InvalidAgeError struct {
+	Age string
+}
+
+func (e InvalidAgeError) Error() string {
+	return fmt.Sprintf("%v The age %v is not valid. Use a duration like %v or %v", constants.RedColor("Error:"), constants.RedColor(e.Age), constants.GreenColor("12h"), constants.GreenColor("30d"))
+}
+
+// OfflineInputError occurs if an offline install is not from a lockfile
+type OfflineInputError struct{}
+
+func (e OfflineInputError) Error() string {
+	return fmt.Sprintf("%v The --offline and --mirror flags can only be used to install from a %v", constants.RedColor("Error:"), constants.RedColor("Stewfile.lock.json"))
+}
+
+// AssetNotAvailableOfflineError occurs if an offline install can't find an asset in the mirror or the download cache
+type AssetNotAvailableOfflineError struct {
+	Asset string
+}
+
+func (e AssetNotAvailableOfflineError) Error() string {
+	return fmt.Sprintf("%v The asset %v is not in the mirror or the download cache. Add it to a mirror with %v", constants.RedColor("Error:"), constants.RedColor(e.Asset), constants.GreenColor("stew mirror create"))
+}
+
+// NoURLInLockFileError occurs if a lockfile entry does not have a download URL
+type NoURLInLockFileError struct {
+	Binary string
+}
+
+func (e NoURLInLockFileError) Error() string {
+	return fmt.Sprintf("%v The lockfile does not have a download URL for the %v binary. Resolve it with %v first", constants.RedColor("Error:"), constants.RedColor(e.Binary), constants.GreenColor("stew lock"))
+}
+
+// InvalidURLRewriteRuleError occurs if a URL rewrite rule in the stew config is not valid
+type InvalidURLRewriteRuleError struct {
+	Index  int
+	Reason string
+}
+
+func (e InvalidURLRewriteRuleError) Error() string {
+	return fmt.Sprintf("%v The URL rewrite rule %v in the stew config is not valid: %v", constants.RedColor("Error:"), constants.RedColor(e.Index+1), e.Reason)
+}
+
+// InvalidHTTPConfigError occurs if an http setting in the stew config is not valid
+type InvalidHTTPConfigError struct {
+	Setting string
+	Reason  string
+}
+
+func (e InvalidHTTPConfigError) Error() string {
+	return fmt.Sprintf("%v The http.%v setting in the stew config is not valid: %v", constants.RedColor("Error:"), constants.RedColor(e.Setting), e.Reason)
+}
+
+// InsecureCredentialsFileError occurs if the stew credentials file can be read by other users
+type InsecureCredentialsFileError struct {
+	Path string
+	Mode os.FileMode
+}
+
+func (e InsecureCredentialsFileError) Error() string {
+	return fmt.Sprintf("%v The credentials file %v has the permissions %v. Make it only readable by you with %v", constants.RedColor("Error:"), constants.RedColor(e.Path), constants.RedColor(fmt.Sprintf("%04o", uint32(e.Mode))), constants.GreenColor("chmod 600 "+e.Path))
+}
+
+// CredentialHelperError occurs if the credential helper command fails or prints invalid output
+type CredentialHelperError struct {
+	Command string
+	Err     error
+}
+
+func (e CredentialHelperError) Error() string {
+	return fmt.Sprintf("%v The credential helper %v failed: %v", constants.RedColor("Error:"), constants.RedColor(e.Command), e.Err)
+}
+
+// ManagedByPackageManagerError occurs if stew self-update is run on a stew executable that was installed by a package manager
+type ManagedByPackageManagerError struct {
+	Path           string
+	Manager        string
+	UpgradeCommand string
+}
+
+func (e ManagedByPackageManagerError) Error() string {
+	return fmt.Sprintf("%v The stew executable at %v is managed by %v. Upgrade it with %v instead", constants.RedColor("Error:"), constants.RedColor(e.Path), e.Manager, constants.GreenColor(e.UpgradeCommand))
+}
+
+// ChecksumNotFoundError occurs if the checksum of a release asset can't be found
+type ChecksumNotFoundError struct {
+	Asset string
+	Tag   string
+}
+
+func (e ChecksumNotFoundError) Error() string {
+	return fmt.Sprintf("%v Could not find a checksum for the %v asset in the %v release", constants.RedColor("Error:"), constants.RedColor(e.Asset), constants.RedColor(e.Tag))
+}
+
+// ChecksumMismatchError occurs if a release asset doesn't match the checksum published with the release
+type ChecksumMismatchError struct {
+	Asset    string
+	Checksum string
+	Hash     string
+}
+
+func (e ChecksumMismatchError) Error() string {
+	return fmt.Sprintf("%v The %v asset has the sha256 hash %v but the release checksum is %v", constants.RedColor("Error:"), constants.RedColor(e.Asset), constants.RedColor(e.Hash), constants.RedColor(e.Checksum))
+}
+
+// InvalidSearchSortError occurs if the search results can't be sorted by the given order
+type InvalidSearchSortError struct {
+	Sort string
+}
+
+func (e InvalidSearchSortError) Error() string {
+	return fmt.Sprintf("%v The search sort order %v is not valid. Use %v, %v, or %v", constants.RedColor("Error:"), constants.RedColor(e.Sort), constants.GreenColor("relevance"), constants.GreenColor("stars"), constants.GreenColor("release"))
+}
+
+// InvalidSearchPageError occurs if a page of search results before the first page is requested
+type InvalidSearchPageError struct {
+	Page int
+}
+
+func (e InvalidSearchPageError) Error() string {
+	return fmt.Sprintf("%v The search page %v is not valid. Pages start at 1", constants.RedColor("Error:"), constants.RedColor(e.Page))
+}
+
+// ConflictingFlagsError occurs if CLI flags that can't be used together are used at the same time
+type ConflictingFlagsError struct {
+	Flags []string
+}
+
+func (e ConflictingFlagsError) Error() string {
+	return fmt.Sprintf("%v Cannot use the %v flags at the same time", constants.RedColor("Error:"), constants.RedColor(strings.Join(e.Flags, ", ")))
+}
+
+// UpgradeCanceledError occurs if you decline an upgrade after reading its changelog
+type UpgradeCanceledError struct {
+	Binary string
+}
+
+func (e UpgradeCanceledError) Error() string {
+	return fmt.Sprintf("%v The upgrade of %v was canceled", constants.RedColor("Error:"), constants.RedColor(e.Binary))
+}
+
+// InvalidURLTemplateError occurs if a URL template can't be rendered
+type InvalidURLTemplateError struct {
+	Template string
+	Err      error
+}
+
+func (e InvalidURLTemplateError) Error() string {
+	return fmt.Sprintf("%v The URL template %v is not valid: %v", constants.RedColor("Error:"), constants.RedColor(e.Template), e.Err)
+}
+
+// InvalidVersionSourceError occurs if a version source is missing a setting or can't be used
+type InvalidVersionSourceError struct {
+	Reason string
+}
+
+func (e InvalidVersionSourceError) Error() string {
+	return fmt.Sprintf("%v The version source is not valid: %v", constants.RedColor("Error:"), e.Reason)
+}
+
+// VersionNotFoundError occurs if the version source doesn't contain a version
+type VersionNotFoundError struct {
+	URL string
+}
+
+func (e VersionNotFoundError) Error() string {
+	return fmt.Sprintf("%v Could not find a version at %v", constants.RedColor("Error:"), constants.RedColor(e.URL))
+}
+
+// InvalidHookTimeoutError occurs if the timeout of the hooks of a package is not a valid duration
+type InvalidHookTimeoutError struct {
+	Timeout string
+}
+
+func (e InvalidHookTimeoutError) Error() string {
+	return fmt.Sprintf("%v The hook timeout %v is not valid. Use a duration like 30s", constants.RedColor("Error:"), constants.RedColor(e.Timeout))
+}
+
+// HookFailedError occurs if a hook of a package exits with an error
+type HookFailedError struct {
+	Binary string
+	Hook   string
+	Err    error
+}
+
+func (e HookFailedError) Error() string {
+	return fmt.Sprintf("%v The %v hook of the %v binary failed: %v", constants.RedColor("Error:"), constants.RedColor(e.Hook), constants.RedColor(e.Binary), e.Err)
+}
+
+// HookTimeoutError occurs if a hook of a package doesn't finish before its timeout
+type HookTimeoutError struct {
+	Binary  string
+	Hook    string
+	Timeout string
+}
+
+func (e HookTimeoutError) Error() string {
+	return fmt.Sprintf("%v The %v hook of the %v binary did not finish within %v", constants.RedColor("Error:"), constants.RedColor(e.Hook), constants.RedColor(e.Binary), constants.RedColor(e.Timeout))
+}
+
+// InvalidLinkModeError occurs if the linkMode in the stew config is not copy or symlink
+type InvalidLinkModeError struct {
+	LinkMode string
+}
+
+func (e InvalidLinkModeError) Error() string {
+	return fmt.Sprintf("%v The linkMode %v in the stew config is not valid. Use copy or symlink", constants.RedColor("Error:"), constants.RedColor(e.LinkMode))
+}
+
+// InvalidEntrypointError occurs if an entrypoint of a directory package is not a path inside of the package
+type InvalidEntrypointError struct {
+	Entrypoint string
+}
+
+func (e InvalidEntrypointError) Error() string {
+	return fmt.Sprintf("%v The entrypoint %v is not valid. Use a path relative to the root of the package like bin/tool", constants.RedColor("Error:"), constants.RedColor(e.Entrypoint))
+}
+
+// EntrypointNotFoundError occurs if an entrypoint of a directory package is not in the extracted asset
+type EntrypointNotFoundError struct {
+	Entrypoint string
+}
+
+func (e EntrypointNotFoundError) Error() string {
+	return fmt.Sprintf("%v Could not find the entrypoint %v in the extracted asset", constants.RedColor("Error:"), constants.RedColor(e.Entrypoint))
+}
+
+// InvalidSystemPackageError occurs if a .deb or .rpm asset can't be read
+type InvalidSystemPackageError struct {
+	Asset  string
+	Reason string
+}
+
+func (e InvalidSystemPackageError) Error() string {
+	return fmt.Sprintf("%v Could not read the package %v because %v", constants.RedColor("Error:"), constants.RedColor(e.Asset), e.Reason)
+}
+
+// UnsafeArchiveEntryError occurs if an entry of an asset would be extracted outside of the extraction directory
+type UnsafeArchiveEntryError struct {
+	Entry string
+}
+
+func (e UnsafeArchiveEntryError) Error() string {
+	return fmt.Sprintf("%v The entry %v would be extracted outside of the extraction directory", constants.RedColor("Error:"), constants.RedColor(e.Entry))
+}
+
+// CachePruneScopeError occurs if you prune the download cache without saying which assets to remove
+type CachePruneScopeError struct {
+}
+
+func (e CachePruneScopeError) Error() string {
+	return fmt.Sprintf("%v Use %v to remove the assets that haven't been used for a while, or %v to remove every cached asset", constants.RedColor("Error:"), constants.GreenColor("--older-than 30d"), constants.GreenColor("--all"))
+}
+
+// CacheLockedError occurs if another stew process keeps the download cache locked
+type CacheLockedError struct {
+	StewCachePath string
+}
+
+func (e CacheLockedError) Error() string {
+	return fmt.Sprintf("%v Another stew process is updating the download cache %v. Try again once it has finished", constants.RedColor("Error:"), constants.RedColor(e.StewCachePath))
 }
@@ -3,6 +3,7 @@ package stew
 import (
 	"errors"
 	"fmt"
+	"os"
 	"testing"
 
 	"github.com/marwanhawari/stew/constants"
@@ -507,3 +508,1344 @@ func TestBinaryMismatchError_Error(t *testing.T) {
 		})
 	}
 }
+
+func TestInvalidPlatformError_Error(t *testing.T) {
+	type fields struct {
+		Platform string
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		want   string
+	}{
+		{
+			name: "test1",
+			fields: fields{
+				Platform: "linux",
+			},
+			want: fmt.Sprintf("%v The platform %v must be in the os/arch format, e.g. linux/amd64", constants.RedColor("Error:"), constants.RedColor("linux")),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := InvalidPlatformError{
+				Platform: tt.fields.Platform,
+			}
+			if got := e.Error(); got != tt.want {
+				t.Errorf("InvalidPlatformError.Error() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlatformNotInLockFileError_Error(t *testing.T) {
+	type fields struct {
+		Binary   string
+		Platform string
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		want   string
+	}{
+		{
+			name: "test1",
+			fields: fields{
+				Binary:   "hyperfine",
+				Platform: "linux/amd64",
+			},
+			want: fmt.Sprintf("%v The lockfile does not contain an asset of the %v binary for %v", constants.RedColor("Error:"), constants.RedColor("hyperfine"), constants.RedColor("linux/amd64")),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := PlatformNotInLockFileError{
+				Binary:   tt.fields.Binary,
+				Platform: tt.fields.Platform,
+			}
+			if got := e.Error(); got != tt.want {
+				t.Errorf("PlatformNotInLockFileError.Error() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnrecognizedStewfileOptionError_Error(t *testing.T) {
+	type fields struct {
+		Option string
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		want   string
+	}{
+		{
+			name: "test1",
+			fields: fields{
+				Option: "arch=amd64",
+			},
+			want: fmt.Sprintf("%v The Stewfile option %v is not recognized", constants.RedColor("Error:"), constants.RedColor("arch=amd64")),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := UnrecognizedStewfileOptionError{
+				Option: tt.fields.Option,
+			}
+			if got := e.Error(); got != tt.want {
+				t.Errorf("UnrecognizedStewfileOptionError.Error() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInvalidStewfileFormatError_Error(t *testing.T) {
+	type fields struct {
+		Format string
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		want   string
+	}{
+		{
+			name: "test1",
+			fields: fields{
+				Format: "yaml",
+			},
+			want: fmt.Sprintf("%v The Stewfile format %v is not supported. Use line, toml, or json", constants.RedColor("Error:"), constants.RedColor("yaml")),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := InvalidStewfileFormatError{
+				Format: tt.fields.Format,
+			}
+			if got := e.Error(); got != tt.want {
+				t.Errorf("InvalidStewfileFormatError.Error() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNonInteractiveError_Error(t *testing.T) {
+	type fields struct {
+		Prompt     string
+		Resolution string
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		want   string
+	}{
+		{
+			name: "test1",
+			fields: fields{
+				Prompt:     "stew search",
+				Resolution: "Install the project directly with stew install owner/repo",
+			},
+			want: fmt.Sprintf("%v Cannot prompt in non-interactive mode: %v\nInstall the project directly with stew install owner/repo", constants.RedColor("Error:"), constants.RedColor("stew search")),
+		},
+		{
+			name: "test2",
+			fields: fields{
+				Prompt: "Choose a release tag:",
+			},
+			want: fmt.Sprintf("%v Cannot prompt in non-interactive mode: %v", constants.RedColor("Error:"), constants.RedColor("Choose a release tag:")),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := NonInteractiveError{
+				Prompt:     tt.fields.Prompt,
+				Resolution: tt.fields.Resolution,
+			}
+			if got := e.Error(); got != tt.want {
+				t.Errorf("NonInteractiveError.Error() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInvalidOutputFormatError_Error(t *testing.T) {
+	type fields struct {
+		Format string
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		want   string
+	}{
+		{
+			name: "test1",
+			fields: fields{
+				Format: "yaml",
+			},
+			want: fmt.Sprintf("%v The output format %v is not supported. Use text, json, or jsonl", constants.RedColor("Error:"), constants.RedColor("yaml")),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := InvalidOutputFormatError{
+				Format: tt.fields.Format,
+			}
+			if got := e.Error(); got != tt.want {
+				t.Errorf("InvalidOutputFormatError.Error() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStewPathLockedError_Error(t *testing.T) {
+	type fields struct {
+		StewPath string
+		PID      string
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		want   string
+	}{
+		{
+			name: "test1",
+			fields: fields{
+				StewPath: "/home/user/.local/share/stew",
+				PID:      "1234",
+			},
+			want: fmt.Sprintf("%v Another stew process (pid 1234) is using the stew path %v. Wait for it to finish or use the --wait flag, e.g. stew --wait 1m", constants.RedColor("Error:"), constants.RedColor("/home/user/.local/share/stew")),
+		},
+		{
+			name: "test2",
+			fields: fields{
+				StewPath: "/home/user/.local/share/stew",
+			},
+			want: fmt.Sprintf("%v Another stew process is using the stew path %v. Wait for it to finish or use the --wait flag, e.g. stew --wait 1m", constants.RedColor("Error:"), constants.RedColor("/home/user/.local/share/stew")),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := StewPathLockedError{
+				StewPath: tt.fields.StewPath,
+				PID:      tt.fields.PID,
+			}
+			if got := e.Error(); got != tt.want {
+				t.Errorf("StewPathLockedError.Error() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRollbackError_Error(t *testing.T) {
+	type fields struct {
+		Err         error
+		RollbackErr error
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		want   string
+	}{
+		{
+			name: "test1",
+			fields: fields{
+				Err:         NonZeroStatusCodeDownloadError{StatusCode: 404},
+				RollbackErr: errors.New("permission denied"),
+			},
+			want: fmt.Sprintf("%v\n%v Could not restore the previous binaries and lockfile: %v", NonZeroStatusCodeDownloadError{StatusCode: 404}, constants.RedColor("Error:"), errors.New("permission denied")),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := RollbackError{
+				Err:         tt.fields.Err,
+				RollbackErr: tt.fields.RollbackErr,
+			}
+			if got := e.Error(); got != tt.want {
+				t.Errorf("RollbackError.Error() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLockFileInstallError_Error(t *testing.T) {
+	type fields struct {
+		Failed int
+		Total  int
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		want   string
+	}{
+		{
+			name: "test1",
+			fields: fields{
+				Failed: 1,
+				Total:  3,
+			},
+			want: fmt.Sprintf("%v Could not install %v of the %v binaries in the lockfile. No binaries were changed", constants.RedColor("Error:"), constants.RedColor(1), constants.RedColor(3)),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := LockFileInstallError{
+				Failed: tt.fields.Failed,
+				Total:  tt.fields.Total,
+			}
+			if got := e.Error(); got != tt.want {
+				t.Errorf("LockFileInstallError.Error() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAssetHashMismatchError_Error(t *testing.T) {
+	type fields struct {
+		Asset        string
+		ExpectedHash string
+		Hash         string
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		want   string
+	}{
+		{
+			name: "test1",
+			fields: fields{
+				Asset:        "ppath-v0.0.3-linux-amd64.tar.gz",
+				ExpectedHash: "abc",
+				Hash:         "def",
+			},
+			want: fmt.Sprintf("%v The asset %v has the hash %v but the lockfile expects %v", constants.RedColor("Error:"), constants.RedColor("ppath-v0.0.3-linux-amd64.tar.gz"), constants.RedColor("def"), constants.RedColor("abc")),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := AssetHashMismatchError{
+				Asset:        tt.fields.Asset,
+				ExpectedHash: tt.fields.ExpectedHash,
+				Hash:         tt.fields.Hash,
+			}
+			if got := e.Error(); got != tt.want {
+				t.Errorf("AssetHashMismatchError.Error() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInvalidAgeError_Error(t *testing.T) {
+	type fields struct {
+		Age string
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		want   string
+	}{
+		{
+			name: "test1",
+			fields: fields{
+				Age: "a week",
+			},
+			want: fmt.Sprintf("%v The age %v is not valid. Use a duration like %v or %v", constants.RedColor("Error:"), constants.RedColor("a week"), constants.GreenColor("12h"), constants.GreenColor("30d")),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := InvalidAgeError{
+				Age: tt.fields.Age,
+			}
+			if got := e.Error(); got != tt.want {
+				t.Errorf("InvalidAgeError.Error() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOfflineInputError_Error(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{
+			name: "test1",
+			want: fmt.Sprintf("%v The --offline and --mirror flags can only be used to install from a %v", constants.RedColor("Error:"), constants.RedColor("Stewfile.lock.json")),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := OfflineInputError{}
+			if got := e.Error(); got != tt.want {
+				t.Errorf("OfflineInputError.Error() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAssetNotAvailableOfflineError_Error(t *testing.T) {
+	type fields struct {
+		Asset string
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		want   string
+	}{
+		{
+			name: "test1",
+			fields: fields{
+				Asset: "ppath-v0.0.3-linux-amd64.tar.gz",
+			},
+			want: fmt.Sprintf("%v The asset %v is not in the mirror or the download cache. Add it to a mirror with %v", constants.RedColor("Error:"), constants.RedColor("ppath-v0.0.3-linux-amd64.tar.gz"), constants.GreenColor("stew mirror create")),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := AssetNotAvailableOfflineError{
+				Asset: tt.fields.Asset,
+			}
+			if got := e.Error(); got != tt.want {
+				t.Errorf("AssetNotAvailableOfflineError.Error() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNoURLInLockFileError_Error(t *testing.T) {
+	type fields struct {
+		Binary string
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		want   string
+	}{
+		{
+			name: "test1",
+			fields: fields{
+				Binary: "ppath",
+			},
+			want: fmt.Sprintf("%v The lockfile does not have a download URL for the %v binary. Resolve it with %v first", constants.RedColor("Error:"), constants.RedColor("ppath"), constants.GreenColor("stew lock")),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := NoURLInLockFileError{
+				Binary: tt.fields.Binary,
+			}
+			if got := e.Error(); got != tt.want {
+				t.Errorf("NoURLInLockFileError.Error() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInvalidURLRewriteRuleError_Error(t *testing.T) {
+	type fields struct {
+		Index  int
+		Reason string
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		want   string
+	}{
+		{
+			name: "test1",
+			fields: fields{
+				Index:  0,
+				Reason: "set either a prefix or a regex",
+			},
+			want: fmt.Sprintf("%v The URL rewrite rule %v in the stew config is not valid: set either a prefix or a regex", constants.RedColor("Error:"), constants.RedColor(1)),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := InvalidURLRewriteRuleError{
+				Index:  tt.fields.Index,
+				Reason: tt.fields.Reason,
+			}
+			if got := e.Error(); got != tt.want {
+				t.Errorf("InvalidURLRewriteRuleError.Error() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInvalidHTTPConfigError_Error(t *testing.T) {
+	type fields struct {
+		Setting string
+		Reason  string
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		want   string
+	}{
+		{
+			name: "test1",
+			fields: fields{
+				Setting: "connectTimeout",
+				Reason:  "use a duration like 30s",
+			},
+			want: fmt.Sprintf("%v The http.%v setting in the stew config is not valid: use a duration like 30s", constants.RedColor("Error:"), constants.RedColor("connectTimeout")),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := InvalidHTTPConfigError{
+				Setting: tt.fields.Setting,
+				Reason:  tt.fields.Reason,
+			}
+			if got := e.Error(); got != tt.want {
+				t.Errorf("InvalidHTTPConfigError.Error() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInsecureCredentialsFileError_Error(t *testing.T) {
+	type fields struct {
+		Path string
+		Mode os.FileMode
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		want   string
+	}{
+		{
+			name: "test1",
+			fields: fields{
+				Path: "/home/user/.config/stew/credentials.json",
+				Mode: 0644,
+			},
+			want: fmt.Sprintf("%v The credentials file %v has the permissions %v. Make it only readable by you with %v", constants.RedColor("Error:"), constants.RedColor("/home/user/.config/stew/credentials.json"), constants.RedColor("0644"), constants.GreenColor("chmod 600 /home/user/.config/stew/credentials.json")),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := InsecureCredentialsFileError{
+				Path: tt.fields.Path,
+				Mode: tt.fields.Mode,
+			}
+			if got := e.Error(); got != tt.want {
+				t.Errorf("InsecureCredentialsFileError.Error() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCredentialHelperError_Error(t *testing.T) {
+	type fields struct {
+		Command string
+		Err     error
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		want   string
+	}{
+		{
+			name: "test1",
+			fields: fields{
+				Command: "stew-credentials",
+				Err:     errors.New("exit status 1"),
+			},
+			want: fmt.Sprintf("%v The credential helper %v failed: exit status 1", constants.RedColor("Error:"), constants.RedColor("stew-credentials")),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := CredentialHelperError{
+				Command: tt.fields.Command,
+				Err:     tt.fields.Err,
+			}
+			if got := e.Error(); got != tt.want {
+				t.Errorf("CredentialHelperError.Error() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSelfInstallError_Error(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{
+			name: "test1",
+			want: fmt.Sprintf("%v Stew cannot install or upgrade itself like other binaries. Use %v instead", constants.RedColor("Error:"), constants.GreenColor("stew self-update")),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := SelfInstallError{}
+			if got := e.Error(); got != tt.want {
+				t.Errorf("SelfInstallError.Error() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestManagedByPackageManagerError_Error(t *testing.T) {
+	type fields struct {
+		Path           string
+		Manager        string
+		UpgradeCommand string
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		want   string
+	}{
+		{
+			name: "test1",
+			fields: fields{
+				Path:           "/opt/homebrew/bin/stew",
+				Manager:        "Homebrew",
+				UpgradeCommand: "brew upgrade stew",
+			},
+			want: fmt.Sprintf("%v The stew executable at %v is managed by Homebrew. Upgrade it with %v instead", constants.RedColor("Error:"), constants.RedColor("/opt/homebrew/bin/stew"), constants.GreenColor("brew upgrade stew")),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := ManagedByPackageManagerError{
+				Path:           tt.fields.Path,
+				Manager:        tt.fields.Manager,
+				UpgradeCommand: tt.fields.UpgradeCommand,
+			}
+			if got := e.Error(); got != tt.want {
+				t.Errorf("ManagedByPackageManagerError.Error() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChecksumNotFoundError_Error(t *testing.T) {
+	type fields struct {
+		Asset string
+		Tag   string
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		want   string
+	}{
+		{
+			name: "test1",
+			fields: fields{
+				Asset: "testAsset",
+				Tag:   "testTag",
+			},
+			want: fmt.Sprintf("%v Could not find a checksum for the %v asset in the %v release", constants.RedColor("Error:"), constants.RedColor("testAsset"), constants.RedColor("testTag")),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := ChecksumNotFoundError{
+				Asset: tt.fields.Asset,
+				Tag:   tt.fields.Tag,
+			}
+			if got := e.Error(); got != tt.want {
+				t.Errorf("ChecksumNotFoundError.Error() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChecksumMismatchError_Error(t *testing.T) {
+	type fields struct {
+		Asset    string
+		Checksum string
+		Hash     string
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		want   string
+	}{
+		{
+			name: "test1",
+			fields: fields{
+				Asset:    "testAsset",
+				Checksum: "testChecksum",
+				Hash:     "testHash",
+			},
+			want: fmt.Sprintf("%v The %v asset has the sha256 hash %v but the release checksum is %v", constants.RedColor("Error:"), constants.RedColor("testAsset"), constants.RedColor("testHash"), constants.RedColor("testChecksum")),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := ChecksumMismatchError{
+				Asset:    tt.fields.Asset,
+				Checksum: tt.fields.Checksum,
+				Hash:     tt.fields.Hash,
+			}
+			if got := e.Error(); got != tt.want {
+				t.Errorf("ChecksumMismatchError.Error() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInvalidSearchSortError_Error(t *testing.T) {
+	type fields struct {
+		Sort string
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		want   string
+	}{
+		{
+			name: "test1",
+			fields: fields{
+				Sort: "testSort",
+			},
+			want: fmt.Sprintf("%v The search sort order %v is not valid. Use %v, %v, or %v", constants.RedColor("Error:"), constants.RedColor("testSort"), constants.GreenColor("relevance"), constants.GreenColor("stars"), constants.GreenColor("release")),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := InvalidSearchSortError{
+				Sort: tt.fields.Sort,
+			}
+			if got := e.Error(); got != tt.want {
+				t.Errorf("InvalidSearchSortError.Error() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInvalidSearchPageError_Error(t *testing.T) {
+	type fields struct {
+		Page int
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		want   string
+	}{
+		{
+			name: "test1",
+			fields: fields{
+				Page: 0,
+			},
+			want: fmt.Sprintf("%v The search page %v is not valid. Pages start at 1", constants.RedColor("Error:"), constants.RedColor(0)),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := InvalidSearchPageError{
+				Page: tt.fields.Page,
+			}
+			if got := e.Error(); got != tt.want {
+				t.Errorf("InvalidSearchPageError.Error() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConflictingFlagsError_Error(t *testing.T) {
+	type fields struct {
+		Flags []string
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		want   string
+	}{
+		{
+			name: "test1",
+			fields: fields{
+				Flags: []string{"--install", "--browse"},
+			},
+			want: fmt.Sprintf("%v Cannot use the %v flags at the same time", constants.RedColor("Error:"), constants.RedColor("--install, --browse")),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := ConflictingFlagsError{
+				Flags: tt.fields.Flags,
+			}
+			if got := e.Error(); got != tt.want {
+				t.Errorf("ConflictingFlagsError.Error() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpgradeCanceledError_Error(t *testing.T) {
+	type fields struct {
+		Binary string
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		want   string
+	}{
+		{
+			name: "test1",
+			fields: fields{
+				Binary: "testBinary",
+			},
+			want: fmt.Sprintf("%v The upgrade of %v was canceled", constants.RedColor("Error:"), constants.RedColor("testBinary")),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := UpgradeCanceledError{
+				Binary: tt.fields.Binary,
+			}
+			if got := e.Error(); got != tt.want {
+				t.Errorf("UpgradeCanceledError.Error() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInvalidURLTemplateError_Error(t *testing.T) {
+	type fields struct {
+		Template string
+		Err      error
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		want   string
+	}{
+		{
+			name: "test1",
+			fields: fields{
+				Template: "testTemplate",
+				Err:      errors.New("testErr"),
+			},
+			want: fmt.Sprintf("%v The URL template %v is not valid: %v", constants.RedColor("Error:"), constants.RedColor("testTemplate"), "testErr"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := InvalidURLTemplateError{
+				Template: tt.fields.Template,
+				Err:      tt.fields.Err,
+			}
+			if got := e.Error(); got != tt.want {
+				t.Errorf("InvalidURLTemplateError.Error() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInvalidVersionSourceError_Error(t *testing.T) {
+	type fields struct {
+		Reason string
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		want   string
+	}{
+		{
+			name: "test1",
+			fields: fields{
+				Reason: "testReason",
+			},
+			want: fmt.Sprintf("%v The version source is not valid: %v", constants.RedColor("Error:"), "testReason"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := InvalidVersionSourceError{
+				Reason: tt.fields.Reason,
+			}
+			if got := e.Error(); got != tt.want {
+				t.Errorf("InvalidVersionSourceError.Error() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVersionNotFoundError_Error(t *testing.T) {
+	type fields struct {
+		URL string
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		want   string
+	}{
+		{
+			name: "test1",
+			fields: fields{
+				URL: "testURL",
+			},
+			want: fmt.Sprintf("%v Could not find a version at %v", constants.RedColor("Error:"), constants.RedColor("testURL")),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := VersionNotFoundError{
+				URL: tt.fields.URL,
+			}
+			if got := e.Error(); got != tt.want {
+				t.Errorf("VersionNotFoundError.Error() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInvalidHookTimeoutError_Error(t *testing.T) {
+	type fields struct {
+		Timeout string
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		want   string
+	}{
+		{
+			name: "test1",
+			fields: fields{
+				Timeout: "testTimeout",
+			},
+			want: fmt.Sprintf("%v The hook timeout %v is not valid. Use a duration like 30s", constants.RedColor("Error:"), constants.RedColor("testTimeout")),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := InvalidHookTimeoutError{
+				Timeout: tt.fields.Timeout,
+			}
+			if got := e.Error(); got != tt.want {
+				t.Errorf("InvalidHookTimeoutError.Error() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHookFailedError_Error(t *testing.T) {
+	type fields struct {
+		Binary string
+		Hook   string
+		Err    error
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		want   string
+	}{
+		{
+			name: "test1",
+			fields: fields{
+				Binary: "testBinary",
+				Hook:   "testHook",
+				Err:    errors.New("testErr"),
+			},
+			want: fmt.Sprintf("%v The %v hook of the %v binary failed: %v", constants.RedColor("Error:"), constants.RedColor("testHook"), constants.RedColor("testBinary"), "testErr"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := HookFailedError{
+				Binary: tt.fields.Binary,
+				Hook:   tt.fields.Hook,
+				Err:    tt.fields.Err,
+			}
+			if got := e.Error(); got != tt.want {
+				t.Errorf("HookFailedError.Error() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHookTimeoutError_Error(t *testing.T) {
+	type fields struct {
+		Binary  string
+		Hook    string
+		Timeout string
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		want   string
+	}{
+		{
+			name: "test1",
+			fields: fields{
+				Binary:  "testBinary",
+				Hook:    "testHook",
+				Timeout: "testTimeout",
+			},
+			want: fmt.Sprintf("%v The %v hook of the %v binary did not finish within %v", constants.RedColor("Error:"), constants.RedColor("testHook"), constants.RedColor("testBinary"), constants.RedColor("testTimeout")),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := HookTimeoutError{
+				Binary:  tt.fields.Binary,
+				Hook:    tt.fields.Hook,
+				Timeout: tt.fields.Timeout,
+			}
+			if got := e.Error(); got != tt.want {
+				t.Errorf("HookTimeoutError.Error() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInvalidLinkModeError_Error(t *testing.T) {
+	type fields struct {
+		LinkMode string
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		want   string
+	}{
+		{
+			name: "test1",
+			fields: fields{
+				LinkMode: "testLinkMode",
+			},
+			want: fmt.Sprintf("%v The linkMode %v in the stew config is not valid. Use copy or symlink", constants.RedColor("Error:"), constants.RedColor("testLinkMode")),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := InvalidLinkModeError{
+				LinkMode: tt.fields.LinkMode,
+			}
+			if got := e.Error(); got != tt.want {
+				t.Errorf("InvalidLinkModeError.Error() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInvalidEntrypointError_Error(t *testing.T) {
+	type fields struct {
+		Entrypoint string
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		want   string
+	}{
+		{
+			name: "test1",
+			fields: fields{
+				Entrypoint: "testEntrypoint",
+			},
+			want: fmt.Sprintf("%v The entrypoint %v is not valid. Use a path relative to the root of the package like bin/tool", constants.RedColor("Error:"), constants.RedColor("testEntrypoint")),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := InvalidEntrypointError{
+				Entrypoint: tt.fields.Entrypoint,
+			}
+			if got := e.Error(); got != tt.want {
+				t.Errorf("InvalidEntrypointError.Error() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEntrypointNotFoundError_Error(t *testing.T) {
+	type fields struct {
+		Entrypoint string
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		want   string
+	}{
+		{
+			name: "test1",
+			fields: fields{
+				Entrypoint: "testEntrypoint",
+			},
+			want: fmt.Sprintf("%v Could not find the entrypoint %v in the extracted asset", constants.RedColor("Error:"), constants.RedColor("testEntrypoint")),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := EntrypointNotFoundError{
+				Entrypoint: tt.fields.Entrypoint,
+			}
+			if got := e.Error(); got != tt.want {
+				t.Errorf("EntrypointNotFoundError.Error() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInvalidSystemPackageError_Error(t *testing.T) {
+	type fields struct {
+		Asset  string
+		Reason string
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		want   string
+	}{
+		{
+			name: "test1",
+			fields: fields{
+				Asset:  "testAsset",
+				Reason: "testReason",
+			},
+			want: fmt.Sprintf("%v Could not read the package %v because testReason", constants.RedColor("Error:"), constants.RedColor("testAsset")),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := InvalidSystemPackageError{
+				Asset:  tt.fields.Asset,
+				Reason: tt.fields.Reason,
+			}
+			if got := e.Error(); got != tt.want {
+				t.Errorf("InvalidSystemPackageError.Error() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnsafeArchiveEntryError_Error(t *testing.T) {
+	type fields struct {
+		Entry string
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		want   string
+	}{
+		{
+			name: "test1",
+			fields: fields{
+				Entry: "testEntry",
+			},
+			want: fmt.Sprintf("%v The entry %v would be extracted outside of the extraction directory", constants.RedColor("Error:"), constants.RedColor("testEntry")),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := UnsafeArchiveEntryError{
+				Entry: tt.fields.Entry,
+			}
+			if got := e.Error(); got != tt.want {
+				t.Errorf("UnsafeArchiveEntryError.Error() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTagNotFoundError_Error(t *testing.T) {
+	type fields struct {
+		Owner string
+		Repo  string
+		Tag   string
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		want   string
+	}{
+		{
+			name: "test1",
+			fields: fields{
+				Owner: "testOwner",
+				Repo:  "testRepo",
+				Tag:   "v9.9.9",
+			},
+			want: fmt.Sprintf("%v Could not find the release %v of %v", constants.RedColor("Error:"), constants.RedColor("v9.9.9"), constants.RedColor("https://github.com/testOwner/testRepo")),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := TagNotFoundError{
+				Owner: tt.fields.Owner,
+				Repo:  tt.fields.Repo,
+				Tag:   tt.fields.Tag,
+			}
+			if got := e.Error(); got != tt.want {
+				t.Errorf("TagNotFoundError.Error() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLockPackagesError_Error(t *testing.T) {
+	type fields struct {
+		Failed int
+		Total  int
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		want   string
+	}{
+		{
+			name: "test1",
+			fields: fields{
+				Failed: 1,
+				Total:  3,
+			},
+			want: fmt.Sprintf("%v Could not lock %v of the %v binaries", constants.RedColor("Error:"), constants.RedColor(1), constants.RedColor(3)),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := LockPackagesError{
+				Failed: tt.fields.Failed,
+				Total:  tt.fields.Total,
+			}
+			if got := e.Error(); got != tt.want {
+				t.Errorf("LockPackagesError.Error() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMirrorAssetsError_Error(t *testing.T) {
+	type fields struct {
+		Failed int
+		Total  int
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		want   string
+	}{
+		{
+			name: "test1",
+			fields: fields{
+				Failed: 1,
+				Total:  3,
+			},
+			want: fmt.Sprintf("%v Could not mirror %v of the %v assets", constants.RedColor("Error:"), constants.RedColor(1), constants.RedColor(3)),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := MirrorAssetsError{
+				Failed: tt.fields.Failed,
+				Total:  tt.fields.Total,
+			}
+			if got := e.Error(); got != tt.want {
+				t.Errorf("MirrorAssetsError.Error() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnsafeMirrorPathError_Error(t *testing.T) {
+	type fields struct {
+		Path string
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		want   string
+	}{
+		{
+			name: "test1",
+			fields: fields{
+				Path: "../outside",
+			},
+			want: fmt.Sprintf("%v The mirrored asset %v is outside of the mirror directory", constants.RedColor("Error:"), constants.RedColor("../outside")),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := UnsafeMirrorPathError{
+				Path: tt.fields.Path,
+			}
+			if got := e.Error(); got != tt.want {
+				t.Errorf("UnsafeMirrorPathError.Error() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAbortLockFileOverwriteError_Error(t *testing.T) {
+	type fields struct {
+		LockFilePath string
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		want   string
+	}{
+		{
+			name: "test1",
+			fields: fields{
+				LockFilePath: "Stewfile.lock.json",
+			},
+			want: fmt.Sprintf("%v Overwrite of %v aborted. Use %v to replace it", constants.RedColor("Error:"), constants.RedColor("Stewfile.lock.json"), constants.GreenColor("stew lock --force")),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := AbortLockFileOverwriteError{
+				LockFilePath: tt.fields.LockFilePath,
+			}
+			if got := e.Error(); got != tt.want {
+				t.Errorf("AbortLockFileOverwriteError.Error() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCachePruneScopeError_Error(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{
+			name: "test1",
+			want: fmt.Sprintf("%v Use %v to remove the assets that haven't been used for a while, or %v to remove every cached asset", constants.RedColor("Error:"), constants.GreenColor("--older-than 30d"), constants.GreenColor("--all")),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := CachePruneScopeError{}
+			if got := e.Error(); got != tt.want {
+				t.Errorf("CachePruneScopeError.Error() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCacheLockedError_Error(t *testing.T) {
+	type fields struct {
+		StewCachePath string
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		want   string
+	}{
+		{
+			name: "test1",
+			fields: fields{
+				StewCachePath: "/home/user/.cache/stew",
+			},
+			want: fmt.Sprintf("%v Another stew process is updating the download cache %v. Try again once it has finished", constants.RedColor("Error:"), constants.RedColor("/home/user/.cache/stew")),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := CacheLockedError{
+				StewCachePath: tt.fields.StewCachePath,
+			}
+			if got := e.Error(); got != tt.want {
+				t.Errorf("CacheLockedError.Error() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
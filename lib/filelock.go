@@ -0,0 +1,91 @@
+package stew
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var lockWaitTimeout time.Duration
+
+// SetLockWaitTimeout sets how long to wait for another stew process to release the stew path
+func SetLockWaitTimeout(timeout time.Duration) {
+	lockWaitTimeout = timeout
+}
+
+// LockStewPath takes an advisory lock on the stew path so that only one stew process can change it at a time.
+// It also creates a temporary directory that is only used by this process and returns the SystemInfo that points to it.
+// The returned function releases the lock and removes the temporary directory.
+func LockStewPath(systemInfo SystemInfo) (SystemInfo, func(), error) {
+	lockFilePath := filepath.Join(systemInfo.StewPath, ".lock")
+	lockFile, unlock, err := waitForFileLock(lockFilePath, lockWaitTimeout)
+	if err != nil {
+		return SystemInfo{}, nil, err
+	}
+	if lockFile == nil {
+		lockingPID, _ := os.ReadFile(lockFilePath)
+		return SystemInfo{}, nil, StewPathLockedError{StewPath: systemInfo.StewPath, PID: strings.TrimSpace(string(lockingPID))}
+	}
+
+	if err = lockFile.Truncate(0); err == nil {
+		_, err = lockFile.WriteAt([]byte(strconv.Itoa(os.Getpid())), 0)
+	}
+	if err != nil {
+		unlock()
+		return SystemInfo{}, nil, err
+	}
+
+	// Nothing else can use the stew tmp path while the lock is held, so anything left in it is stale
+	if err = os.RemoveAll(systemInfo.StewTmpPath); err != nil {
+		unlock()
+		return SystemInfo{}, nil, err
+	}
+	if err = os.MkdirAll(systemInfo.StewTmpPath, 0755); err != nil {
+		unlock()
+		return SystemInfo{}, nil, err
+	}
+	invocationTmpPath, err := os.MkdirTemp(systemInfo.StewTmpPath, "run-")
+	if err != nil {
+		unlock()
+		return SystemInfo{}, nil, err
+	}
+	systemInfo.StewTmpPath = invocationTmpPath
+
+	return systemInfo, func() {
+		os.RemoveAll(invocationTmpPath)
+		unlock()
+	}, nil
+}
+
+// waitForFileLock takes an advisory lock on the lockFilePath, waiting up to the timeout for another process to release it.
+// The returned file is nil if the lock is still held by another process once the timeout has passed.
+func waitForFileLock(lockFilePath string, timeout time.Duration) (*os.File, func(), error) {
+	lockFile, err := os.OpenFile(lockFilePath, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		locked, err := tryLockFile(lockFile)
+		if err != nil {
+			lockFile.Close()
+			return nil, nil, err
+		}
+		if locked {
+			break
+		}
+		if time.Now().After(deadline) {
+			lockFile.Close()
+			return nil, nil, nil
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	return lockFile, func() {
+		unlockFile(lockFile)
+		lockFile.Close()
+	}, nil
+}
@@ -0,0 +1,48 @@
+package stew
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+func TestLockStewPath(t *testing.T) {
+	tempDir := t.TempDir()
+	systemInfo := SystemInfo{
+		StewPath:    tempDir,
+		StewTmpPath: filepath.Join(tempDir, "tmp"),
+	}
+
+	staleFilePath := filepath.Join(systemInfo.StewTmpPath, "stale")
+	os.MkdirAll(systemInfo.StewTmpPath, 0755)
+	os.WriteFile(staleFilePath, []byte("stale"), 0644)
+
+	lockedSystemInfo, unlock, err := LockStewPath(systemInfo)
+	if err != nil {
+		t.Fatalf("LockStewPath() error = %v", err)
+	}
+	if filepath.Dir(lockedSystemInfo.StewTmpPath) != systemInfo.StewTmpPath {
+		t.Errorf("LockStewPath() StewTmpPath = %v, want a directory in %v", lockedSystemInfo.StewTmpPath, systemInfo.StewTmpPath)
+	}
+	if _, err := os.Stat(staleFilePath); !os.IsNotExist(err) {
+		t.Errorf("LockStewPath() did not remove the stale file %v", staleFilePath)
+	}
+
+	_, _, err = LockStewPath(systemInfo)
+	want := StewPathLockedError{StewPath: tempDir, PID: strconv.Itoa(os.Getpid())}
+	if err != want {
+		t.Errorf("LockStewPath() error = %v, want %v", err, want)
+	}
+
+	unlock()
+	if _, err := os.Stat(lockedSystemInfo.StewTmpPath); !os.IsNotExist(err) {
+		t.Errorf("unlock() did not remove %v", lockedSystemInfo.StewTmpPath)
+	}
+
+	_, unlock, err = LockStewPath(systemInfo)
+	if err != nil {
+		t.Fatalf("LockStewPath() after unlock error = %v", err)
+	}
+	unlock()
+}
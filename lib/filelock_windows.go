@@ -0,0 +1,27 @@
+//go:build windows
+
+package stew
+
+import (
+	"errors"
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// The lock covers a byte past the end of the file so that the PID in the file can still be read
+const lockFileOffset = 1 << 30
+
+func tryLockFile(file *os.File) (bool, error) {
+	overlapped := windows.Overlapped{Offset: lockFileOffset}
+	err := windows.LockFileEx(windows.Handle(file.Fd()), windows.LOCKFILE_EXCLUSIVE_LOCK|windows.LOCKFILE_FAIL_IMMEDIATELY, 0, 1, 0, &overlapped)
+	if errors.Is(err, windows.ERROR_LOCK_VIOLATION) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func unlockFile(file *os.File) error {
+	overlapped := windows.Overlapped{Offset: lockFileOffset}
+	return windows.UnlockFileEx(windows.Handle(file.Fd()), 0, 1, 0, &overlapped)
+}
@@ -1,10 +1,18 @@
 package stew
 
 import (
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
 	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/gookit/color"
 	"github.com/marwanhawari/stew/constants"
 )
 
@@ -20,17 +28,22 @@ type GithubAPIResponse []GithubRelease
 
 // GithubRelease contains information about a GitHub release, including the associated assets
 type GithubRelease struct {
-	TagName    string        `json:"tag_name"`
-	Assets     []GithubAsset `json:"assets"`
-	Prerelease bool          `json:"prerelease"`
+	TagName     string        `json:"tag_name"`
+	Assets      []GithubAsset `json:"assets"`
+	Prerelease  bool          `json:"prerelease"`
+	Draft       bool          `json:"draft"`
+	PublishedAt time.Time     `json:"published_at"`
+	Body        string        `json:"body"`
 }
 
 // GithubAsset contains information about a specific GitHub asset
 type GithubAsset struct {
-	Name        string `json:"name"`
-	DownloadURL string `json:"url"`
-	Size        int    `json:"size"`
-	ContentType string `json:"content_type"`
+	Name          string    `json:"name"`
+	DownloadURL   string    `json:"url"`
+	Size          int       `json:"size"`
+	ContentType   string    `json:"content_type"`
+	DownloadCount int       `json:"download_count"`
+	UpdatedAt     time.Time `json:"updated_at"`
 }
 
 func readGithubJSON(jsonString string) (GithubAPIResponse, error) {
@@ -58,7 +71,15 @@ func NewGithubProject(owner, repo string) (GithubProject, error) {
 	if owner == constants.StewOwner && repo == constants.StewRepo {
 		return GithubProject{}, SelfInstallError{}
 	}
+	return newGithubProject(owner, repo)
+}
+
+// NewStewGithubProject creates a GithubProject for stew itself, which is only used by stew self-update
+func NewStewGithubProject() (GithubProject, error) {
+	return newGithubProject(constants.StewOwner, constants.StewRepo)
+}
 
+func newGithubProject(owner, repo string) (GithubProject, error) {
 	ghJSON, err := getGithubJSON(owner, repo)
 	if err != nil {
 		return GithubProject{}, err
@@ -142,6 +163,23 @@ func filterReleaseAssets(assets []string) []string {
 
 // DetectAsset will automatically detect a release asset matching your systems OS/arch or prompt you to manually select an asset
 func DetectAsset(userOS string, userArch string, releaseAssets []string) (string, error) {
+	finalAsset, _, err := DetectPlatformAsset(userOS, userArch, releaseAssets)
+	if err != nil {
+		return "", err
+	}
+	if finalAsset != "" {
+		return finalAsset, nil
+	}
+
+	if nonInteractive {
+		return "", NonInteractiveError{Prompt: "Could not automatically detect the release asset matching your OS/Arch", Resolution: "Pin the asset with the asset= option in a Stewfile or install from a Stewfile.lock.json"}
+	}
+	return WarningPromptSelect("Could not automatically detect the release asset matching your OS/Arch. Please select it manually:", filterReleaseAssets(releaseAssets))
+}
+
+// DetectPlatformAsset detects the release asset matching an OS/arch without prompting.
+// It returns an empty asset if there isn't exactly one match, along with the assets that matched the OS/arch.
+func DetectPlatformAsset(userOS string, userArch string, releaseAssets []string) (string, []string, error) {
 	var detectedOSAssets []string
 	var reOS *regexp.Regexp
 	var err error
@@ -154,7 +192,7 @@ func DetectAsset(userOS string, userArch string, releaseAssets []string) (string
 		reOS, err = regexp.Compile(`(?i)` + userOS)
 	}
 	if err != nil {
-		return "", err
+		return "", []string{}, err
 	}
 
 	filteredReleaseAssets := filterReleaseAssets(releaseAssets)
@@ -177,7 +215,7 @@ func DetectAsset(userOS string, userArch string, releaseAssets []string) (string
 		reArch, err = regexp.Compile(`(?i)` + userArch)
 	}
 	if err != nil {
-		return "", err
+		return "", []string{}, err
 	}
 
 	for _, asset := range detectedOSAssets {
@@ -186,25 +224,33 @@ func DetectAsset(userOS string, userArch string, releaseAssets []string) (string
 		}
 	}
 
-	var finalAsset string
-	if len(detectedFinalAssets) != 1 {
-		if userOS == "darwin" && userArch == "arm64" {
-			finalAsset, err = darwinARMFallback(detectedOSAssets)
-			if err != nil {
-				return "", err
-			}
+	if len(detectedFinalAssets) == 1 {
+		return detectedFinalAssets[0], detectedFinalAssets, nil
+	}
+	// A .deb or .rpm package is only picked automatically if it is the only match
+	if archiveAssets := withoutSystemPackages(detectedFinalAssets); len(archiveAssets) == 1 {
+		return archiveAssets[0], archiveAssets, nil
+	}
+	if userOS == "darwin" && userArch == "arm64" {
+		finalAsset, err := darwinARMFallback(detectedOSAssets)
+		if err != nil {
+			return "", []string{}, err
 		}
-		if finalAsset == "" {
-			finalAsset, err = WarningPromptSelect("Could not automatically detect the release asset matching your OS/Arch. Please select it manually:", filteredReleaseAssets)
-			if err != nil {
-				return "", err
-			}
+		if finalAsset != "" {
+			return finalAsset, []string{finalAsset}, nil
 		}
-	} else {
-		finalAsset = detectedFinalAssets[0]
 	}
+	return "", detectedFinalAssets, nil
+}
 
-	return finalAsset, nil
+func withoutSystemPackages(assets []string) []string {
+	var filteredAssets []string
+	for _, asset := range assets {
+		if !isSystemPackage(asset) {
+			filteredAssets = append(filteredAssets, asset)
+		}
+	}
+	return filteredAssets
 }
 
 func darwinARMFallback(darwinAssets []string) (string, error) {
@@ -230,6 +276,7 @@ func darwinARMFallback(darwinAssets []string) (string, error) {
 // GithubSearch contains information about the GitHub search including the GitHub search results
 type GithubSearch struct {
 	SearchQuery string
+	Page        int
 	Count       int                  `json:"total_count"`
 	Items       []GithubSearchResult `json:"items"`
 }
@@ -240,13 +287,37 @@ type GithubSearchResult struct {
 	Stars       int    `json:"stargazers_count"`
 	Language    string `json:"language"`
 	Description string `json:"description"`
+	// Platform is only set when the search results are checked with CheckSearchResults
+	Platform *SearchResultPlatform `json:"platform,omitempty"`
+}
+
+// SearchResultPlatform describes whether the latest release of a search result has an asset for the current platform
+type SearchResultPlatform struct {
+	Tag         string     `json:"tag,omitempty"`
+	PublishedAt *time.Time `json:"publishedAt,omitempty"`
+	Asset       string     `json:"asset,omitempty"`
+	Installable bool       `json:"installable"`
+	Annotation  string     `json:"annotation"`
 }
 
+// githubSearchPerPage is the number of search results on each page, which is the GitHub default
+const githubSearchPerPage = 30
+
+// githubSearchMaxResults is the number of search results that GitHub returns for a query across all pages
+const githubSearchMaxResults = 1000
+
 func getGithubSearchJSON(searchQuery string) (string, error) {
+	return getGithubSearchPageJSON(searchQuery, 1)
+}
+
+func getGithubSearchPageJSON(searchQuery string, page int) (string, error) {
 	if searchQuery == "" {
 		return "", InvalidGithubSearchQueryError{}
 	}
 	url := fmt.Sprintf("https://api.github.com/search/repositories?q=%v%v", searchQuery, "+fork:true+archived:false")
+	if page > 1 {
+		url = fmt.Sprintf("%v&page=%v", url, page)
+	}
 
 	response, err := getHTTPResponseBody(url)
 	if err != nil {
@@ -265,9 +336,17 @@ func readGithubSearchJSON(jsonString string) (GithubSearch, error) {
 	return ghSearch, nil
 }
 
-// NewGithubSearch creates a new instance of the GithubSearch struct
+// NewGithubSearch creates a new instance of the GithubSearch struct with the first page of search results
 func NewGithubSearch(searchQuery string) (GithubSearch, error) {
-	ghJSON, err := getGithubSearchJSON(searchQuery)
+	return NewGithubSearchPage(searchQuery, 1)
+}
+
+// NewGithubSearchPage creates a new instance of the GithubSearch struct with a page of search results, starting at page 1
+func NewGithubSearchPage(searchQuery string, page int) (GithubSearch, error) {
+	if page < 1 {
+		return GithubSearch{}, InvalidSearchPageError{Page: page}
+	}
+	ghJSON, err := getGithubSearchPageJSON(searchQuery, page)
 	if err != nil {
 		return GithubSearch{}, err
 	}
@@ -278,16 +357,26 @@ func NewGithubSearch(searchQuery string) (GithubSearch, error) {
 	}
 
 	ghSearch.SearchQuery = searchQuery
+	ghSearch.Page = page
 
 	return ghSearch, nil
 }
 
+// HasNextPage checks if there are more search results after the current page
+func (ghSearch GithubSearch) HasNextPage() bool {
+	pageEnd := ghSearch.Page * githubSearchPerPage
+	return pageEnd < ghSearch.Count && pageEnd < githubSearchMaxResults
+}
+
 // FormatSearchResults formats the GitHub search results for the terminal UI
 func FormatSearchResults(ghSearch GithubSearch) []string {
 
 	var formattedSearchResults []string
 	for _, searchResult := range ghSearch.Items {
 		formatted := fmt.Sprintf("%v [⭐️%v] %v", searchResult.FullName, searchResult.Stars, searchResult.Description)
+		if searchResult.Platform != nil {
+			formatted = fmt.Sprintf("%v [⭐️%v] (%v) %v", searchResult.FullName, searchResult.Stars, searchResult.Platform.Annotation, searchResult.Description)
+		}
 		formattedSearchResults = append(formattedSearchResults, formatted)
 	}
 
@@ -308,3 +397,229 @@ func ValidateGithubSearchQuery(searchQuery string) error {
 
 	return nil
 }
+
+func getGithubLatestReleaseJSON(fullName string) (string, error) {
+	url := fmt.Sprintf("https://api.github.com/repos/%v/releases/latest", fullName)
+
+	response, err := getHTTPResponseBody(url)
+	if err != nil {
+		return "", err
+	}
+
+	return response, nil
+}
+
+// GetLatestGithubRelease gets the latest release of a GitHub repo, which excludes prereleases and drafts.
+// It returns false if the repo doesn't have any releases.
+func GetLatestGithubRelease(fullName string) (GithubRelease, bool, error) {
+	ghJSON, err := getGithubLatestReleaseJSON(fullName)
+	var statusCodeErr NonZeroStatusCodeError
+	if errors.As(err, &statusCodeErr) && statusCodeErr.StatusCode == http.StatusNotFound {
+		return GithubRelease{}, false, nil
+	}
+	if err != nil {
+		return GithubRelease{}, false, err
+	}
+
+	var release GithubRelease
+	if err = json.Unmarshal([]byte(ghJSON), &release); err != nil {
+		return GithubRelease{}, false, err
+	}
+	return release, true, nil
+}
+
+// NewSearchResultPlatform annotates a search result with the asset that would be installed from its latest release
+func NewSearchResultPlatform(userOS, userArch string, release GithubRelease, releaseFound bool) (SearchResultPlatform, error) {
+	if !releaseFound {
+		return SearchResultPlatform{Annotation: "no releases"}, nil
+	}
+	platform := SearchResultPlatform{Tag: release.TagName, PublishedAt: &release.PublishedAt}
+
+	releaseAssets := []string{}
+	for _, asset := range release.Assets {
+		releaseAssets = append(releaseAssets, asset.Name)
+	}
+	if len(releaseAssets) == 0 {
+		platform.Annotation = fmt.Sprintf("no assets in %v", release.TagName)
+		return platform, nil
+	}
+
+	asset, matchingAssets, err := DetectPlatformAsset(userOS, userArch, releaseAssets)
+	if err != nil {
+		return SearchResultPlatform{}, err
+	}
+	switch {
+	case asset != "":
+		platform.Asset = asset
+		platform.Installable = true
+		platform.Annotation = asset
+	case len(matchingAssets) > 1:
+		// The asset can still be selected when installing
+		platform.Installable = true
+		platform.Annotation = fmt.Sprintf("%v %v/%v assets", len(matchingAssets), userOS, userArch)
+	default:
+		platform.Annotation = fmt.Sprintf("no %v/%v asset", userOS, userArch)
+	}
+	return platform, nil
+}
+
+// failedSearchResultPlatform annotates a search result whose latest release could not be checked
+func failedSearchResultPlatform(err error) SearchResultPlatform {
+	reason := strings.TrimPrefix(color.ClearCode(err.Error()), "Error: ")
+	return SearchResultPlatform{Annotation: "check failed: " + reason}
+}
+
+// CheckSearchResults checks the latest release of each search result for an asset matching the OS/arch.
+// A search result that can't be checked is annotated with the reason and is not installable.
+func CheckSearchResults(ghSearch *GithubSearch, userOS, userArch string) {
+	const maxConcurrentChecks = 8
+	semaphore := make(chan struct{}, maxConcurrentChecks)
+
+	var wg sync.WaitGroup
+	for index := range ghSearch.Items {
+		wg.Add(1)
+		go func(searchResult *GithubSearchResult) {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			release, releaseFound, err := GetLatestGithubRelease(searchResult.FullName)
+			var platform SearchResultPlatform
+			if err == nil {
+				platform, err = NewSearchResultPlatform(userOS, userArch, release, releaseFound)
+			}
+			if err != nil {
+				platform = failedSearchResultPlatform(err)
+			}
+			searchResult.Platform = &platform
+		}(&ghSearch.Items[index])
+	}
+	wg.Wait()
+}
+
+// FilterInstallableSearchResults removes the search results that don't have an asset for the current platform
+func FilterInstallableSearchResults(ghSearch *GithubSearch) {
+	installableItems := []GithubSearchResult{}
+	for _, searchResult := range ghSearch.Items {
+		if searchResult.Platform != nil && searchResult.Platform.Installable {
+			installableItems = append(installableItems, searchResult)
+		}
+	}
+	ghSearch.Items = installableItems
+}
+
+// SortSearchResults sorts the search results by stars, recent release, or relevance, which is the order returned by GitHub
+func SortSearchResults(ghSearch *GithubSearch, sortBy string) error {
+	switch sortBy {
+	case "", "relevance":
+	case "stars":
+		sort.SliceStable(ghSearch.Items, func(i, j int) bool {
+			return ghSearch.Items[i].Stars > ghSearch.Items[j].Stars
+		})
+	case "release":
+		sort.SliceStable(ghSearch.Items, func(i, j int) bool {
+			return releaseTime(ghSearch.Items[i]).After(releaseTime(ghSearch.Items[j]))
+		})
+	default:
+		return InvalidSearchSortError{Sort: sortBy}
+	}
+	return nil
+}
+
+func releaseTime(searchResult GithubSearchResult) time.Time {
+	if searchResult.Platform == nil || searchResult.Platform.PublishedAt == nil {
+		return time.Time{}
+	}
+	return *searchResult.Platform.PublishedAt
+}
+
+func getGithubReadmeJSON(fullName string) (string, error) {
+	url := fmt.Sprintf("https://api.github.com/repos/%v/readme", fullName)
+
+	response, err := getHTTPResponseBody(url)
+	if err != nil {
+		return "", err
+	}
+
+	return response, nil
+}
+
+// GetGithubReadme gets the README of a GitHub repo. It returns false if the repo doesn't have a README.
+func GetGithubReadme(fullName string) (string, bool, error) {
+	ghJSON, err := getGithubReadmeJSON(fullName)
+	var statusCodeErr NonZeroStatusCodeError
+	if errors.As(err, &statusCodeErr) && statusCodeErr.StatusCode == http.StatusNotFound {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+
+	var readme struct {
+		Content  string `json:"content"`
+		Encoding string `json:"encoding"`
+	}
+	if err = json.Unmarshal([]byte(ghJSON), &readme); err != nil {
+		return "", false, err
+	}
+	if readme.Encoding != "base64" {
+		return readme.Content, true, nil
+	}
+	// The base64 content is split into lines
+	content, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(readme.Content, "\n", ""))
+	if err != nil {
+		return "", false, err
+	}
+	return string(content), true, nil
+}
+
+// FormatReleases formats the GitHub releases as columns for the terminal UI
+func FormatReleases(releases GithubAPIResponse) []string {
+	tagWidth := 0
+	for _, release := range releases {
+		tagWidth = max(tagWidth, len(release.TagName))
+	}
+
+	formattedReleases := []string{}
+	for _, release := range releases {
+		labels := []string{}
+		if release.Draft {
+			labels = append(labels, "draft")
+		}
+		if release.Prerelease {
+			labels = append(labels, "prerelease")
+		}
+		formatted := fmt.Sprintf("%-*v  %10v  %3v assets", tagWidth, release.TagName, formatDate(release.PublishedAt), len(release.Assets))
+		if len(labels) > 0 {
+			formatted += fmt.Sprintf("  [%v]", strings.Join(labels, ", "))
+		}
+		formattedReleases = append(formattedReleases, formatted)
+	}
+	return formattedReleases
+}
+
+// FormatAssets formats the assets of a GitHub release as columns for the terminal UI.
+// The detected asset is marked as matching the OS/arch.
+func FormatAssets(assets []GithubAsset, detectedAsset, userOS, userArch string) []string {
+	nameWidth := 0
+	for _, asset := range assets {
+		nameWidth = max(nameWidth, len(asset.Name))
+	}
+
+	formattedAssets := []string{}
+	for _, asset := range assets {
+		formatted := fmt.Sprintf("%-*v  %10v  %8v downloads  %10v", nameWidth, asset.Name, FormatBytes(int64(asset.Size)), asset.DownloadCount, formatDate(asset.UpdatedAt))
+		if asset.Name == detectedAsset {
+			formatted += fmt.Sprintf("  ✓ %v/%v", userOS, userArch)
+		}
+		formattedAssets = append(formattedAssets, formatted)
+	}
+	return formattedAssets
+}
+
+func formatDate(date time.Time) string {
+	if date.IsZero() {
+		return "-"
+	}
+	return date.Format("2006-01-02")
+}
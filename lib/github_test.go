@@ -1,8 +1,11 @@
 package stew
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"reflect"
 	"testing"
+	"time"
 )
 
 var testGithubRelease0 GithubRelease = GithubRelease{
@@ -499,6 +502,16 @@ func TestDetectAsset(t *testing.T) {
 			want:    "ppath-v0.0.1-windows-unexpectedArch.tar.gz",
 			wantErr: false,
 		},
+		{
+			name: "test8",
+			args: args{
+				userOS:        "linux",
+				userArch:      "amd64",
+				releaseAssets: append(testReleaseAssets, "ppath_0.0.1_linux_amd64.deb", "ppath-0.0.1-1.linux.x86_64.rpm"),
+			},
+			want:    "ppath-v0.0.1-linux-amd64.tar.gz",
+			wantErr: false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -571,6 +584,7 @@ var testGithubSearchReadJSON GithubSearch = GithubSearch{
 
 var testGithubSearch GithubSearch = GithubSearch{
 	SearchQuery: "marwanhawari/ppath",
+	Page:        1,
 	Count:       1,
 	Items: []GithubSearchResult{
 		{
@@ -757,3 +771,224 @@ func TestValidateGithubSearchQuery(t *testing.T) {
 		})
 	}
 }
+
+func TestNewSearchResultPlatform(t *testing.T) {
+	release := GithubRelease{
+		TagName: "v0.0.3",
+		Assets: []GithubAsset{
+			{Name: "ppath-v0.0.3-darwin-amd64.tar.gz"},
+			{Name: "ppath-v0.0.3-linux-amd64.tar.gz"},
+			{Name: "ppath-v0.0.3-linux-arm64.tar.gz"},
+			{Name: "ppath-v0.0.3-linux-arm64.tar.gz.sha256"},
+		},
+	}
+	tests := []struct {
+		name            string
+		userOS          string
+		userArch        string
+		release         GithubRelease
+		releaseFound    bool
+		wantAnnotation  string
+		wantInstallable bool
+	}{
+		{
+			name:            "test1",
+			userOS:          "linux",
+			userArch:        "arm64",
+			release:         release,
+			releaseFound:    true,
+			wantAnnotation:  "ppath-v0.0.3-linux-arm64.tar.gz",
+			wantInstallable: true,
+		},
+		{
+			name:            "test2",
+			userOS:          "linux",
+			userArch:        "386",
+			release:         release,
+			releaseFound:    true,
+			wantAnnotation:  "no linux/386 asset",
+			wantInstallable: false,
+		},
+		{
+			name:            "test3",
+			userOS:          "linux",
+			userArch:        "amd64",
+			release:         GithubRelease{},
+			releaseFound:    false,
+			wantAnnotation:  "no releases",
+			wantInstallable: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewSearchResultPlatform(tt.userOS, tt.userArch, tt.release, tt.releaseFound)
+			if err != nil {
+				t.Fatalf("NewSearchResultPlatform() error = %v", err)
+			}
+			if got.Annotation != tt.wantAnnotation || got.Installable != tt.wantInstallable {
+				t.Errorf("NewSearchResultPlatform() = %v, %v, want %v, %v", got.Annotation, got.Installable, tt.wantAnnotation, tt.wantInstallable)
+			}
+		})
+	}
+}
+
+func Test_failedSearchResultPlatform(t *testing.T) {
+	got := failedSearchResultPlatform(NonZeroStatusCodeError{StatusCode: 403})
+	want := "check failed: Received non-zero status code from HTTP request: 403"
+	if got.Annotation != want || got.Installable {
+		t.Errorf("failedSearchResultPlatform() = %v, %v, want %v, false", got.Annotation, got.Installable, want)
+	}
+}
+
+func TestSortSearchResults(t *testing.T) {
+	older := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	newer := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	newGithubSearch := func() GithubSearch {
+		return GithubSearch{Items: []GithubSearchResult{
+			{FullName: "a/a", Stars: 1, Platform: &SearchResultPlatform{PublishedAt: &older, Installable: true}},
+			{FullName: "b/b", Stars: 3},
+			{FullName: "c/c", Stars: 2, Platform: &SearchResultPlatform{PublishedAt: &newer}},
+		}}
+	}
+	tests := []struct {
+		name    string
+		sortBy  string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:   "test1",
+			sortBy: "relevance",
+			want:   []string{"a/a", "b/b", "c/c"},
+		},
+		{
+			name:   "test2",
+			sortBy: "stars",
+			want:   []string{"b/b", "c/c", "a/a"},
+		},
+		{
+			name:   "test3",
+			sortBy: "release",
+			want:   []string{"c/c", "a/a", "b/b"},
+		},
+		{
+			name:    "test4",
+			sortBy:  "forks",
+			want:    []string{"a/a", "b/b", "c/c"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ghSearch := newGithubSearch()
+			err := SortSearchResults(&ghSearch, tt.sortBy)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SortSearchResults() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			got := []string{}
+			for _, searchResult := range ghSearch.Items {
+				got = append(got, searchResult.FullName)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SortSearchResults() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterInstallableSearchResults(t *testing.T) {
+	ghSearch := GithubSearch{Items: []GithubSearchResult{
+		{FullName: "a/a", Platform: &SearchResultPlatform{Installable: true}},
+		{FullName: "b/b", Platform: &SearchResultPlatform{Installable: false}},
+		{FullName: "c/c"},
+	}}
+	FilterInstallableSearchResults(&ghSearch)
+	if len(ghSearch.Items) != 1 || ghSearch.Items[0].FullName != "a/a" {
+		t.Errorf("FilterInstallableSearchResults() = %v, want [a/a]", ghSearch.Items)
+	}
+}
+
+func TestGithubSearch_HasNextPage(t *testing.T) {
+	tests := []struct {
+		name         string
+		githubSearch GithubSearch
+		want         bool
+	}{
+		{
+			name:         "test1",
+			githubSearch: GithubSearch{Page: 1, Count: 31},
+			want:         true,
+		},
+		{
+			name:         "test2",
+			githubSearch: GithubSearch{Page: 2, Count: 60},
+			want:         false,
+		},
+		{
+			name:         "test3",
+			githubSearch: GithubSearch{Page: 34, Count: 5000},
+			want:         false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.githubSearch.HasNextPage(); got != tt.want {
+				t.Errorf("GithubSearch.HasNextPage() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetGithubReadme(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/marwanhawari/ppath/readme" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"encoding": "base64", "content": "IyBwcGF0aAo=\n"}`))
+	}))
+	defer server.Close()
+
+	if err := SetURLRewriteRules([]URLRewriteRule{{Prefix: "https://api.github.com/", Replacement: server.URL + "/"}}); err != nil {
+		t.Fatalf("SetURLRewriteRules() error = %v", err)
+	}
+	defer SetURLRewriteRules(nil)
+
+	readme, readmeFound, err := GetGithubReadme("marwanhawari/ppath")
+	if err != nil || !readmeFound || readme != "# ppath\n" {
+		t.Errorf("GetGithubReadme() = %q, %v, %v, want %q, true, nil", readme, readmeFound, err, "# ppath\n")
+	}
+
+	_, readmeFound, err = GetGithubReadme("marwanhawari/missing")
+	if err != nil || readmeFound {
+		t.Errorf("GetGithubReadme() = %v, %v, want false, nil", readmeFound, err)
+	}
+}
+
+func TestFormatReleases(t *testing.T) {
+	releases := GithubAPIResponse{
+		{TagName: "v0.0.4-rc1", Prerelease: true, PublishedAt: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), Assets: []GithubAsset{{Name: "a"}}},
+		{TagName: "v0.0.3", PublishedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	want := []string{
+		"v0.0.4-rc1  2024-02-01    1 assets  [prerelease]",
+		"v0.0.3      2024-01-01    0 assets",
+	}
+	if got := FormatReleases(releases); !reflect.DeepEqual(got, want) {
+		t.Errorf("FormatReleases() = %q, want %q", got, want)
+	}
+}
+
+func TestFormatAssets(t *testing.T) {
+	assets := []GithubAsset{
+		{Name: "ppath-linux-amd64.tar.gz", Size: 2048, DownloadCount: 12, UpdatedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{Name: "checksums.txt", Size: 100},
+	}
+	want := []string{
+		"ppath-linux-amd64.tar.gz     2.0 KiB        12 downloads  2024-01-01  ✓ linux/amd64",
+		"checksums.txt                  100 B         0 downloads           -",
+	}
+	if got := FormatAssets(assets, "ppath-linux-amd64.tar.gz", "linux", "amd64"); !reflect.DeepEqual(got, want) {
+		t.Errorf("FormatAssets() = %q, want %q", got, want)
+	}
+}
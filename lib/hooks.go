@@ -0,0 +1,153 @@
+package stew
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/marwanhawari/stew/constants"
+)
+
+const defaultHookTimeout = 60 * time.Second
+
+// The hooks that can be set for a package
+const (
+	PostInstallHook  = "postInstall"
+	PostUpgradeHook  = "postUpgrade"
+	PreUninstallHook = "preUninstall"
+)
+
+var hooksEnabled = true
+var configHooks = map[string]Hooks{}
+
+// Hooks contains the shell commands that are run after a package is installed or upgraded, and before it is uninstalled
+type Hooks struct {
+	PostInstall  string `json:"postInstall,omitempty" toml:"postInstall,omitempty"`
+	PostUpgrade  string `json:"postUpgrade,omitempty" toml:"postUpgrade,omitempty"`
+	PreUninstall string `json:"preUninstall,omitempty" toml:"preUninstall,omitempty"`
+	// Timeout is a duration like 30s. Defaults to 60s.
+	Timeout string `json:"timeout,omitempty" toml:"timeout,omitempty"`
+}
+
+// SetHooksEnabled turns the package hooks on or off
+func SetHooksEnabled(enabled bool) {
+	hooksEnabled = enabled
+}
+
+// SetConfigHooks validates the hooks from the stew config, which are keyed by the binary name, and sets them
+func SetConfigHooks(hooks map[string]Hooks) error {
+	for _, binaryHooks := range hooks {
+		if err := binaryHooks.Validate(); err != nil {
+			return err
+		}
+	}
+	configHooks = hooks
+	return nil
+}
+
+// Validate checks that the timeout of the hooks is a valid duration
+func (h Hooks) Validate() error {
+	_, err := h.timeout()
+	return err
+}
+
+func (h Hooks) timeout() (time.Duration, error) {
+	if h.Timeout == "" {
+		return defaultHookTimeout, nil
+	}
+	duration, err := time.ParseDuration(h.Timeout)
+	if err != nil || duration <= 0 {
+		return 0, InvalidHookTimeoutError{Timeout: h.Timeout}
+	}
+	return duration, nil
+}
+
+func (h Hooks) command(hook string) string {
+	switch hook {
+	case PostInstallHook:
+		return h.PostInstall
+	case PostUpgradeHook:
+		return h.PostUpgrade
+	case PreUninstallHook:
+		return h.PreUninstall
+	}
+	return ""
+}
+
+// PackageHooks returns the hooks of a package. The hooks in the stew config for its binary replace the hooks of the package one by one.
+// It returns nil if the package doesn't have any hooks.
+func PackageHooks(pkg PackageData) *Hooks {
+	var hooks Hooks
+	if pkg.Hooks != nil {
+		hooks = *pkg.Hooks
+	}
+	if binaryHooks, found := configHooks[pkg.Binary]; found {
+		if binaryHooks.PostInstall != "" {
+			hooks.PostInstall = binaryHooks.PostInstall
+		}
+		if binaryHooks.PostUpgrade != "" {
+			hooks.PostUpgrade = binaryHooks.PostUpgrade
+		}
+		if binaryHooks.PreUninstall != "" {
+			hooks.PreUninstall = binaryHooks.PreUninstall
+		}
+		if binaryHooks.Timeout != "" {
+			hooks.Timeout = binaryHooks.Timeout
+		}
+	}
+	if hooks == (Hooks{}) {
+		return nil
+	}
+	return &hooks
+}
+
+// HookEnv returns the environment variables that describe the package to a hook
+func HookEnv(hook string, pkg PackageData, binaryPath, previousTag string) []string {
+	return []string{
+		"STEW_HOOK=" + hook,
+		"STEW_BINARY=" + pkg.Binary,
+		"STEW_BINARY_PATH=" + binaryPath,
+		"STEW_TAG=" + pkg.Tag,
+		"STEW_PREVIOUS_TAG=" + previousTag,
+		"STEW_SOURCE=" + pkg.Source,
+		"STEW_OWNER=" + pkg.Owner,
+		"STEW_REPO=" + pkg.Repo,
+		"STEW_ASSET=" + pkg.Asset,
+		"STEW_URL=" + pkg.URL,
+	}
+}
+
+// RunHook runs a hook of the package in a shell, with the package described in environment variables.
+// Nothing is run if the hooks are turned off or the package doesn't have the hook.
+func RunHook(hook string, pkg PackageData, binaryPath, previousTag string) error {
+	hooks := PackageHooks(pkg)
+	if !hooksEnabled || hooks == nil || hooks.command(hook) == "" {
+		return nil
+	}
+	timeout, err := hooks.timeout()
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(humanOutput, "🪝 Running the %v hook of the %v binary\n", constants.GreenColor(hook), constants.GreenColor(pkg.Binary))
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	command := newHookCommand(ctx, hooks.command(hook))
+	command.Env = append(os.Environ(), HookEnv(hook, pkg, binaryPath, previousTag)...)
+	command.Stdout = humanOutput
+	command.Stderr = os.Stderr
+	// The hook is stopped at the timeout even if it started other processes that keep its output open
+	command.WaitDelay = time.Second
+
+	err = command.Run()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return HookTimeoutError{Binary: pkg.Binary, Hook: hook, Timeout: timeout.String()}
+	}
+	if err != nil {
+		return HookFailedError{Binary: pkg.Binary, Hook: hook, Err: err}
+	}
+	return nil
+}
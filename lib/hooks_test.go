@@ -0,0 +1,154 @@
+package stew
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+func TestPackageHooks(t *testing.T) {
+	defer SetConfigHooks(map[string]Hooks{})
+	err := SetConfigHooks(map[string]Hooks{
+		"rg": {PostUpgrade: "rg --version", Timeout: "10s"},
+	})
+	if err != nil {
+		t.Fatalf("SetConfigHooks() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		pkg  PackageData
+		want *Hooks
+	}{
+		{
+			name: "test1",
+			pkg:  PackageData{Binary: "rg", Hooks: &Hooks{PostInstall: "rg --help", PostUpgrade: "true"}},
+			want: &Hooks{PostInstall: "rg --help", PostUpgrade: "rg --version", Timeout: "10s"},
+		},
+		{
+			name: "test2",
+			pkg:  PackageData{Binary: "fzf", Hooks: &Hooks{PreUninstall: "rm -f ~/.zsh/_fzf"}},
+			want: &Hooks{PreUninstall: "rm -f ~/.zsh/_fzf"},
+		},
+		{
+			name: "test3",
+			pkg:  PackageData{Binary: "fzf"},
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PackageHooks(tt.pkg); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("PackageHooks() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHooksValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		hooks   Hooks
+		wantErr bool
+	}{
+		{
+			name:    "test1",
+			hooks:   Hooks{PostInstall: "true"},
+			wantErr: false,
+		},
+		{
+			name:    "test2",
+			hooks:   Hooks{PostInstall: "true", Timeout: "2m"},
+			wantErr: false,
+		},
+		{
+			name:    "test3",
+			hooks:   Hooks{PostInstall: "true", Timeout: "ten seconds"},
+			wantErr: true,
+		},
+		{
+			name:    "test4",
+			hooks:   Hooks{PostInstall: "true", Timeout: "-1s"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.hooks.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Hooks.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRunHook(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the test hooks use sh")
+	}
+	tempDir := t.TempDir()
+	outputPath := filepath.Join(tempDir, "output")
+
+	tests := []struct {
+		name       string
+		hook       string
+		hooks      *Hooks
+		want       string
+		wantErr    bool
+		wantErrVal error
+	}{
+		{
+			name:  "test1",
+			hook:  PostUpgradeHook,
+			hooks: &Hooks{PostUpgrade: `echo "$STEW_HOOK $STEW_BINARY $STEW_PREVIOUS_TAG $STEW_TAG $STEW_BINARY_PATH" > ` + outputPath},
+			want:  "postUpgrade rg 13.0.0 14.0.0 /bin/rg\n",
+		},
+		{
+			name:  "test2",
+			hook:  PostInstallHook,
+			hooks: &Hooks{PostUpgrade: "exit 1"},
+			want:  "",
+		},
+		{
+			name:       "test3",
+			hook:       PreUninstallHook,
+			hooks:      &Hooks{PreUninstall: "exit 3"},
+			wantErr:    true,
+			wantErrVal: HookFailedError{},
+		},
+		{
+			name:       "test4",
+			hook:       PostInstallHook,
+			hooks:      &Hooks{PostInstall: "sleep 5", Timeout: "100ms"},
+			wantErr:    true,
+			wantErrVal: HookTimeoutError{Binary: "rg", Hook: PostInstallHook, Timeout: "100ms"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Remove(outputPath)
+			pkg := PackageData{Binary: "rg", Tag: "14.0.0", Hooks: tt.hooks}
+			err := RunHook(tt.hook, pkg, "/bin/rg", "13.0.0")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RunHook() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && reflect.TypeOf(err) != reflect.TypeOf(tt.wantErrVal) {
+				t.Fatalf("RunHook() error = %T, want %T", err, tt.wantErrVal)
+			}
+			if timeoutErr, isTimeout := tt.wantErrVal.(HookTimeoutError); isTimeout && err != timeoutErr {
+				t.Errorf("RunHook() error = %v, want %v", err, timeoutErr)
+			}
+			output, _ := os.ReadFile(outputPath)
+			if string(output) != tt.want {
+				t.Errorf("RunHook() output = %q, want %q", output, tt.want)
+			}
+		})
+	}
+
+	SetHooksEnabled(false)
+	defer SetHooksEnabled(true)
+	if err := RunHook(PreUninstallHook, PackageData{Binary: "rg", Hooks: &Hooks{PreUninstall: "exit 1"}}, "/bin/rg", ""); err != nil {
+		t.Errorf("RunHook() with the hooks turned off error = %v, want nil", err)
+	}
+}
@@ -0,0 +1,19 @@
+//go:build !windows
+
+package stew
+
+import (
+	"context"
+	"os/exec"
+	"syscall"
+)
+
+// newHookCommand runs the hook in its own process group, so that the processes it starts are also stopped at the timeout
+func newHookCommand(ctx context.Context, hookCommand string) *exec.Cmd {
+	command := exec.CommandContext(ctx, "sh", "-c", hookCommand)
+	command.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	command.Cancel = func() error {
+		return syscall.Kill(-command.Process.Pid, syscall.SIGKILL)
+	}
+	return command
+}
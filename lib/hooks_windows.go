@@ -0,0 +1,12 @@
+//go:build windows
+
+package stew
+
+import (
+	"context"
+	"os/exec"
+)
+
+func newHookCommand(ctx context.Context, hookCommand string) *exec.Cmd {
+	return exec.CommandContext(ctx, "cmd", "/C", hookCommand)
+}
@@ -1,29 +1,158 @@
 package stew
 
 import (
-	"fmt"
+	"crypto/tls"
+	"crypto/x509"
 	"io"
+	"net"
 	"net/http"
 	"os"
 	"strings"
+	"time"
+
+	"github.com/marwanhawari/stew/constants"
 )
 
-func getHTTPResponseBody(url string) (string, error) {
-	client := &http.Client{}
-	req, err := http.NewRequest("GET", url, nil)
+const defaultConnectTimeout = 30 * time.Second
+const defaultResponseTimeout = 60 * time.Second
+
+var httpClient = newHTTPClient(defaultConnectTimeout, defaultResponseTimeout, nil)
+var userAgent = "stew/" + constants.StewVersion
+
+func newHTTPClient(connectTimeout, responseTimeout time.Duration, tlsConfig *tls.Config) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyFromEnvironment
+	transport.DialContext = (&net.Dialer{Timeout: connectTimeout, KeepAlive: 30 * time.Second}).DialContext
+	transport.TLSHandshakeTimeout = connectTimeout
+	transport.ResponseHeaderTimeout = responseTimeout
+	if tlsConfig != nil {
+		transport.TLSClientConfig = tlsConfig
+	}
+	return &http.Client{Transport: transport}
+}
+
+// SetHTTPConfig builds the HTTP client that is shared by every request from the stew config. A nil config uses the defaults.
+func SetHTTPConfig(httpConfig *HTTPConfig) error {
+	if httpConfig == nil {
+		httpConfig = &HTTPConfig{}
+	}
+
+	connectTimeout, err := parseHTTPTimeout("connectTimeout", httpConfig.ConnectTimeout, defaultConnectTimeout)
 	if err != nil {
-		return "", err
+		return err
+	}
+	responseTimeout, err := parseHTTPTimeout("responseTimeout", httpConfig.ResponseTimeout, defaultResponseTimeout)
+	if err != nil {
+		return err
 	}
 
-	if strings.Contains(url, "api.github.com") {
-		req.Header.Add("Accept", "application/vnd.github.v3+json")
-		githubToken := os.Getenv("GITHUB_TOKEN")
-		if githubToken != "" {
-			req.Header.Add("Authorization", fmt.Sprintf("token %v", githubToken))
+	var tlsConfig *tls.Config
+	if httpConfig.CABundle != "" || httpConfig.ClientCert != "" || httpConfig.ClientKey != "" {
+		tlsConfig, err = newTLSConfig(httpConfig)
+		if err != nil {
+			return err
+		}
+	}
+
+	httpClient = newHTTPClient(connectTimeout, responseTimeout, tlsConfig)
+	userAgent = "stew/" + constants.StewVersion
+	if httpConfig.UserAgent != "" {
+		userAgent = httpConfig.UserAgent
+	}
+	return nil
+}
+
+func parseHTTPTimeout(name, timeout string, defaultTimeout time.Duration) (time.Duration, error) {
+	if timeout == "" {
+		return defaultTimeout, nil
+	}
+	duration, err := time.ParseDuration(timeout)
+	if err != nil || duration < 0 {
+		return 0, InvalidHTTPConfigError{Setting: name, Reason: "use a duration like 30s"}
+	}
+	return duration, nil
+}
+
+func newTLSConfig(httpConfig *HTTPConfig) (*tls.Config, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if httpConfig.CABundle != "" {
+		caBundlePath, err := ResolvePath(httpConfig.CABundle)
+		if err != nil {
+			return nil, err
+		}
+		caBundle, err := os.ReadFile(caBundlePath)
+		if err != nil {
+			return nil, InvalidHTTPConfigError{Setting: "caBundle", Reason: err.Error()}
+		}
+		rootCAs, err := x509.SystemCertPool()
+		if err != nil || rootCAs == nil {
+			rootCAs = x509.NewCertPool()
+		}
+		if !rootCAs.AppendCertsFromPEM(caBundle) {
+			return nil, InvalidHTTPConfigError{Setting: "caBundle", Reason: "no PEM certificates found in " + caBundlePath}
 		}
+		tlsConfig.RootCAs = rootCAs
+	}
+
+	if (httpConfig.ClientCert == "") != (httpConfig.ClientKey == "") {
+		return nil, InvalidHTTPConfigError{Setting: "clientCert", Reason: "set both clientCert and clientKey"}
+	}
+	if httpConfig.ClientCert != "" {
+		clientCertPath, err := ResolvePath(httpConfig.ClientCert)
+		if err != nil {
+			return nil, err
+		}
+		clientKeyPath, err := ResolvePath(httpConfig.ClientKey)
+		if err != nil {
+			return nil, err
+		}
+		clientCert, err := tls.LoadX509KeyPair(clientCertPath, clientKeyPath)
+		if err != nil {
+			return nil, InvalidHTTPConfigError{Setting: "clientCert", Reason: err.Error()}
+		}
+		tlsConfig.Certificates = []tls.Certificate{clientCert}
+	}
+
+	return tlsConfig, nil
+}
+
+// newGetRequest creates a GET request for the url after applying the URL rewrite rules.
+// The GitHub headers are based on the original url, but the credential is found for the host that the request is sent to.
+func newGetRequest(url string, githubAccept string) (*http.Request, error) {
+	requestURL, ruleHeaders := RewriteURL(url)
+	req, err := http.NewRequest("GET", requestURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.Contains(url, "api.github.com") {
+		req.Header.Add("Accept", githubAccept)
+	}
+
+	credential, err := FindCredential(req.URL.Hostname())
+	if err != nil {
+		return nil, err
+	}
+	if credential != nil {
+		req.Header.Set("Authorization", credential.Header())
+	}
+
+	req.Header.Set("User-Agent", userAgent)
+	for name, value := range ruleHeaders {
+		req.Header.Set(name, value)
+	}
+
+	return req, nil
+}
+
+func getHTTPResponseBody(url string) (string, error) {
+	req, err := newGetRequest(url, "application/vnd.github.v3+json")
+	if err != nil {
+		return "", err
 	}
 
-	res, err := client.Do(req)
+	res, err := httpClient.Do(req)
 	if err != nil {
 		return "", err
 	}
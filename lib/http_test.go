@@ -1,8 +1,11 @@
 package stew
 
 import (
+	"encoding/pem"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -52,3 +55,99 @@ func TestGetHTTPResponseBody(t *testing.T) {
 		})
 	}
 }
+
+func TestGetHTTPResponseBody_URLRewrite(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/proxy/repos/marwanhawari/ppath" || r.Header.Get("X-JFrog-Art-Api") != "key" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"test":"ok"}`))
+	}))
+	defer server.Close()
+
+	err := SetURLRewriteRules([]URLRewriteRule{{Prefix: "https://api.github.com/", Replacement: server.URL + "/proxy/", Headers: map[string]string{"X-JFrog-Art-Api": "key"}}})
+	if err != nil {
+		t.Fatalf("SetURLRewriteRules() error = %v", err)
+	}
+	defer SetURLRewriteRules(nil)
+
+	got, err := getHTTPResponseBody("https://api.github.com/repos/marwanhawari/ppath")
+	if err != nil {
+		t.Fatalf("getHTTPResponseBody() error = %v", err)
+	}
+	if want := `{"test":"ok"}`; got != want {
+		t.Errorf("getHTTPResponseBody() = %v, want %v", got, want)
+	}
+}
+
+func TestGetHTTPResponseBody_CABundle(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(r.Header.Get("User-Agent")))
+	}))
+	defer server.Close()
+	defer SetHTTPConfig(nil)
+
+	if _, err := getHTTPResponseBody(server.URL); err == nil {
+		t.Errorf("getHTTPResponseBody() error = nil, want an unknown certificate authority error")
+	}
+
+	caBundlePath := filepath.Join(t.TempDir(), "ca.pem")
+	os.WriteFile(caBundlePath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}), 0644)
+	err := SetHTTPConfig(&HTTPConfig{CABundle: caBundlePath, UserAgent: "stew-test"})
+	if err != nil {
+		t.Fatalf("SetHTTPConfig() error = %v", err)
+	}
+
+	got, err := getHTTPResponseBody(server.URL)
+	if err != nil {
+		t.Fatalf("getHTTPResponseBody() error = %v", err)
+	}
+	if want := "stew-test"; got != want {
+		t.Errorf("getHTTPResponseBody() = %v, want %v", got, want)
+	}
+}
+
+func TestSetHTTPConfig(t *testing.T) {
+	defer SetHTTPConfig(nil)
+	tests := []struct {
+		name       string
+		httpConfig *HTTPConfig
+		wantErr    bool
+	}{
+		{
+			name:       "test1",
+			httpConfig: nil,
+			wantErr:    false,
+		},
+		{
+			name:       "test2",
+			httpConfig: &HTTPConfig{ConnectTimeout: "10s", ResponseTimeout: "2m", UserAgent: "stew-test"},
+			wantErr:    false,
+		},
+		{
+			name:       "test3",
+			httpConfig: &HTTPConfig{ConnectTimeout: "10"},
+			wantErr:    true,
+		},
+		{
+			name:       "test4",
+			httpConfig: &HTTPConfig{ClientCert: "client.pem"},
+			wantErr:    true,
+		},
+		{
+			name:       "test5",
+			httpConfig: &HTTPConfig{CABundle: filepath.Join(t.TempDir(), "missing.pem")},
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := SetHTTPConfig(tt.httpConfig); (err != nil) != tt.wantErr {
+				t.Errorf("SetHTTPConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
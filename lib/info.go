@@ -0,0 +1,89 @@
+package stew
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// GithubRepo contains information about a GitHub repo
+type GithubRepo struct {
+	FullName    string         `json:"full_name"`
+	Description string         `json:"description"`
+	License     *GithubLicense `json:"license"`
+	Archived    bool           `json:"archived"`
+	Stars       int            `json:"stargazers_count"`
+	HTMLURL     string         `json:"html_url"`
+}
+
+// GithubLicense contains the license of a GitHub repo
+type GithubLicense struct {
+	SPDXID string `json:"spdx_id"`
+	Name   string `json:"name"`
+}
+
+// PackageInfo contains what stew knows about a package in addition to its lockfile entry
+type PackageInfo struct {
+	Installed  bool   `json:"installed"`
+	BinaryPath string `json:"binaryPath,omitempty"`
+	BinarySize int64  `json:"binarySize,omitempty"`
+	// LinkTarget is the file in the package store that the binary links to in the symlink mode
+	LinkTarget string `json:"linkTarget,omitempty"`
+	// PackagePath is the package store directory that contains the whole extracted tree of a directory package
+	PackagePath string      `json:"packagePath,omitempty"`
+	Repo        *GithubRepo `json:"repo,omitempty"`
+	LatestTag   string      `json:"latestTag,omitempty"`
+	// ReleasesBehind is the number of releases after the installed tag. It is nil if the installed tag isn't a release anymore.
+	ReleasesBehind *int `json:"releasesBehind,omitempty"`
+	// DetectedAsset is the asset that would be installed on this platform
+	DetectedAsset string `json:"detectedAsset,omitempty"`
+}
+
+func getGithubRepoJSON(owner, repo string) (string, error) {
+	url := fmt.Sprintf("https://api.github.com/repos/%v/%v", owner, repo)
+
+	response, err := getHTTPResponseBody(url)
+	if err != nil {
+		return "", err
+	}
+
+	return response, nil
+}
+
+// GetGithubRepo gets the description, license, and archived status of a GitHub repo
+func GetGithubRepo(owner, repo string) (GithubRepo, error) {
+	ghJSON, err := getGithubRepoJSON(owner, repo)
+	if err != nil {
+		return GithubRepo{}, err
+	}
+
+	var ghRepo GithubRepo
+	if err = json.Unmarshal([]byte(ghJSON), &ghRepo); err != nil {
+		return GithubRepo{}, err
+	}
+	return ghRepo, nil
+}
+
+// LatestReleaseTag returns the tag of the newest release that isn't a prerelease or a draft
+func LatestReleaseTag(releases GithubAPIResponse) (string, bool) {
+	for _, release := range releases {
+		if !release.Prerelease && !release.Draft {
+			return release.TagName, true
+		}
+	}
+	return "", false
+}
+
+// ReleasesBehind counts the releases that are newer than the tag, not counting prereleases and drafts.
+// It returns false if the tag isn't one of the releases.
+func ReleasesBehind(releases GithubAPIResponse, tag string) (int, bool) {
+	releasesBehind := 0
+	for _, release := range releases {
+		if release.TagName == tag {
+			return releasesBehind, true
+		}
+		if !release.Prerelease && !release.Draft {
+			releasesBehind++
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,80 @@
+package stew
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+var testInfoReleases = GithubAPIResponse{
+	{TagName: "v4-rc1", Prerelease: true},
+	{TagName: "v3"},
+	{TagName: "v2"},
+	{TagName: "v1"},
+}
+
+func TestLatestReleaseTag(t *testing.T) {
+	got, found := LatestReleaseTag(testInfoReleases)
+	if got != "v3" || !found {
+		t.Errorf("LatestReleaseTag() = %v, %v, want v3, true", got, found)
+	}
+	if _, found := LatestReleaseTag(GithubAPIResponse{{TagName: "v1-rc1", Prerelease: true}}); found {
+		t.Errorf("LatestReleaseTag() found a tag, want none")
+	}
+}
+
+func TestReleasesBehind(t *testing.T) {
+	tests := []struct {
+		name      string
+		tag       string
+		want      int
+		wantFound bool
+	}{
+		{
+			name:      "test1",
+			tag:       "v1",
+			want:      2,
+			wantFound: true,
+		},
+		{
+			name:      "test2",
+			tag:       "v3",
+			want:      0,
+			wantFound: true,
+		},
+		{
+			name:      "test3",
+			tag:       "v0",
+			want:      0,
+			wantFound: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotFound := ReleasesBehind(testInfoReleases, tt.tag)
+			if got != tt.want || gotFound != tt.wantFound {
+				t.Errorf("ReleasesBehind() = %v, %v, want %v, %v", got, gotFound, tt.want, tt.wantFound)
+			}
+		})
+	}
+}
+
+func TestGetGithubRepo(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"full_name": "marwanhawari/ppath", "description": "ppath", "license": {"spdx_id": "MIT", "name": "MIT License"}, "archived": true}`))
+	}))
+	defer server.Close()
+
+	if err := SetURLRewriteRules([]URLRewriteRule{{Prefix: "https://api.github.com/", Replacement: server.URL + "/"}}); err != nil {
+		t.Fatalf("SetURLRewriteRules() error = %v", err)
+	}
+	defer SetURLRewriteRules(nil)
+
+	got, err := GetGithubRepo("marwanhawari", "ppath")
+	if err != nil {
+		t.Fatalf("GetGithubRepo() error = %v", err)
+	}
+	if got.FullName != "marwanhawari/ppath" || got.License == nil || got.License.SPDXID != "MIT" || !got.Archived {
+		t.Errorf("GetGithubRepo() = %v", got)
+	}
+}
@@ -0,0 +1,180 @@
+package stew
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// The ways that binaries can be installed in the stewBinPath
+const (
+	CopyLinkMode    = "copy"
+	SymlinkLinkMode = "symlink"
+)
+
+var linkMode = CopyLinkMode
+
+// SetLinkMode sets how binaries are installed in the stewBinPath.
+// They are either copied there, or kept in the package store and symlinked there.
+func SetLinkMode(mode string) error {
+	switch mode {
+	case CopyLinkMode, "":
+		linkMode = CopyLinkMode
+	case SymlinkLinkMode:
+		linkMode = SymlinkLinkMode
+	default:
+		return InvalidLinkModeError{LinkMode: mode}
+	}
+	return nil
+}
+
+// IsSymlinkMode checks if binaries are symlinked to the package store
+func IsSymlinkMode() bool {
+	return linkMode == SymlinkLinkMode
+}
+
+// PackageStorePath returns the directory of the package store that contains the extracted files of a binary at a tag
+func PackageStorePath(stewPkgPath, binary, tag string) string {
+	version := strings.NewReplacer("/", "_", `\`, "_").Replace(tag)
+	if version == "" {
+		version = "untagged"
+	}
+	return filepath.Join(stewPkgPath, binary, version)
+}
+
+// LinkedStorePath returns the package store directory that the binary in the stewBinPath links into.
+// It returns an empty string if the binary is not a symlink to the package store, including if the binary doesn't exist.
+func LinkedStorePath(stewPkgPath, stewBinPath, binary string) (string, error) {
+	linkTarget, err := readBinaryLink(filepath.Join(stewBinPath, binary))
+	if err != nil || linkTarget == "" {
+		return "", err
+	}
+	relativeTarget, err := filepath.Rel(stewPkgPath, linkTarget)
+	if err != nil {
+		return "", nil
+	}
+	parts := strings.Split(relativeTarget, string(filepath.Separator))
+	if len(parts) < 3 || parts[0] == ".." {
+		return "", nil
+	}
+	return filepath.Join(stewPkgPath, parts[0], parts[1]), nil
+}
+
+// readBinaryLink returns the absolute target of a symlink. It returns an empty string if the path is not a symlink.
+func readBinaryLink(linkPath string) (string, error) {
+	linkInfo, err := os.Lstat(linkPath)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if linkInfo.Mode()&os.ModeSymlink == 0 {
+		return "", nil
+	}
+	linkTarget, err := os.Readlink(linkPath)
+	if err != nil {
+		return "", err
+	}
+	if !filepath.IsAbs(linkTarget) {
+		linkTarget = filepath.Join(filepath.Dir(linkPath), linkTarget)
+	}
+	return linkTarget, nil
+}
+
+// linkBinary moves the extracted files of a package into the package store and points the link in the stewBinPath at its binary.
+// It returns the package store directory.
+func linkBinary(extractionPath, binaryPath, stewPkgPath, stewBinPath, binary, tag string) (string, error) {
+	storePath := PackageStorePath(stewPkgPath, binary, tag)
+	relativeBinaryPath, err := filepath.Rel(extractionPath, binaryPath)
+	if err != nil {
+		return "", err
+	}
+	if err = moveToStore(extractionPath, storePath); err != nil {
+		return "", err
+	}
+	linkTarget := filepath.Join(storePath, relativeBinaryPath)
+	if err = os.Chmod(linkTarget, 0755); err != nil {
+		return "", err
+	}
+
+	return storePath, replaceLink(linkTarget, filepath.Join(stewBinPath, binary))
+}
+
+// moveToStore moves a directory to a package store directory, replacing the one that is already there
+func moveToStore(dirPath, storePath string) error {
+	if err := os.RemoveAll(storePath); err != nil {
+		return err
+	}
+	// The asset of a copied binary that is downloaded without an archive can have the name of the binary
+	if binaryStoreInfo, err := os.Stat(filepath.Dir(storePath)); err == nil && !binaryStoreInfo.IsDir() {
+		if err = os.Remove(filepath.Dir(storePath)); err != nil {
+			return err
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(storePath), 0755); err != nil {
+		return err
+	}
+	return os.Rename(dirPath, storePath)
+}
+
+// replaceLink creates a symlink at linkPath, or replaces the binary or link that is already there
+func replaceLink(linkTarget, linkPath string) error {
+	tmpLinkPath := filepath.Join(filepath.Dir(linkPath), "."+filepath.Base(linkPath)+".tmp-link")
+	if err := os.RemoveAll(tmpLinkPath); err != nil {
+		return err
+	}
+	if err := os.Symlink(linkTarget, tmpLinkPath); err != nil {
+		return err
+	}
+	if err := replaceFile(tmpLinkPath, linkPath); err != nil {
+		os.Remove(tmpLinkPath)
+		return err
+	}
+	return syncDir(filepath.Dir(linkPath))
+}
+
+// removeStorePath removes a version from the package store, and the directory of the binary once it has no other versions
+func removeStorePath(storePath string) error {
+	if err := os.RemoveAll(storePath); err != nil {
+		return err
+	}
+	binaryStorePath := filepath.Dir(storePath)
+	if entries, err := os.ReadDir(binaryStorePath); err == nil && len(entries) == 0 {
+		return os.Remove(binaryStorePath)
+	}
+	return nil
+}
+
+// RenameBinary renames an installed binary. A binary that is linked to the package store is moved to the store directory of its new name.
+func RenameBinary(stewPkgPath, stewBinPath, binary, newBinary string) error {
+	binaryPath := filepath.Join(stewBinPath, binary)
+	newBinaryPath := filepath.Join(stewBinPath, newBinary)
+	storePath, err := LinkedStorePath(stewPkgPath, stewBinPath, binary)
+	if err != nil {
+		return err
+	}
+	if storePath == "" {
+		return os.Rename(binaryPath, newBinaryPath)
+	}
+
+	linkTarget, err := readBinaryLink(binaryPath)
+	if err != nil {
+		return err
+	}
+	relativeTarget, err := filepath.Rel(storePath, linkTarget)
+	if err != nil {
+		return err
+	}
+	newStorePath := filepath.Join(stewPkgPath, newBinary, filepath.Base(storePath))
+	if err = moveToStore(storePath, newStorePath); err != nil {
+		return err
+	}
+	if err = replaceLink(filepath.Join(newStorePath, relativeTarget), newBinaryPath); err != nil {
+		return err
+	}
+	if err = os.Remove(binaryPath); err != nil {
+		return err
+	}
+	return removeStorePath(storePath)
+}
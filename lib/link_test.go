@@ -0,0 +1,196 @@
+package stew
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func newTestLinkedBinary(t *testing.T, systemInfo SystemInfo, binary, tag, contents string) string {
+	extractionPath := filepath.Join(systemInfo.StewTmpPath, "extracted")
+	os.MkdirAll(filepath.Join(extractionPath, "bin"), 0755)
+	binaryPath := filepath.Join(extractionPath, "bin", binary)
+	os.WriteFile(binaryPath, []byte(contents), 0644)
+	storePath, err := linkBinary(extractionPath, binaryPath, systemInfo.StewPkgPath, systemInfo.StewBinPath, binary, tag)
+	if err != nil {
+		t.Fatalf("linkBinary() error = %v", err)
+	}
+	return storePath
+}
+
+func TestSetLinkMode(t *testing.T) {
+	defer SetLinkMode(CopyLinkMode)
+	tests := []struct {
+		name        string
+		mode        string
+		wantSymlink bool
+		wantErr     bool
+	}{
+		{
+			name:        "test1",
+			mode:        "",
+			wantSymlink: false,
+		},
+		{
+			name:        "test2",
+			mode:        "symlink",
+			wantSymlink: true,
+		},
+		{
+			name:    "test3",
+			mode:    "hardlink",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetLinkMode(CopyLinkMode)
+			err := SetLinkMode(tt.mode)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SetLinkMode() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := IsSymlinkMode(); got != tt.wantSymlink {
+				t.Errorf("IsSymlinkMode() = %v, want %v", got, tt.wantSymlink)
+			}
+		})
+	}
+}
+
+func TestPackageStorePath(t *testing.T) {
+	tests := []struct {
+		name string
+		tag  string
+		want string
+	}{
+		{
+			name: "test1",
+			tag:  "v1.2.3",
+			want: filepath.Join("pkg", "rg", "v1.2.3"),
+		},
+		{
+			name: "test2",
+			tag:  "release/v1",
+			want: filepath.Join("pkg", "rg", "release_v1"),
+		},
+		{
+			name: "test3",
+			tag:  "",
+			want: filepath.Join("pkg", "rg", "untagged"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PackageStorePath("pkg", "rg", tt.tag); got != tt.want {
+				t.Errorf("PackageStorePath() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLinkBinary(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks need extra privileges on windows")
+	}
+	systemInfo := newTestTransactionSystemInfo(t)
+
+	storePath := newTestLinkedBinary(t, systemInfo, "rg", "v1.0.0", "v1")
+	if want := filepath.Join(systemInfo.StewPkgPath, "rg", "v1.0.0"); storePath != want {
+		t.Errorf("linkBinary() store path = %v, want %v", storePath, want)
+	}
+	if got, _ := os.ReadFile(filepath.Join(systemInfo.StewBinPath, "rg")); string(got) != "v1" {
+		t.Errorf("linkBinary() binary = %v, want v1", string(got))
+	}
+	if got, _ := LinkedStorePath(systemInfo.StewPkgPath, systemInfo.StewBinPath, "rg"); got != storePath {
+		t.Errorf("LinkedStorePath() = %v, want %v", got, storePath)
+	}
+
+	newTestLinkedBinary(t, systemInfo, "rg", "v1.1.0", "v1.1")
+	if got, _ := os.ReadFile(filepath.Join(systemInfo.StewBinPath, "rg")); string(got) != "v1.1" {
+		t.Errorf("linkBinary() did not replace the link, got %v", string(got))
+	}
+	if exists, _ := PathExists(storePath); !exists {
+		t.Errorf("linkBinary() removed the store path of the previous version")
+	}
+}
+
+func TestLinkedStorePath(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks need extra privileges on windows")
+	}
+	systemInfo := newTestTransactionSystemInfo(t)
+	os.WriteFile(filepath.Join(systemInfo.StewBinPath, "copied"), []byte("copied"), 0755)
+	os.WriteFile(filepath.Join(systemInfo.StewTmpPath, "outside"), []byte("outside"), 0755)
+	os.Symlink(filepath.Join(systemInfo.StewTmpPath, "outside"), filepath.Join(systemInfo.StewBinPath, "outside"))
+	storePath := newTestLinkedBinary(t, systemInfo, "rg", "v1.0.0", "v1")
+
+	tests := []struct {
+		name   string
+		binary string
+		want   string
+	}{
+		{
+			name:   "test1",
+			binary: "rg",
+			want:   storePath,
+		},
+		{
+			name:   "test2",
+			binary: "copied",
+			want:   "",
+		},
+		{
+			name:   "test3",
+			binary: "outside",
+			want:   "",
+		},
+		{
+			name:   "test4",
+			binary: "missing",
+			want:   "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := LinkedStorePath(systemInfo.StewPkgPath, systemInfo.StewBinPath, tt.binary)
+			if err != nil {
+				t.Fatalf("LinkedStorePath() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("LinkedStorePath() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenameBinary(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks need extra privileges on windows")
+	}
+	systemInfo := newTestTransactionSystemInfo(t)
+	newTestLinkedBinary(t, systemInfo, "rg", "v1.0.0", "v1")
+	os.WriteFile(filepath.Join(systemInfo.StewBinPath, "copied"), []byte("copied"), 0755)
+
+	if err := RenameBinary(systemInfo.StewPkgPath, systemInfo.StewBinPath, "rg", "ripgrep"); err != nil {
+		t.Fatalf("RenameBinary() error = %v", err)
+	}
+	if got, _ := os.ReadFile(filepath.Join(systemInfo.StewBinPath, "ripgrep")); string(got) != "v1" {
+		t.Errorf("RenameBinary() binary = %v, want v1", string(got))
+	}
+	wantStorePath := filepath.Join(systemInfo.StewPkgPath, "ripgrep", "v1.0.0")
+	if got, _ := LinkedStorePath(systemInfo.StewPkgPath, systemInfo.StewBinPath, "ripgrep"); got != wantStorePath {
+		t.Errorf("RenameBinary() store path = %v, want %v", got, wantStorePath)
+	}
+	for _, path := range []string{filepath.Join(systemInfo.StewBinPath, "rg"), filepath.Join(systemInfo.StewPkgPath, "rg")} {
+		if exists, _ := PathExists(path); exists {
+			t.Errorf("RenameBinary() did not remove %v", path)
+		}
+	}
+
+	if err := RenameBinary(systemInfo.StewPkgPath, systemInfo.StewBinPath, "copied", "renamed"); err != nil {
+		t.Fatalf("RenameBinary() error = %v", err)
+	}
+	if got, _ := os.ReadFile(filepath.Join(systemInfo.StewBinPath, "renamed")); string(got) != "copied" {
+		t.Errorf("RenameBinary() binary = %v, want copied", string(got))
+	}
+}
@@ -0,0 +1,175 @@
+package stew
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// MirrorEntry describes an asset in a mirror directory
+type MirrorEntry struct {
+	URL    string `json:"url"`
+	Asset  string `json:"asset"`
+	SHA256 string `json:"sha256"`
+	// Path is relative to the mirror directory and always uses forward slashes
+	Path string `json:"path"`
+}
+
+// MirrorManifest lists the assets in a mirror directory
+type MirrorManifest struct {
+	Entries []MirrorEntry `json:"entries"`
+}
+
+func mirrorManifestPath(mirrorPath string) string {
+	return filepath.Join(mirrorPath, "manifest.json")
+}
+
+// mirrorAssetPath returns the path of an asset inside of a mirror directory. Absolute paths and .. elements can't leave the mirror directory.
+func mirrorAssetPath(mirrorPath, relativePath string) (string, error) {
+	nativePath := filepath.FromSlash(relativePath)
+	if strings.HasPrefix(relativePath, "/") || filepath.IsAbs(nativePath) || filepath.VolumeName(nativePath) != "" {
+		return "", UnsafeMirrorPathError{Path: relativePath}
+	}
+	assetPath := filepath.Join(mirrorPath, nativePath)
+	relativeAssetPath, err := filepath.Rel(mirrorPath, assetPath)
+	if err != nil || relativeAssetPath == "." || relativeAssetPath == ".." || strings.HasPrefix(relativeAssetPath, ".."+string(filepath.Separator)) {
+		return "", UnsafeMirrorPathError{Path: relativePath}
+	}
+	return assetPath, nil
+}
+
+// ReadMirrorManifest reads the manifest of a mirror directory. A directory without a manifest has no entries.
+func ReadMirrorManifest(mirrorPath string) (MirrorManifest, error) {
+	manifestBytes, err := os.ReadFile(mirrorManifestPath(mirrorPath))
+	if os.IsNotExist(err) {
+		return MirrorManifest{Entries: []MirrorEntry{}}, nil
+	}
+	if err != nil {
+		return MirrorManifest{}, err
+	}
+
+	var manifest MirrorManifest
+	if err = json.Unmarshal(manifestBytes, &manifest); err != nil {
+		return MirrorManifest{}, err
+	}
+	return manifest, nil
+}
+
+// WriteMirrorManifest writes the manifest of a mirror directory
+func WriteMirrorManifest(mirrorPath string, manifest MirrorManifest) error {
+	manifestBytes, err := json.MarshalIndent(manifest, "", "\t")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(mirrorManifestPath(mirrorPath), bytes.NewReader(manifestBytes), 0644)
+}
+
+// URLHash returns the sha256 hash of a URL, which can be used as the name of a mirrored asset
+func URLHash(url string) string {
+	urlHash := sha256.Sum256([]byte(url))
+	return hex.EncodeToString(urlHash[:])
+}
+
+// FindMirrorAsset looks up the asset of a package in a mirror directory by its URL, its content hash, the hash of its URL, or its asset name.
+// An asset that doesn't match the asset hash in the lockfile is skipped. It returns an empty path if the mirror doesn't have the asset.
+func FindMirrorAsset(mirrorPath string, manifest MirrorManifest, pkg PackageData) (string, error) {
+	relativePaths := []string{}
+	for _, entry := range manifest.Entries {
+		if (pkg.URL != "" && entry.URL == pkg.URL) || (pkg.AssetHash != "" && entry.SHA256 == pkg.AssetHash) {
+			relativePaths = append(relativePaths, entry.Path)
+		}
+	}
+	if pkg.AssetHash != "" {
+		relativePaths = append(relativePaths, path.Join("sha256", pkg.AssetHash))
+	}
+	if pkg.URL != "" {
+		relativePaths = append(relativePaths, path.Join("url", URLHash(pkg.URL)))
+	}
+	if pkg.Asset != "" {
+		relativePaths = append(relativePaths, pkg.Asset)
+	}
+
+	for _, relativePath := range relativePaths {
+		candidatePath, err := mirrorAssetPath(mirrorPath, relativePath)
+		if err != nil {
+			return "", err
+		}
+		candidateInfo, err := os.Stat(candidatePath)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		if !candidateInfo.Mode().IsRegular() {
+			continue
+		}
+		if pkg.AssetHash != "" {
+			candidateHash, err := CalculateFileHash(candidatePath)
+			if err != nil {
+				return "", err
+			}
+			if candidateHash != pkg.AssetHash {
+				continue
+			}
+		}
+		return candidatePath, nil
+	}
+
+	return "", nil
+}
+
+// AddMirrorAsset copies an asset into a mirror directory by its content hash and adds it to the manifest
+func AddMirrorAsset(mirrorPath string, manifest *MirrorManifest, assetPath, url, assetHash string) error {
+	relativePath := path.Join("sha256", assetHash)
+	mirroredPath, err := mirrorAssetPath(mirrorPath, relativePath)
+	if err != nil {
+		return err
+	}
+
+	assetMirrored, err := PathExists(mirroredPath)
+	if err != nil {
+		return err
+	}
+	if !assetMirrored {
+		if err = os.MkdirAll(filepath.Dir(mirroredPath), 0755); err != nil {
+			return err
+		}
+		asset, err := os.Open(assetPath)
+		if err != nil {
+			return err
+		}
+		defer asset.Close()
+		if err = writeFileAtomic(mirroredPath, asset, 0644); err != nil {
+			return err
+		}
+	}
+
+	entry := MirrorEntry{URL: url, Asset: filepath.Base(assetPath), SHA256: assetHash, Path: relativePath}
+	for index := range manifest.Entries {
+		if manifest.Entries[index].URL == url {
+			manifest.Entries[index] = entry
+			return nil
+		}
+	}
+	manifest.Entries = append(manifest.Entries, entry)
+	return nil
+}
+
+// CopyMirrorAsset copies an asset from a mirror directory to the downloadPath and returns its sha256 hash
+func CopyMirrorAsset(mirrorAssetPath, downloadPath string) (string, error) {
+	asset, err := os.Open(mirrorAssetPath)
+	if err != nil {
+		return "", err
+	}
+	defer asset.Close()
+	if err = writeFileAtomic(downloadPath, asset, 0644); err != nil {
+		return "", err
+	}
+	return CalculateFileHash(downloadPath)
+}
@@ -0,0 +1,126 @@
+package stew
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAddMirrorAsset(t *testing.T) {
+	mirrorPath := t.TempDir()
+	assetPath := filepath.Join(t.TempDir(), "ppath-v0.0.3-linux-amd64.tar.gz")
+	os.WriteFile(assetPath, []byte("asset"), 0644)
+	assetHash, _ := CalculateFileHash(assetPath)
+	url := "https://github.com/marwanhawari/ppath/releases/download/v0.0.3/ppath-v0.0.3-linux-amd64.tar.gz"
+
+	manifest := MirrorManifest{Entries: []MirrorEntry{}}
+	if err := AddMirrorAsset(mirrorPath, &manifest, assetPath, url, assetHash); err != nil {
+		t.Fatalf("AddMirrorAsset() error = %v", err)
+	}
+	if err := WriteMirrorManifest(mirrorPath, manifest); err != nil {
+		t.Fatalf("WriteMirrorManifest() error = %v", err)
+	}
+
+	got, err := ReadMirrorManifest(mirrorPath)
+	if err != nil {
+		t.Fatalf("ReadMirrorManifest() error = %v", err)
+	}
+	want := MirrorEntry{URL: url, Asset: "ppath-v0.0.3-linux-amd64.tar.gz", SHA256: assetHash, Path: "sha256/" + assetHash}
+	if len(got.Entries) != 1 || got.Entries[0] != want {
+		t.Errorf("ReadMirrorManifest() = %v, want %v", got.Entries, want)
+	}
+	if contents, _ := os.ReadFile(filepath.Join(mirrorPath, "sha256", assetHash)); string(contents) != "asset" {
+		t.Errorf("AddMirrorAsset() contents = %v, want asset", string(contents))
+	}
+}
+
+func TestFindMirrorAsset(t *testing.T) {
+	mirrorPath := t.TempDir()
+	url := "https://github.com/marwanhawari/ppath/releases/download/v0.0.3/ppath-v0.0.3-linux-amd64.tar.gz"
+	os.WriteFile(filepath.Join(mirrorPath, "ppath-v0.0.3-linux-amd64.tar.gz"), []byte("by name"), 0644)
+	os.MkdirAll(filepath.Join(mirrorPath, "url"), 0755)
+	os.WriteFile(filepath.Join(mirrorPath, "url", URLHash(url)), []byte("by url"), 0644)
+	nameHash, _ := CalculateFileHash(filepath.Join(mirrorPath, "ppath-v0.0.3-linux-amd64.tar.gz"))
+	outsidePath := filepath.Join(filepath.Dir(mirrorPath), "outside")
+	os.WriteFile(outsidePath, []byte("outside"), 0644)
+
+	type args struct {
+		manifest MirrorManifest
+		pkg      PackageData
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "test1",
+			args: args{
+				manifest: MirrorManifest{Entries: []MirrorEntry{{URL: url, Path: "url/" + URLHash(url)}}},
+				pkg:      PackageData{Asset: "ppath-v0.0.3-linux-amd64.tar.gz", URL: url},
+			},
+			want: filepath.Join(mirrorPath, "url", URLHash(url)),
+		},
+		{
+			name: "test2",
+			args: args{
+				manifest: MirrorManifest{},
+				pkg:      PackageData{Asset: "ppath-v0.0.3-linux-amd64.tar.gz", URL: url, AssetHash: nameHash},
+			},
+			want: filepath.Join(mirrorPath, "ppath-v0.0.3-linux-amd64.tar.gz"),
+		},
+		{
+			name: "test3",
+			args: args{
+				manifest: MirrorManifest{},
+				pkg:      PackageData{Asset: "ppath-v0.0.3-linux-amd64.tar.gz", URL: url, AssetHash: "mismatch"},
+			},
+			want: "",
+		},
+		{
+			name: "test4",
+			args: args{
+				manifest: MirrorManifest{},
+				pkg:      PackageData{Asset: "ppath-v0.0.2-linux-amd64.tar.gz", URL: "https://example.com/ppath"},
+			},
+			want: "",
+		},
+		{
+			name: "test5",
+			args: args{
+				manifest: MirrorManifest{Entries: []MirrorEntry{{URL: url, Path: "../outside"}}},
+				pkg:      PackageData{Asset: "ppath-v0.0.3-linux-amd64.tar.gz", URL: url},
+			},
+			wantErr: true,
+		},
+		{
+			name: "test6",
+			args: args{
+				manifest: MirrorManifest{Entries: []MirrorEntry{{URL: url, Path: filepath.ToSlash(outsidePath)}}},
+				pkg:      PackageData{Asset: "ppath-v0.0.3-linux-amd64.tar.gz", URL: url},
+			},
+			wantErr: true,
+		},
+		{
+			name: "test7",
+			args: args{
+				manifest: MirrorManifest{},
+				pkg:      PackageData{Asset: "../outside"},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FindMirrorAsset(mirrorPath, tt.args.manifest, tt.args.pkg)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("FindMirrorAsset() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("FindMirrorAsset() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
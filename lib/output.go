@@ -0,0 +1,121 @@
+package stew
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/gookit/color"
+	"github.com/marwanhawari/stew/constants"
+)
+
+var outputFormat = "text"
+var outputCommand string
+var resultsOutput io.Writer = os.Stdout
+
+// humanOutput receives the human readable output. It is stderr in the json and jsonl formats so that stdout only contains the results.
+var humanOutput = os.Stdout
+var results = []Result{}
+
+// Result contains the structured output of a command for a single package
+type Result struct {
+	Command      string              `json:"command"`
+	Status       string              `json:"status"`
+	Binary       string              `json:"binary,omitempty"`
+	Package      *PackageData        `json:"package,omitempty"`
+	PreviousTag  string              `json:"previousTag,omitempty"`
+	SearchResult *GithubSearchResult `json:"searchResult,omitempty"`
+	Info         *PackageInfo        `json:"info,omitempty"`
+	Problem      *DoctorProblem      `json:"problem,omitempty"`
+	Auth         *AuthStatus         `json:"auth,omitempty"`
+	Stewfile     string              `json:"stewfile,omitempty"`
+	Error        string              `json:"error,omitempty"`
+	ErrorType    string              `json:"errorType,omitempty"`
+	// HookError is set if a hook failed after the package was installed or upgraded
+	HookError string `json:"hookError,omitempty"`
+}
+
+// SetOutputFormat sets the output format to text, json, or jsonl.
+// In the json and jsonl formats, human readable output is written to stderr so that stdout only contains the results.
+func SetOutputFormat(format string) error {
+	switch format {
+	case "text", "":
+		outputFormat = "text"
+	case "json", "jsonl":
+		outputFormat = format
+		humanOutput = os.Stderr
+		constants.LoadingSpinner.Writer = os.Stderr
+		constants.LoadingSpinner.WriterFile = os.Stderr
+	default:
+		return InvalidOutputFormatError{Format: format}
+	}
+	return nil
+}
+
+// HumanOutput returns the file that human readable output is written to
+func HumanOutput() *os.File {
+	return humanOutput
+}
+
+// SetOutputCommand sets the name of the command that is added to every result
+func SetOutputCommand(command string) {
+	outputCommand = command
+}
+
+// IsStructuredOutput checks if the results are written in the json or jsonl format
+func IsStructuredOutput() bool {
+	return outputFormat == "json" || outputFormat == "jsonl"
+}
+
+// NewSuccessResult creates a Result for a package that was handled successfully
+func NewSuccessResult(pkg PackageData) Result {
+	return Result{Status: "success", Binary: pkg.Binary, Package: &pkg}
+}
+
+// WithHookError records a hook that failed after the package was handled successfully. A nil error is ignored.
+func (r Result) WithHookError(err error) Result {
+	if err != nil {
+		r.HookError = color.ClearCode(err.Error())
+	}
+	return r
+}
+
+// NewErrorResult creates a Result for a failed operation. The binary and package are optional.
+func NewErrorResult(binary string, pkg *PackageData, err error) Result {
+	errorType := strings.TrimPrefix(fmt.Sprintf("%T", err), "stew.")
+	return Result{Status: "failure", Binary: binary, Package: pkg, Error: color.ClearCode(err.Error()), ErrorType: errorType}
+}
+
+// EmitResult records a Result. It is written immediately in the jsonl format and by FlushResults in the json format.
+func EmitResult(result Result) {
+	if !IsStructuredOutput() {
+		return
+	}
+	if result.Command == "" {
+		result.Command = outputCommand
+	}
+	if outputFormat == "jsonl" {
+		resultBytes, err := json.Marshal(result)
+		if err != nil {
+			return
+		}
+		fmt.Fprintln(resultsOutput, string(resultBytes))
+		return
+	}
+	results = append(results, result)
+}
+
+// FlushResults writes the recorded results in the json format
+func FlushResults() {
+	if outputFormat != "json" {
+		return
+	}
+	resultsBytes, err := json.MarshalIndent(results, "", "\t")
+	if err != nil {
+		return
+	}
+	fmt.Fprintln(resultsOutput, string(resultsBytes))
+	results = []Result{}
+}
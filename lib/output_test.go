@@ -0,0 +1,126 @@
+package stew
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+func TestSetOutputFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  string
+		wantErr bool
+	}{
+		{
+			name:    "test1",
+			format:  "text",
+			wantErr: false,
+		},
+		{
+			name:    "test2",
+			format:  "yaml",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := SetOutputFormat(tt.format); (err != nil) != tt.wantErr {
+				t.Errorf("SetOutputFormat() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if IsStructuredOutput() {
+				t.Errorf("IsStructuredOutput() = true, want false")
+			}
+		})
+	}
+}
+
+func TestSetOutputFormat_Structured(t *testing.T) {
+	stdout := os.Stdout
+	defer func() { outputFormat, humanOutput = "text", os.Stdout }()
+
+	if err := SetOutputFormat("json"); err != nil {
+		t.Fatalf("SetOutputFormat() error = %v", err)
+	}
+	if !IsStructuredOutput() {
+		t.Errorf("IsStructuredOutput() = false, want true")
+	}
+	if got := HumanOutput(); got != os.Stderr {
+		t.Errorf("HumanOutput() = %v, want os.Stderr", got.Name())
+	}
+	if os.Stdout != stdout {
+		t.Errorf("SetOutputFormat() replaced os.Stdout")
+	}
+}
+
+func TestNewErrorResult(t *testing.T) {
+	tests := []struct {
+		name          string
+		err           error
+		wantErrorType string
+	}{
+		{
+			name:          "test1",
+			err:           BinaryNotInstalledError{Binary: "fzf"},
+			wantErrorType: "BinaryNotInstalledError",
+		},
+		{
+			name:          "test2",
+			err:           &os.PathError{Op: "open", Path: "Stewfile", Err: os.ErrNotExist},
+			wantErrorType: "*fs.PathError",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewErrorResult("fzf", nil, tt.err)
+			if got.Status != "failure" {
+				t.Errorf("NewErrorResult() status = %v, want failure", got.Status)
+			}
+			if got.ErrorType != tt.wantErrorType {
+				t.Errorf("NewErrorResult() errorType = %v, want %v", got.ErrorType, tt.wantErrorType)
+			}
+			if bytes.ContainsRune([]byte(got.Error), '\x1b') {
+				t.Errorf("NewErrorResult() error = %q contains color codes", got.Error)
+			}
+		})
+	}
+}
+
+func TestEmitResult(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		want   string
+	}{
+		{
+			name:   "test1",
+			format: "jsonl",
+			want:   "{\"command\":\"list\",\"status\":\"success\",\"binary\":\"fzf\",\"package\":{\"source\":\"github\",\"owner\":\"junegunn\",\"repo\":\"fzf\",\"tag\":\"0.29.0\",\"asset\":\"\",\"binary\":\"fzf\",\"url\":\"\",\"binaryHash\":\"\"}}\n",
+		},
+		{
+			name:   "test2",
+			format: "json",
+			want:   "[\n\t{\n\t\t\"command\": \"list\",\n\t\t\"status\": \"success\",\n\t\t\"binary\": \"fzf\",\n\t\t\"package\": {\n\t\t\t\"source\": \"github\",\n\t\t\t\"owner\": \"junegunn\",\n\t\t\t\"repo\": \"fzf\",\n\t\t\t\"tag\": \"0.29.0\",\n\t\t\t\"asset\": \"\",\n\t\t\t\"binary\": \"fzf\",\n\t\t\t\"url\": \"\",\n\t\t\t\"binaryHash\": \"\"\n\t\t}\n\t}\n]\n",
+		},
+		{
+			name:   "test3",
+			format: "text",
+			want:   "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buffer bytes.Buffer
+			outputFormat, resultsOutput = tt.format, &buffer
+			defer func() { outputFormat, resultsOutput = "text", os.Stdout }()
+			SetOutputCommand("list")
+
+			EmitResult(NewSuccessResult(PackageData{Source: "github", Owner: "junegunn", Repo: "fzf", Tag: "0.29.0", Binary: "fzf"}))
+			FlushResults()
+
+			if got := buffer.String(); got != tt.want {
+				t.Errorf("EmitResult() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
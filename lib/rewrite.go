@@ -0,0 +1,58 @@
+package stew
+
+import (
+	"os"
+	"regexp"
+	"strings"
+)
+
+type compiledURLRewriteRule struct {
+	URLRewriteRule
+	regex *regexp.Regexp
+}
+
+var urlRewriteRules []compiledURLRewriteRule
+
+// SetURLRewriteRules validates the rules that are applied to the URLs of HTTP requests and sets them
+func SetURLRewriteRules(rules []URLRewriteRule) error {
+	compiledRules := []compiledURLRewriteRule{}
+	for index, rule := range rules {
+		if (rule.Prefix == "") == (rule.Regex == "") {
+			return InvalidURLRewriteRuleError{Index: index, Reason: "set either a prefix or a regex"}
+		}
+		compiledRule := compiledURLRewriteRule{URLRewriteRule: rule}
+		if rule.Regex != "" {
+			regex, err := regexp.Compile(rule.Regex)
+			if err != nil {
+				return InvalidURLRewriteRuleError{Index: index, Reason: err.Error()}
+			}
+			compiledRule.regex = regex
+		}
+		compiledRules = append(compiledRules, compiledRule)
+	}
+	urlRewriteRules = compiledRules
+	return nil
+}
+
+// RewriteURL applies the first rule that matches the url. It returns the rewritten URL and the headers of the rule.
+// The URL is returned unchanged if no rule matches.
+func RewriteURL(url string) (string, map[string]string) {
+	for _, rule := range urlRewriteRules {
+		var rewrittenURL string
+		switch {
+		case rule.regex != nil && rule.regex.MatchString(url):
+			rewrittenURL = rule.regex.ReplaceAllString(url, rule.Replacement)
+		case rule.regex == nil && strings.HasPrefix(url, rule.Prefix):
+			rewrittenURL = rule.Replacement + strings.TrimPrefix(url, rule.Prefix)
+		default:
+			continue
+		}
+
+		headers := map[string]string{}
+		for name, value := range rule.Headers {
+			headers[name] = os.ExpandEnv(value)
+		}
+		return rewrittenURL, headers
+	}
+	return url, map[string]string{}
+}
@@ -0,0 +1,99 @@
+package stew
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRewriteURL(t *testing.T) {
+	t.Setenv("ARTIFACTORY_TOKEN", "secret")
+	err := SetURLRewriteRules([]URLRewriteRule{
+		{
+			Prefix:      "https://api.github.com/",
+			Replacement: "https://artifactory.example.com/api/vcs/github-api/",
+			Headers:     map[string]string{"Authorization": "Bearer ${ARTIFACTORY_TOKEN}"},
+		},
+		{
+			Regex:       `^https://github\.com/([^/]+)/([^/]+)/releases/download/`,
+			Replacement: "https://artifactory.example.com/artifactory/github/$1/$2/releases/download/",
+		},
+	})
+	if err != nil {
+		t.Fatalf("SetURLRewriteRules() error = %v", err)
+	}
+	defer SetURLRewriteRules(nil)
+
+	tests := []struct {
+		name        string
+		url         string
+		want        string
+		wantHeaders map[string]string
+	}{
+		{
+			name:        "test1",
+			url:         "https://api.github.com/repos/marwanhawari/ppath/releases?per_page=100",
+			want:        "https://artifactory.example.com/api/vcs/github-api/repos/marwanhawari/ppath/releases?per_page=100",
+			wantHeaders: map[string]string{"Authorization": "Bearer secret"},
+		},
+		{
+			name:        "test2",
+			url:         "https://github.com/marwanhawari/ppath/releases/download/v0.0.3/ppath-v0.0.3-linux-amd64.tar.gz",
+			want:        "https://artifactory.example.com/artifactory/github/marwanhawari/ppath/releases/download/v0.0.3/ppath-v0.0.3-linux-amd64.tar.gz",
+			wantHeaders: map[string]string{},
+		},
+		{
+			name:        "test3",
+			url:         "https://example.com/ppath",
+			want:        "https://example.com/ppath",
+			wantHeaders: map[string]string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotHeaders := RewriteURL(tt.url)
+			if got != tt.want {
+				t.Errorf("RewriteURL() = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(gotHeaders, tt.wantHeaders) {
+				t.Errorf("RewriteURL() headers = %v, want %v", gotHeaders, tt.wantHeaders)
+			}
+		})
+	}
+}
+
+func TestSetURLRewriteRules(t *testing.T) {
+	defer SetURLRewriteRules(nil)
+	tests := []struct {
+		name    string
+		rules   []URLRewriteRule
+		wantErr bool
+	}{
+		{
+			name:    "test1",
+			rules:   []URLRewriteRule{{Prefix: "https://github.com/", Replacement: "https://proxy.example.com/"}},
+			wantErr: false,
+		},
+		{
+			name:    "test2",
+			rules:   []URLRewriteRule{{Replacement: "https://proxy.example.com/"}},
+			wantErr: true,
+		},
+		{
+			name:    "test3",
+			rules:   []URLRewriteRule{{Prefix: "https://github.com/", Regex: "^https://github.com/", Replacement: "https://proxy.example.com/"}},
+			wantErr: true,
+		},
+		{
+			name:    "test4",
+			rules:   []URLRewriteRule{{Regex: "(", Replacement: "https://proxy.example.com/"}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := SetURLRewriteRules(tt.rules); (err != nil) != tt.wantErr {
+				t.Errorf("SetURLRewriteRules() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package stew
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DetectPackageManager checks if the executable at executablePath was installed by a package manager.
+// It returns the name of the package manager and the command that upgrades stew with it, or empty strings.
+func DetectPackageManager(executablePath string) (string, string) {
+	slashPath := filepath.ToSlash(strings.ToLower(executablePath))
+	switch {
+	case strings.Contains(slashPath, "/cellar/") || strings.Contains(slashPath, "/homebrew/") || strings.Contains(slashPath, "/linuxbrew/"):
+		return "Homebrew", "brew upgrade stew"
+	case strings.HasPrefix(slashPath, "/nix/store/"):
+		return "Nix", "nix profile upgrade stew"
+	case strings.Contains(slashPath, "/scoop/apps/"):
+		return "Scoop", "scoop update stew"
+	case strings.HasPrefix(slashPath, "/usr/bin/") || strings.HasPrefix(slashPath, "/bin/"):
+		return "your system package manager", "your system package manager"
+	}
+	return "", ""
+}
+
+// FindChecksumAsset finds the release asset that contains the checksum of an asset.
+// An asset.sha256 file is preferred over a checksums file for the whole release.
+func FindChecksumAsset(releaseAssets []string, asset string) (string, bool) {
+	for _, suffix := range []string{".sha256", ".sha256sum"} {
+		if _, found := Contains(releaseAssets, asset+suffix); found {
+			return asset + suffix, true
+		}
+	}
+	for _, releaseAsset := range releaseAssets {
+		lowerReleaseAsset := strings.ToLower(releaseAsset)
+		if strings.Contains(lowerReleaseAsset, "checksums") && (strings.HasSuffix(lowerReleaseAsset, ".txt") || strings.Contains(lowerReleaseAsset, "sha256")) {
+			return releaseAsset, true
+		}
+	}
+	return "", false
+}
+
+// ParseChecksum finds the sha256 checksum of an asset in the contents of a checksum file.
+// Both "<checksum>  <asset>" lines and a file with only a checksum are supported.
+func ParseChecksum(checksumFileContents, asset string) (string, bool) {
+	var lines []string
+	scanner := bufio.NewScanner(strings.NewReader(checksumFileContents))
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			lines = append(lines, line)
+		}
+	}
+
+	for _, line := range lines {
+		fields := strings.Fields(line)
+		if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == asset && len(fields[0]) == 64 {
+			return strings.ToLower(fields[0]), true
+		}
+	}
+	if len(lines) == 1 {
+		fields := strings.Fields(lines[0])
+		if len(fields) == 1 && len(fields[0]) == 64 {
+			return strings.ToLower(fields[0]), true
+		}
+	}
+	return "", false
+}
+
+// ReplaceExecutable atomically replaces the executable at executablePath with the file at newExecutablePath
+func ReplaceExecutable(newExecutablePath, executablePath string) error {
+	executableInfo, err := os.Stat(executablePath)
+	if err != nil {
+		return err
+	}
+	newExecutable, err := os.Open(newExecutablePath)
+	if err != nil {
+		return err
+	}
+	defer newExecutable.Close()
+	return writeFileAtomic(executablePath, newExecutable, executableInfo.Mode().Perm()|0111)
+}
@@ -0,0 +1,149 @@
+package stew
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestDetectPackageManager(t *testing.T) {
+	tests := []struct {
+		name           string
+		executablePath string
+		want           string
+	}{
+		{
+			name:           "test1",
+			executablePath: "/opt/homebrew/Cellar/stew/0.6.0/bin/stew",
+			want:           "Homebrew",
+		},
+		{
+			name:           "test2",
+			executablePath: "/nix/store/abc-stew-0.6.0/bin/stew",
+			want:           "Nix",
+		},
+		{
+			name:           "test3",
+			executablePath: `C:\Users\test\scoop\apps\stew\current\stew.exe`,
+			want:           "Scoop",
+		},
+		{
+			name:           "test4",
+			executablePath: "/home/test/.local/bin/stew",
+			want:           "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			executablePath := tt.executablePath
+			if runtime.GOOS != "windows" {
+				executablePath = strings.ReplaceAll(executablePath, `\`, "/")
+			}
+			if got, _ := DetectPackageManager(executablePath); got != tt.want {
+				t.Errorf("DetectPackageManager() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindChecksumAsset(t *testing.T) {
+	tests := []struct {
+		name          string
+		releaseAssets []string
+		want          string
+		wantFound     bool
+	}{
+		{
+			name:          "test1",
+			releaseAssets: []string{"stew-v0.6.0-linux-amd64.tar.gz", "stew-v0.6.0-checksums.txt", "stew-v0.6.0-linux-amd64.tar.gz.sha256"},
+			want:          "stew-v0.6.0-linux-amd64.tar.gz.sha256",
+			wantFound:     true,
+		},
+		{
+			name:          "test2",
+			releaseAssets: []string{"stew-v0.6.0-linux-amd64.tar.gz", "stew-v0.6.0-checksums.txt"},
+			want:          "stew-v0.6.0-checksums.txt",
+			wantFound:     true,
+		},
+		{
+			name:          "test3",
+			releaseAssets: []string{"stew-v0.6.0-linux-amd64.tar.gz"},
+			want:          "",
+			wantFound:     false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotFound := FindChecksumAsset(tt.releaseAssets, "stew-v0.6.0-linux-amd64.tar.gz")
+			if got != tt.want || gotFound != tt.wantFound {
+				t.Errorf("FindChecksumAsset() = %v, %v, want %v, %v", got, gotFound, tt.want, tt.wantFound)
+			}
+		})
+	}
+}
+
+func TestParseChecksum(t *testing.T) {
+	checksum := strings.Repeat("a", 64)
+	otherChecksum := strings.Repeat("b", 64)
+	tests := []struct {
+		name      string
+		contents  string
+		want      string
+		wantFound bool
+	}{
+		{
+			name:      "test1",
+			contents:  otherChecksum + "  stew-v0.6.0-darwin-arm64.tar.gz\n" + checksum + "  stew-v0.6.0-linux-amd64.tar.gz\n",
+			want:      checksum,
+			wantFound: true,
+		},
+		{
+			name:      "test2",
+			contents:  checksum + " *stew-v0.6.0-linux-amd64.tar.gz\n",
+			want:      checksum,
+			wantFound: true,
+		},
+		{
+			name:      "test3",
+			contents:  strings.ToUpper(checksum) + "\n",
+			want:      checksum,
+			wantFound: true,
+		},
+		{
+			name:      "test4",
+			contents:  otherChecksum + "  stew-v0.6.0-darwin-arm64.tar.gz\n",
+			want:      "",
+			wantFound: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotFound := ParseChecksum(tt.contents, "stew-v0.6.0-linux-amd64.tar.gz")
+			if got != tt.want || gotFound != tt.wantFound {
+				t.Errorf("ParseChecksum() = %v, %v, want %v, %v", got, gotFound, tt.want, tt.wantFound)
+			}
+		})
+	}
+}
+
+func TestReplaceExecutable(t *testing.T) {
+	tempDir := t.TempDir()
+	executablePath := filepath.Join(tempDir, "stew")
+	newExecutablePath := filepath.Join(tempDir, "stew-new")
+	os.WriteFile(executablePath, []byte("old"), 0755)
+	os.WriteFile(newExecutablePath, []byte("new"), 0644)
+
+	if err := ReplaceExecutable(newExecutablePath, executablePath); err != nil {
+		t.Fatalf("ReplaceExecutable() error = %v", err)
+	}
+	if contents, _ := os.ReadFile(executablePath); string(contents) != "new" {
+		t.Errorf("ReplaceExecutable() contents = %v, want new", string(contents))
+	}
+	if runtime.GOOS != "windows" {
+		if executableInfo, _ := os.Stat(executablePath); executableInfo.Mode().Perm() != 0755 {
+			t.Errorf("ReplaceExecutable() mode = %v, want 0755", executableInfo.Mode().Perm())
+		}
+	}
+}
@@ -2,6 +2,7 @@ package stew
 
 import (
 	"bufio"
+	"bytes"
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
@@ -9,7 +10,10 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 
+	"github.com/BurntSushi/toml"
 	"github.com/marwanhawari/stew/constants"
 )
 
@@ -30,6 +34,31 @@ type PackageData struct {
 	Binary     string `json:"binary"`
 	URL        string `json:"url"`
 	BinaryHash string `json:"binaryHash"`
+	// AssetHash is the sha256 hash of the downloaded asset, which is used to find it in the download cache
+	AssetHash string `json:"assetHash,omitempty"`
+	// Platforms holds the resolved asset for each locked platform, keyed by "os/arch"
+	Platforms map[string]PlatformAsset `json:"platforms,omitempty"`
+	// URLTemplate is rendered into the URL of a package installed from a URL, with its version in the Tag
+	URLTemplate   string         `json:"urlTemplate,omitempty"`
+	VersionSource *VersionSource `json:"versionSource,omitempty"`
+	// Hooks are recorded so that installing the lockfile runs them again
+	Hooks *Hooks `json:"hooks,omitempty"`
+	// Entrypoints are the paths of the executables in a directory package, relative to its root. The whole extracted directory is kept in the package store.
+	Entrypoints []string `json:"entrypoints,omitempty"`
+	// Wrappers links the entrypoints of a directory package with wrapper scripts instead of symlinks
+	Wrappers bool `json:"wrappers,omitempty"`
+	// ExtraFiles are the man pages and completions installed from a .deb or .rpm package, relative to the stewPath
+	ExtraFiles []string `json:"extraFiles,omitempty"`
+}
+
+// PlatformAsset contains the resolved asset, URL, and binary hash of a package for a specific platform
+type PlatformAsset struct {
+	Asset string `json:"asset"`
+	URL   string `json:"url"`
+	// Binary is the name of the binary on the platform, e.g. fzf.exe on windows
+	Binary     string `json:"binary,omitempty"`
+	BinaryHash string `json:"binaryHash"`
+	AssetHash  string `json:"assetHash,omitempty"`
 }
 
 func ReadLockFileJSON(lockFilePath string) (LockFile, error) {
@@ -56,12 +85,12 @@ func WriteLockFileJSON(lockFileJSON LockFile, outputPath string) error {
 		return err
 	}
 
-	err = os.WriteFile(outputPath, lockFileBytes, 0644)
+	err = writeFileAtomic(outputPath, bytes.NewReader(lockFileBytes), 0644)
 	if err != nil {
 		return err
 	}
 
-	fmt.Printf("📄 Updated %v\n", constants.GreenColor(outputPath))
+	fmt.Fprintf(humanOutput, "📄 Updated %v\n", constants.GreenColor(outputPath))
 
 	return nil
 }
@@ -79,23 +108,137 @@ func RemovePackage(pkgs []PackageData, index int) ([]PackageData, error) {
 	return append(pkgs[:index], pkgs[index+1:]...), nil
 }
 
+// StewfilePackage is a package entry in a TOML or JSON Stewfile
+type StewfilePackage struct {
+	Source string `json:"source,omitempty" toml:"source,omitempty"`
+	Owner  string `json:"owner,omitempty" toml:"owner,omitempty"`
+	Repo   string `json:"repo,omitempty" toml:"repo,omitempty"`
+	Tag    string `json:"tag,omitempty" toml:"tag,omitempty"`
+	Asset  string `json:"asset,omitempty" toml:"asset,omitempty"`
+	Binary string `json:"binary,omitempty" toml:"binary,omitempty"`
+	URL    string `json:"url,omitempty" toml:"url,omitempty"`
+	// Version is the version that a URL template is rendered with. The version source finds the latest version if it is empty.
+	Version       string         `json:"version,omitempty" toml:"version,omitempty"`
+	VersionSource *VersionSource `json:"versionSource,omitempty" toml:"versionSource,omitempty"`
+	Hooks         *Hooks         `json:"hooks,omitempty" toml:"hooks,omitempty"`
+	Entrypoints   []string       `json:"entrypoints,omitempty" toml:"entrypoints,omitempty"`
+	Wrappers      bool           `json:"wrappers,omitempty" toml:"wrappers,omitempty"`
+}
+
+// Stewfile contains the packages of a TOML or JSON Stewfile
+type Stewfile struct {
+	Packages []StewfilePackage `json:"packages" toml:"packages"`
+}
+
+// IsStewfile checks if a path is a Stewfile in any of the supported formats
+func IsStewfile(stewfilePath string) bool {
+	_, isStewfile := Contains([]string{"Stewfile", "Stewfile.toml", "Stewfile.json"}, filepath.Base(stewfilePath))
+	return isStewfile
+}
+
 // ReadStewfileContents will read the contents of the Stewfile
 func ReadStewfileContents(stewfilePath string) ([]PackageData, error) {
-	file, err := os.Open(stewfilePath)
+	stewfileBytes, err := os.ReadFile(stewfilePath)
 	if err != nil {
 		return []PackageData{}, err
 	}
-	defer file.Close()
 
-	scanner := bufio.NewScanner(file)
+	format := detectStewfileFormat(stewfilePath, stewfileBytes)
+	if format == "line" {
+		return readStewfileLines(stewfileBytes)
+	}
+
+	var stewfile Stewfile
+	if format == "json" {
+		err = json.Unmarshal(stewfileBytes, &stewfile)
+	} else {
+		err = toml.Unmarshal(stewfileBytes, &stewfile)
+	}
+	if err != nil {
+		return []PackageData{}, err
+	}
+
+	var packages []PackageData
+	for _, stewfilePkg := range stewfile.Packages {
+		pkg, err := parseStewfilePackage(stewfilePkg)
+		if err != nil {
+			return []PackageData{}, err
+		}
+		packages = append(packages, pkg)
+	}
+
+	return packages, nil
+}
+
+func detectStewfileFormat(stewfilePath string, stewfileBytes []byte) string {
+	switch filepath.Ext(stewfilePath) {
+	case ".json":
+		return "json"
+	case ".toml":
+		return "toml"
+	}
+
+	trimmedContents := strings.TrimSpace(string(stewfileBytes))
+	if strings.HasPrefix(trimmedContents, "{") {
+		return "json"
+	}
+	for _, line := range strings.Split(trimmedContents, "\n") {
+		if strings.TrimSpace(line) == "[[packages]]" {
+			return "toml"
+		}
+	}
+	return "line"
+}
+
+func readStewfileLines(stewfileBytes []byte) ([]PackageData, error) {
+	scanner := bufio.NewScanner(strings.NewReader(string(stewfileBytes)))
 
 	var packages []PackageData
 	for scanner.Scan() {
-		packageText := scanner.Text()
-		pkg, err := ParseCLIInput(packageText)
+		packageText := strings.TrimSpace(scanner.Text())
+		if packageText == "" || strings.HasPrefix(packageText, "#") {
+			continue
+		}
+		fields := strings.Fields(packageText)
+		pkg, err := ParseCLIInput(fields[0])
 		if err != nil {
 			return []PackageData{}, err
 		}
+		var versionURL, versionPath, versionRegex string
+		for _, option := range fields[1:] {
+			key, value, _ := strings.Cut(option, "=")
+			switch key {
+			case "asset":
+				pkg.Asset = value
+			case "version":
+				pkg.Tag = value
+			case "version-url":
+				versionURL = value
+			case "version-path":
+				versionPath = value
+			case "version-regex":
+				versionRegex = value
+			case "entrypoints":
+				pkg.Entrypoints = strings.Split(value, ",")
+			case "wrappers":
+				pkg.Wrappers, err = strconv.ParseBool(value)
+				if err != nil {
+					return []PackageData{}, UnrecognizedStewfileOptionError{Option: option}
+				}
+			default:
+				return []PackageData{}, UnrecognizedStewfileOptionError{Option: option}
+			}
+		}
+		pkg.VersionSource, err = NewVersionSource(versionURL, versionPath, versionRegex)
+		if err != nil {
+			return []PackageData{}, err
+		}
+		if pkg.VersionSource != nil && pkg.URLTemplate == "" {
+			return []PackageData{}, InvalidVersionSourceError{Reason: "a version source can only be used with a URL template"}
+		}
+		if err = ValidateEntrypoints(pkg.Entrypoints); err != nil {
+			return []PackageData{}, err
+		}
 		packages = append(packages, pkg)
 	}
 
@@ -106,6 +249,161 @@ func ReadStewfileContents(stewfilePath string) ([]PackageData, error) {
 	return packages, nil
 }
 
+func parseStewfilePackage(stewfilePkg StewfilePackage) (PackageData, error) {
+	source := stewfilePkg.Source
+	if source == "" {
+		source = "github"
+		if stewfilePkg.URL != "" {
+			source = "other"
+		}
+	}
+
+	pkg := PackageData{
+		Source: source,
+		Owner:  stewfilePkg.Owner,
+		Repo:   stewfilePkg.Repo,
+		Tag:    stewfilePkg.Tag,
+		Asset:  stewfilePkg.Asset,
+		Binary: stewfilePkg.Binary,
+	}
+	if stewfilePkg.Hooks != nil {
+		if err := stewfilePkg.Hooks.Validate(); err != nil {
+			return PackageData{}, err
+		}
+		pkg.Hooks = stewfilePkg.Hooks
+	}
+	if err := ValidateEntrypoints(stewfilePkg.Entrypoints); err != nil {
+		return PackageData{}, err
+	}
+	pkg.Entrypoints = stewfilePkg.Entrypoints
+	pkg.Wrappers = stewfilePkg.Wrappers
+	switch source {
+	case "github":
+		if pkg.Owner == "" || pkg.Repo == "" {
+			return PackageData{}, UnrecognizedInputError{}
+		}
+	case "other":
+		if stewfilePkg.URL == "" {
+			return PackageData{}, UnrecognizedInputError{}
+		}
+		if IsURLTemplate(stewfilePkg.URL) {
+			pkg.URLTemplate = stewfilePkg.URL
+			pkg.Tag = stewfilePkg.Version
+			if stewfilePkg.VersionSource != nil {
+				if err := stewfilePkg.VersionSource.Validate(); err != nil {
+					return PackageData{}, err
+				}
+				pkg.VersionSource = stewfilePkg.VersionSource
+			}
+			break
+		}
+		pkg.URL = stewfilePkg.URL
+		if pkg.Asset == "" {
+			pkg.Asset = filepath.Base(stewfilePkg.URL)
+		}
+	default:
+		return PackageData{}, UnrecognizedInputError{}
+	}
+
+	return pkg, nil
+}
+
+// NewStewfilePackage creates the Stewfile entry that reproduces an installed package. The tag and asset are only included if pinned.
+func NewStewfilePackage(pkg PackageData, pin bool) StewfilePackage {
+	stewfilePkg := StewfilePackage{Source: pkg.Source, Binary: pkg.Binary, Hooks: pkg.Hooks, Entrypoints: pkg.Entrypoints, Wrappers: pkg.Wrappers}
+	switch pkg.Source {
+	case "github":
+		stewfilePkg.Owner = pkg.Owner
+		stewfilePkg.Repo = pkg.Repo
+		if pin {
+			stewfilePkg.Tag = pkg.Tag
+			stewfilePkg.Asset = pkg.Asset
+		}
+	default:
+		if pkg.URLTemplate != "" {
+			stewfilePkg.URL = pkg.URLTemplate
+			stewfilePkg.VersionSource = pkg.VersionSource
+			if pin || pkg.VersionSource == nil {
+				stewfilePkg.Version = pkg.Tag
+			}
+			break
+		}
+		stewfilePkg.URL = pkg.URL
+		if pkg.Asset != filepath.Base(pkg.URL) {
+			stewfilePkg.Asset = pkg.Asset
+		}
+	}
+	return stewfilePkg
+}
+
+// FormatStewfile formats installed packages as a Stewfile in the line, toml, or json format
+func FormatStewfile(pkgs []PackageData, format string, pin bool) (string, error) {
+	stewfile := Stewfile{Packages: []StewfilePackage{}}
+	for _, pkg := range pkgs {
+		stewfile.Packages = append(stewfile.Packages, NewStewfilePackage(pkg, pin))
+	}
+
+	switch format {
+	case "line", "":
+		var builder strings.Builder
+		for _, stewfilePkg := range stewfile.Packages {
+			builder.WriteString(formatStewfileLine(stewfilePkg) + "\n")
+		}
+		return builder.String(), nil
+	case "json":
+		stewfileBytes, err := json.MarshalIndent(stewfile, "", "\t")
+		if err != nil {
+			return "", err
+		}
+		return string(stewfileBytes) + "\n", nil
+	case "toml":
+		var builder strings.Builder
+		if err := toml.NewEncoder(&builder).Encode(stewfile); err != nil {
+			return "", err
+		}
+		return builder.String(), nil
+	default:
+		return "", InvalidStewfileFormatError{Format: format}
+	}
+}
+
+func formatStewfileLine(stewfilePkg StewfilePackage) string {
+	var line string
+	if stewfilePkg.Source == "github" {
+		line = stewfilePkg.Owner + "/" + stewfilePkg.Repo
+		if stewfilePkg.Tag != "" {
+			line += "@" + stewfilePkg.Tag
+		}
+	} else {
+		line = stewfilePkg.URL
+	}
+	if stewfilePkg.Binary != "" {
+		line = stewfilePkg.Binary + ":" + line
+	}
+	if stewfilePkg.Asset != "" {
+		line += " asset=" + stewfilePkg.Asset
+	}
+	if stewfilePkg.Version != "" {
+		line += " version=" + stewfilePkg.Version
+	}
+	if stewfilePkg.VersionSource != nil {
+		line += " version-url=" + stewfilePkg.VersionSource.URL
+		if stewfilePkg.VersionSource.JSONPath != "" {
+			line += " version-path=" + stewfilePkg.VersionSource.JSONPath
+		}
+		if stewfilePkg.VersionSource.Regex != "" {
+			line += " version-regex=" + stewfilePkg.VersionSource.Regex
+		}
+	}
+	if len(stewfilePkg.Entrypoints) > 0 {
+		line += " entrypoints=" + strings.Join(stewfilePkg.Entrypoints, ",")
+	}
+	if stewfilePkg.Wrappers {
+		line += " wrappers=true"
+	}
+	return line
+}
+
 // ReadStewLockFileContents will read the contents of the Stewfile.lock.json
 func ReadStewLockFileContents(lockFilePath string) ([]PackageData, error) {
 	lockFile, err := ReadLockFileJSON(lockFilePath)
@@ -133,11 +431,19 @@ func NewLockFile(stewLockFilePath, userOS, userArch string) (LockFile, error) {
 	return lockFile, nil
 }
 
-// DeleteAssetAndBinary will delete the asset from the ~/.stew/pkg path and delete the binary from the ~/.stew/bin path
+// DeleteAssetAndBinary will delete the asset from the ~/.stew/pkg path and delete the binary from the ~/.stew/bin path.
+// If the binary is linked to the package store, its package store directory is deleted instead of the asset.
 func DeleteAssetAndBinary(stewPkgPath, stewBinPath, asset, binary string) error {
-	assetPath := filepath.Join(stewPkgPath, asset)
 	binPath := filepath.Join(stewBinPath, binary)
-	err := os.RemoveAll(assetPath)
+	storePath, err := LinkedStorePath(stewPkgPath, stewBinPath, binary)
+	if err != nil {
+		return err
+	}
+	if storePath != "" {
+		err = removeStorePath(storePath)
+	} else {
+		err = os.RemoveAll(filepath.Join(stewPkgPath, asset))
+	}
 	if err != nil {
 		return err
 	}
@@ -162,3 +468,61 @@ func CalculateFileHash(filePath string) (string, error) {
 
 	return hex.EncodeToString(hasher.Sum(nil)), nil
 }
+
+// PlatformKey returns the "os/arch" key used for a platform in the lockfile
+func PlatformKey(userOS, userArch string) string {
+	return userOS + "/" + userArch
+}
+
+// ParsePlatform splits an "os/arch" platform into its OS and arch
+func ParsePlatform(platform string) (string, string, error) {
+	splitPlatform := strings.Split(strings.TrimSpace(platform), "/")
+	if len(splitPlatform) != 2 || splitPlatform[0] == "" || splitPlatform[1] == "" {
+		return "", "", InvalidPlatformError{Platform: platform}
+	}
+	return splitPlatform[0], splitPlatform[1], nil
+}
+
+// ResolvePackageForPlatform returns the lockfile package with the asset, URL, and binary hash for the given platform.
+// GitHub packages without an entry for the platform are returned without an asset so that it is detected again.
+func ResolvePackageForPlatform(pkg PackageData, lockFileOS, lockFileArch, userOS, userArch string) (PackageData, error) {
+	platform := PlatformKey(userOS, userArch)
+	if platformAsset, found := pkg.Platforms[platform]; found {
+		pkg.Asset = platformAsset.Asset
+		pkg.URL = platformAsset.URL
+		pkg.BinaryHash = platformAsset.BinaryHash
+		pkg.AssetHash = platformAsset.AssetHash
+		if platformAsset.Binary != "" {
+			pkg.Binary = platformAsset.Binary
+		}
+		return pkg, nil
+	}
+
+	if lockFileOS == userOS && lockFileArch == userArch {
+		return pkg, nil
+	}
+
+	// A URL template is rendered again for the platform with the same version
+	if pkg.Source != "github" && pkg.URLTemplate == "" {
+		return PackageData{}, PlatformNotInLockFileError{Binary: pkg.Binary, Platform: platform}
+	}
+
+	pkg.Asset = ""
+	pkg.URL = ""
+	pkg.Binary = PlatformBinaryName(pkg.Binary, userOS)
+	pkg.BinaryHash = ""
+	pkg.AssetHash = ""
+	return pkg, nil
+}
+
+// PlatformBinaryName returns the name of a binary on an OS, so that the fzf binary is named fzf.exe on windows and fzf everywhere else
+func PlatformBinaryName(binary, platformOS string) string {
+	if binary == "" {
+		return ""
+	}
+	binary = strings.TrimSuffix(binary, ".exe")
+	if platformOS == "windows" {
+		return binary + ".exe"
+	}
+	return binary
+}
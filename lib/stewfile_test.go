@@ -476,3 +476,514 @@ func TestDeleteAssetAndBinary(t *testing.T) {
 		})
 	}
 }
+
+func TestParsePlatform(t *testing.T) {
+	tests := []struct {
+		name     string
+		platform string
+		wantOS   string
+		wantArch string
+		wantErr  bool
+	}{
+		{
+			name:     "test1",
+			platform: "linux/amd64",
+			wantOS:   "linux",
+			wantArch: "amd64",
+			wantErr:  false,
+		},
+		{
+			name:     "test2",
+			platform: "darwin",
+			wantErr:  true,
+		},
+		{
+			name:     "test3",
+			platform: "darwin/arm64/v8",
+			wantErr:  true,
+		},
+		{
+			name:     "test4",
+			platform: "/arm64",
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotOS, gotArch, err := ParsePlatform(tt.platform)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParsePlatform() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if gotOS != tt.wantOS || gotArch != tt.wantArch {
+				t.Errorf("ParsePlatform() = %v, %v, want %v, %v", gotOS, gotArch, tt.wantOS, tt.wantArch)
+			}
+		})
+	}
+}
+
+func TestResolvePackageForPlatform(t *testing.T) {
+	lockedPkg := PackageData{
+		Source:     "github",
+		Owner:      "junegunn",
+		Repo:       "fzf",
+		Tag:        "0.29.0",
+		Asset:      "fzf-0.29.0-darwin_arm64.zip",
+		Binary:     "fzf",
+		URL:        "https://github.com/junegunn/fzf/releases/download/0.29.0/fzf-0.29.0-darwin_arm64.zip",
+		BinaryHash: "darwinHash",
+		Platforms: map[string]PlatformAsset{
+			"linux/amd64": {
+				Asset:      "fzf-0.29.0-linux_amd64.tar.gz",
+				URL:        "https://github.com/junegunn/fzf/releases/download/0.29.0/fzf-0.29.0-linux_amd64.tar.gz",
+				BinaryHash: "linuxHash",
+			},
+			"windows/arm64": {
+				Asset:      "fzf-0.29.0-windows_arm64.zip",
+				URL:        "https://github.com/junegunn/fzf/releases/download/0.29.0/fzf-0.29.0-windows_arm64.zip",
+				Binary:     "fzf.exe",
+				BinaryHash: "windowsHash",
+			},
+		},
+	}
+	wantLinuxPkg := lockedPkg
+	wantLinuxPkg.Asset = "fzf-0.29.0-linux_amd64.tar.gz"
+	wantLinuxPkg.URL = "https://github.com/junegunn/fzf/releases/download/0.29.0/fzf-0.29.0-linux_amd64.tar.gz"
+	wantLinuxPkg.BinaryHash = "linuxHash"
+
+	wantWindowsPkg := lockedPkg
+	wantWindowsPkg.Asset = ""
+	wantWindowsPkg.URL = ""
+	wantWindowsPkg.Binary = "fzf.exe"
+	wantWindowsPkg.BinaryHash = ""
+
+	wantWindowsArmPkg := lockedPkg
+	wantWindowsArmPkg.Asset = "fzf-0.29.0-windows_arm64.zip"
+	wantWindowsArmPkg.URL = "https://github.com/junegunn/fzf/releases/download/0.29.0/fzf-0.29.0-windows_arm64.zip"
+	wantWindowsArmPkg.Binary = "fzf.exe"
+	wantWindowsArmPkg.BinaryHash = "windowsHash"
+
+	urlPkg := testLockfile.Packages[1]
+
+	type args struct {
+		pkg      PackageData
+		userOS   string
+		userArch string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    PackageData
+		wantErr bool
+	}{
+		{
+			name:    "test1",
+			args:    args{pkg: lockedPkg, userOS: "linux", userArch: "amd64"},
+			want:    wantLinuxPkg,
+			wantErr: false,
+		},
+		{
+			name:    "test2",
+			args:    args{pkg: lockedPkg, userOS: "darwin", userArch: "arm64"},
+			want:    lockedPkg,
+			wantErr: false,
+		},
+		{
+			name:    "test3",
+			args:    args{pkg: lockedPkg, userOS: "windows", userArch: "amd64"},
+			want:    wantWindowsPkg,
+			wantErr: false,
+		},
+		{
+			name:    "test4",
+			args:    args{pkg: urlPkg, userOS: "darwin", userArch: "arm64"},
+			want:    urlPkg,
+			wantErr: false,
+		},
+		{
+			name:    "test5",
+			args:    args{pkg: urlPkg, userOS: "linux", userArch: "amd64"},
+			want:    PackageData{},
+			wantErr: true,
+		},
+		{
+			name:    "test6",
+			args:    args{pkg: lockedPkg, userOS: "windows", userArch: "arm64"},
+			want:    wantWindowsArmPkg,
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ResolvePackageForPlatform(tt.args.pkg, "darwin", "arm64", tt.args.userOS, tt.args.userArch)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ResolvePackageForPlatform() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ResolvePackageForPlatform() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlatformBinaryName(t *testing.T) {
+	tests := []struct {
+		name       string
+		binary     string
+		platformOS string
+		want       string
+	}{
+		{name: "test1", binary: "fzf", platformOS: "windows", want: "fzf.exe"},
+		{name: "test2", binary: "fzf.exe", platformOS: "linux", want: "fzf"},
+		{name: "test3", binary: "fzf.exe", platformOS: "windows", want: "fzf.exe"},
+		{name: "test4", binary: "", platformOS: "windows", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PlatformBinaryName(tt.binary, tt.platformOS); got != tt.want {
+				t.Errorf("PlatformBinaryName() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsStewfile(t *testing.T) {
+	tests := []struct {
+		name         string
+		stewfilePath string
+		want         bool
+	}{
+		{name: "test1", stewfilePath: filepath.Join("dotfiles", "Stewfile"), want: true},
+		{name: "test2", stewfilePath: "Stewfile.toml", want: true},
+		{name: "test3", stewfilePath: "Stewfile.json", want: true},
+		{name: "test4", stewfilePath: "Stewfile.lock.json", want: false},
+		{name: "test5", stewfilePath: "junegunn/fzf", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsStewfile(tt.stewfilePath); got != tt.want {
+				t.Errorf("IsStewfile() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadStewfileContentsFormats(t *testing.T) {
+	want := []PackageData{
+		{
+			Source: "github",
+			Owner:  "BurntSushi",
+			Repo:   "ripgrep",
+			Tag:    "13.0.0",
+			Asset:  "ripgrep-13.0.0-x86_64-apple-darwin.tar.gz",
+			Binary: "rg",
+		},
+		{
+			Source: "other",
+			Asset:  "hyperfine-v1.12.0-x86_64-apple-darwin.tar.gz",
+			Binary: "hyperfine",
+			URL:    "https://github.com/sharkdp/hyperfine/releases/download/v1.12.0/hyperfine-v1.12.0-x86_64-apple-darwin.tar.gz",
+		},
+	}
+	tests := []struct {
+		name             string
+		stewfileName     string
+		stewfileContents string
+		want             []PackageData
+		wantErr          bool
+	}{
+		{
+			name:         "test1",
+			stewfileName: "Stewfile",
+			stewfileContents: `# Search tools
+rg:BurntSushi/ripgrep@13.0.0 asset=ripgrep-13.0.0-x86_64-apple-darwin.tar.gz
+
+hyperfine:https://github.com/sharkdp/hyperfine/releases/download/v1.12.0/hyperfine-v1.12.0-x86_64-apple-darwin.tar.gz
+`,
+			want:    want,
+			wantErr: false,
+		},
+		{
+			name:         "test2",
+			stewfileName: "Stewfile",
+			stewfileContents: `[[packages]]
+owner = "BurntSushi"
+repo = "ripgrep"
+tag = "13.0.0"
+asset = "ripgrep-13.0.0-x86_64-apple-darwin.tar.gz"
+binary = "rg"
+
+[[packages]]
+binary = "hyperfine"
+url = "https://github.com/sharkdp/hyperfine/releases/download/v1.12.0/hyperfine-v1.12.0-x86_64-apple-darwin.tar.gz"
+`,
+			want:    want,
+			wantErr: false,
+		},
+		{
+			name:         "test3",
+			stewfileName: "Stewfile.json",
+			stewfileContents: `{"packages": [
+	{"source": "github", "owner": "BurntSushi", "repo": "ripgrep", "tag": "13.0.0", "asset": "ripgrep-13.0.0-x86_64-apple-darwin.tar.gz", "binary": "rg"},
+	{"source": "other", "binary": "hyperfine", "url": "https://github.com/sharkdp/hyperfine/releases/download/v1.12.0/hyperfine-v1.12.0-x86_64-apple-darwin.tar.gz"}
+]}`,
+			want:    want,
+			wantErr: false,
+		},
+		{
+			name:             "test4",
+			stewfileName:     "Stewfile",
+			stewfileContents: "rg:BurntSushi/ripgrep@13.0.0 arch=amd64\n",
+			want:             []PackageData{},
+			wantErr:          true,
+		},
+		{
+			name:             "test5",
+			stewfileName:     "Stewfile.toml",
+			stewfileContents: "[[packages]]\nbinary = \"rg\"\n",
+			want:             []PackageData{},
+			wantErr:          true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir := t.TempDir()
+			testStewfilePath := filepath.Join(tempDir, tt.stewfileName)
+			os.WriteFile(testStewfilePath, []byte(tt.stewfileContents), 0644)
+
+			got, err := ReadStewfileContents(testStewfilePath)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ReadStewfileContents() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ReadStewfileContents() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatStewfile(t *testing.T) {
+	// The download URL of GitHub packages is resolved during install, so it isn't part of the Stewfile
+	pinnedPackages := []PackageData{}
+	for _, pkg := range testLockfile.Packages {
+		if pkg.Source == "github" {
+			pkg.URL = ""
+		}
+		pinnedPackages = append(pinnedPackages, pkg)
+	}
+	tests := []struct {
+		name    string
+		format  string
+		pin     bool
+		want    []PackageData
+		wantErr bool
+	}{
+		{
+			name:   "test1",
+			format: "line",
+			pin:    false,
+			want: []PackageData{
+				{Source: "github", Owner: "junegunn", Repo: "fzf", Binary: "fzf"},
+				{Source: "other", Asset: testLockfile.Packages[1].Asset, Binary: "hyperfine", URL: testLockfile.Packages[1].URL},
+				{Source: "github", Owner: "marwanhawari", Repo: "ppath", Binary: "ppath"},
+			},
+			wantErr: false,
+		},
+		{
+			name:   "test2",
+			format: "line",
+			pin:    true,
+			want:   pinnedPackages,
+		},
+		{
+			name:   "test3",
+			format: "toml",
+			pin:    true,
+			want:   pinnedPackages,
+		},
+		{
+			name:   "test4",
+			format: "json",
+			pin:    true,
+			want:   pinnedPackages,
+		},
+		{
+			name:    "test5",
+			format:  "yaml",
+			pin:     true,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FormatStewfile(testLockfile.Packages, tt.format, tt.pin)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("FormatStewfile() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+
+			tempDir := t.TempDir()
+			testStewfilePath := filepath.Join(tempDir, "Stewfile")
+			os.WriteFile(testStewfilePath, []byte(got), 0644)
+
+			roundTrip, err := ReadStewfileContents(testStewfilePath)
+			if err != nil {
+				t.Errorf("ReadStewfileContents() error = %v", err)
+				return
+			}
+			if !reflect.DeepEqual(roundTrip, tt.want) {
+				t.Errorf("FormatStewfile() round trip = %v, want %v", roundTrip, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadStewfileContentsURLTemplate(t *testing.T) {
+	want := []PackageData{
+		{
+			Source:        "other",
+			Binary:        "kubectl",
+			Tag:           "v1.29.2",
+			URLTemplate:   "https://dl.k8s.io/release/{{.Version}}/bin/{{.OS}}/{{.Arch}}/kubectl",
+			VersionSource: &VersionSource{Type: "text", URL: "https://dl.k8s.io/release/stable.txt"},
+		},
+	}
+	tests := []struct {
+		name             string
+		stewfileName     string
+		stewfileContents string
+		want             []PackageData
+		wantErr          bool
+	}{
+		{
+			name:             "test1",
+			stewfileName:     "Stewfile",
+			stewfileContents: "kubectl:https://dl.k8s.io/release/{{.Version}}/bin/{{.OS}}/{{.Arch}}/kubectl version=v1.29.2 version-url=https://dl.k8s.io/release/stable.txt\n",
+			want:             want,
+			wantErr:          false,
+		},
+		{
+			name:         "test2",
+			stewfileName: "Stewfile.toml",
+			stewfileContents: `[[packages]]
+binary = "kubectl"
+url = "https://dl.k8s.io/release/{{.Version}}/bin/{{.OS}}/{{.Arch}}/kubectl"
+version = "v1.29.2"
+versionSource = { type = "text", url = "https://dl.k8s.io/release/stable.txt" }
+`,
+			want:    want,
+			wantErr: false,
+		},
+		{
+			name:             "test3",
+			stewfileName:     "Stewfile",
+			stewfileContents: "rg:BurntSushi/ripgrep version-url=https://dl.k8s.io/release/stable.txt\n",
+			want:             []PackageData{},
+			wantErr:          true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir := t.TempDir()
+			testStewfilePath := filepath.Join(tempDir, tt.stewfileName)
+			os.WriteFile(testStewfilePath, []byte(tt.stewfileContents), 0644)
+
+			got, err := ReadStewfileContents(testStewfilePath)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ReadStewfileContents() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ReadStewfileContents() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadStewfileContentsHooks(t *testing.T) {
+	tempDir := t.TempDir()
+	testStewfilePath := filepath.Join(tempDir, "Stewfile.toml")
+	os.WriteFile(testStewfilePath, []byte(`[[packages]]
+owner = "BurntSushi"
+repo = "ripgrep"
+binary = "rg"
+
+[packages.hooks]
+postInstall = "rg --generate complete-zsh > ~/.zsh/_rg"
+preUninstall = "rm -f ~/.zsh/_rg"
+timeout = "10s"
+`), 0644)
+
+	want := []PackageData{
+		{
+			Source: "github",
+			Owner:  "BurntSushi",
+			Repo:   "ripgrep",
+			Binary: "rg",
+			Hooks: &Hooks{
+				PostInstall:  "rg --generate complete-zsh > ~/.zsh/_rg",
+				PreUninstall: "rm -f ~/.zsh/_rg",
+				Timeout:      "10s",
+			},
+		},
+	}
+	got, err := ReadStewfileContents(testStewfilePath)
+	if err != nil {
+		t.Fatalf("ReadStewfileContents() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ReadStewfileContents() = %v, want %v", got, want)
+	}
+}
+
+func TestReadStewfileContentsEntrypoints(t *testing.T) {
+	tempDir := t.TempDir()
+	tomlStewfilePath := filepath.Join(tempDir, "Stewfile.toml")
+	os.WriteFile(tomlStewfilePath, []byte(`[[packages]]
+owner = "nodejs"
+repo = "node"
+entrypoints = ["bin/node", "bin/npm"]
+wrappers = true
+`), 0644)
+	lineStewfilePath := filepath.Join(tempDir, "Stewfile")
+	os.WriteFile(lineStewfilePath, []byte("nodejs/node entrypoints=bin/node,bin/npm wrappers=true\n"), 0644)
+	invalidStewfilePath := filepath.Join(tempDir, "invalid", "Stewfile")
+	os.MkdirAll(filepath.Dir(invalidStewfilePath), 0755)
+	os.WriteFile(invalidStewfilePath, []byte("nodejs/node entrypoints=../bin/node\n"), 0644)
+
+	want := []PackageData{
+		{
+			Source:      "github",
+			Owner:       "nodejs",
+			Repo:        "node",
+			Entrypoints: []string{"bin/node", "bin/npm"},
+			Wrappers:    true,
+		},
+	}
+	for _, stewfilePath := range []string{tomlStewfilePath, lineStewfilePath} {
+		got, err := ReadStewfileContents(stewfilePath)
+		if err != nil {
+			t.Fatalf("ReadStewfileContents() error = %v", err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("ReadStewfileContents() = %v, want %v", got, want)
+		}
+	}
+
+	formatted, err := FormatStewfile(want, "line", false)
+	if err != nil {
+		t.Fatalf("FormatStewfile() error = %v", err)
+	}
+	if wantLine := "nodejs/node entrypoints=bin/node,bin/npm wrappers=true\n"; formatted != wantLine {
+		t.Errorf("FormatStewfile() = %q, want %q", formatted, wantLine)
+	}
+
+	if _, err := ReadStewfileContents(invalidStewfilePath); !reflect.DeepEqual(err, InvalidEntrypointError{Entrypoint: "../bin/node"}) {
+		t.Errorf("ReadStewfileContents() error = %v, want %v", err, InvalidEntrypointError{Entrypoint: "../bin/node"})
+	}
+}
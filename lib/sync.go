@@ -0,0 +1,107 @@
+package stew
+
+import (
+	"slices"
+	"strings"
+)
+
+// SyncPlan contains the changes needed to reconcile the installed packages with a Stewfile
+type SyncPlan struct {
+	Install []PackageData
+	Change  []SyncChange
+	Prune   []PackageData
+}
+
+// SyncChange contains an installed package and the Stewfile package that it should be changed to
+type SyncChange struct {
+	Installed PackageData
+	Desired   PackageData
+}
+
+// IsEmpty checks if the SyncPlan has no changes to make
+func (plan SyncPlan) IsEmpty() bool {
+	return len(plan.Install) == 0 && len(plan.Change) == 0 && len(plan.Prune) == 0
+}
+
+// NewSyncPlan compares the packages in a Stewfile with the installed packages in the lockfile
+func NewSyncPlan(stewfilePkgs []PackageData, lockFile LockFile) SyncPlan {
+	plan := SyncPlan{Install: []PackageData{}, Change: []SyncChange{}, Prune: []PackageData{}}
+	matched := make([]bool, len(lockFile.Packages))
+
+	for _, desired := range stewfilePkgs {
+		indexInLockFile := -1
+		for index, installed := range lockFile.Packages {
+			if !matched[index] && packagesMatch(desired, installed) {
+				indexInLockFile = index
+				break
+			}
+		}
+		if indexInLockFile == -1 {
+			plan.Install = append(plan.Install, desired)
+			continue
+		}
+
+		matched[indexInLockFile] = true
+		installed := lockFile.Packages[indexInLockFile]
+		if packageVersionDiffers(desired, installed) {
+			desired.Binary = installed.Binary
+			plan.Change = append(plan.Change, SyncChange{Installed: installed, Desired: desired})
+		} else if packageLayoutDiffers(desired, installed) {
+			// The installed version is installed again with the new entrypoints
+			desired.Binary = installed.Binary
+			if desired.Tag == "" || desired.Tag == "latest" {
+				desired.Tag = installed.Tag
+			}
+			plan.Change = append(plan.Change, SyncChange{Installed: installed, Desired: desired})
+		}
+	}
+
+	for index, installed := range lockFile.Packages {
+		if !matched[index] {
+			plan.Prune = append(plan.Prune, installed)
+		}
+	}
+
+	return plan
+}
+
+func packagesMatch(desired, installed PackageData) bool {
+	if desired.Source != installed.Source {
+		return false
+	}
+	if desired.Binary != "" && desired.Binary != installed.Binary {
+		return false
+	}
+	switch desired.Source {
+	case "github":
+		return strings.EqualFold(desired.Owner, installed.Owner) && strings.EqualFold(desired.Repo, installed.Repo)
+	default:
+		if desired.URLTemplate != "" {
+			return desired.Binary != "" || desired.URLTemplate == installed.URLTemplate
+		}
+		return desired.Binary != "" || desired.URL == installed.URL
+	}
+}
+
+// packageLayoutDiffers checks if the entrypoints of a directory package changed
+func packageLayoutDiffers(desired, installed PackageData) bool {
+	return !slices.Equal(desired.Entrypoints, installed.Entrypoints) || desired.Wrappers != installed.Wrappers
+}
+
+func packageVersionDiffers(desired, installed PackageData) bool {
+	switch desired.Source {
+	case "github":
+		if desired.Tag != "" && desired.Tag != "latest" && desired.Tag != installed.Tag {
+			return true
+		}
+		return desired.Asset != "" && desired.Asset != installed.Asset
+	default:
+		if desired.URLTemplate != "" {
+			if desired.URLTemplate != installed.URLTemplate {
+				return true
+			}
+			return desired.Tag != "" && desired.Tag != "latest" && desired.Tag != installed.Tag
+		}
+		return desired.URL != installed.URL
+	}
+}
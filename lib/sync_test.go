@@ -0,0 +1,119 @@
+package stew
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNewSyncPlan(t *testing.T) {
+	installedLockFile := LockFile{
+		Os:   "darwin",
+		Arch: "arm64",
+		Packages: []PackageData{
+			{
+				Source: "github",
+				Owner:  "junegunn",
+				Repo:   "fzf",
+				Tag:    "0.29.0",
+				Asset:  "fzf-0.29.0-darwin_arm64.zip",
+				Binary: "fzf",
+			},
+			{
+				Source: "github",
+				Owner:  "BurntSushi",
+				Repo:   "ripgrep",
+				Tag:    "13.0.0",
+				Asset:  "ripgrep-13.0.0-x86_64-apple-darwin.tar.gz",
+				Binary: "rg",
+			},
+			{
+				Source: "other",
+				Asset:  "hyperfine-v1.12.0-x86_64-apple-darwin.tar.gz",
+				Binary: "hyperfine",
+				URL:    "https://github.com/sharkdp/hyperfine/releases/download/v1.12.0/hyperfine-v1.12.0-x86_64-apple-darwin.tar.gz",
+			},
+		},
+	}
+
+	tests := []struct {
+		name         string
+		stewfilePkgs []PackageData
+		want         SyncPlan
+	}{
+		{
+			name: "test1",
+			stewfilePkgs: []PackageData{
+				{Source: "github", Owner: "junegunn", Repo: "fzf"},
+				{Source: "github", Owner: "burntsushi", Repo: "ripgrep", Tag: "13.0.0"},
+				{Source: "other", Asset: "hyperfine-v1.12.0-x86_64-apple-darwin.tar.gz", URL: "https://github.com/sharkdp/hyperfine/releases/download/v1.12.0/hyperfine-v1.12.0-x86_64-apple-darwin.tar.gz"},
+			},
+			want: SyncPlan{Install: []PackageData{}, Change: []SyncChange{}, Prune: []PackageData{}},
+		},
+		{
+			name: "test2",
+			stewfilePkgs: []PackageData{
+				{Source: "github", Owner: "junegunn", Repo: "fzf", Tag: "0.44.1"},
+				{Source: "github", Owner: "marwanhawari", Repo: "ppath", Tag: "v0.0.3"},
+				{Source: "other", Binary: "hyperfine", Asset: "hyperfine-v1.18.0-x86_64-apple-darwin.tar.gz", URL: "https://github.com/sharkdp/hyperfine/releases/download/v1.18.0/hyperfine-v1.18.0-x86_64-apple-darwin.tar.gz"},
+			},
+			want: SyncPlan{
+				Install: []PackageData{
+					{Source: "github", Owner: "marwanhawari", Repo: "ppath", Tag: "v0.0.3"},
+				},
+				Change: []SyncChange{
+					{
+						Installed: installedLockFile.Packages[0],
+						Desired:   PackageData{Source: "github", Owner: "junegunn", Repo: "fzf", Tag: "0.44.1", Binary: "fzf"},
+					},
+					{
+						Installed: installedLockFile.Packages[2],
+						Desired:   PackageData{Source: "other", Binary: "hyperfine", Asset: "hyperfine-v1.18.0-x86_64-apple-darwin.tar.gz", URL: "https://github.com/sharkdp/hyperfine/releases/download/v1.18.0/hyperfine-v1.18.0-x86_64-apple-darwin.tar.gz"},
+					},
+				},
+				Prune: []PackageData{installedLockFile.Packages[1]},
+			},
+		},
+		{
+			name: "test3",
+			stewfilePkgs: []PackageData{
+				{Source: "github", Owner: "BurntSushi", Repo: "ripgrep", Binary: "ripgrep"},
+			},
+			want: SyncPlan{
+				Install: []PackageData{
+					{Source: "github", Owner: "BurntSushi", Repo: "ripgrep", Binary: "ripgrep"},
+				},
+				Change: []SyncChange{},
+				Prune:  installedLockFile.Packages,
+			},
+		},
+		{
+			name: "test4",
+			stewfilePkgs: []PackageData{
+				{Source: "github", Owner: "junegunn", Repo: "fzf", Entrypoints: []string{"bin/fzf"}},
+				{Source: "github", Owner: "burntsushi", Repo: "ripgrep", Tag: "13.0.0"},
+				{Source: "other", Asset: "hyperfine-v1.12.0-x86_64-apple-darwin.tar.gz", URL: "https://github.com/sharkdp/hyperfine/releases/download/v1.12.0/hyperfine-v1.12.0-x86_64-apple-darwin.tar.gz"},
+			},
+			want: SyncPlan{
+				Install: []PackageData{},
+				Change: []SyncChange{
+					{
+						Installed: installedLockFile.Packages[0],
+						Desired:   PackageData{Source: "github", Owner: "junegunn", Repo: "fzf", Tag: "0.29.0", Binary: "fzf", Entrypoints: []string{"bin/fzf"}},
+					},
+				},
+				Prune: []PackageData{},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewSyncPlan(tt.stewfilePkgs, installedLockFile)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("NewSyncPlan() = %v, want %v", got, tt.want)
+			}
+			if got.IsEmpty() != (tt.name == "test1") {
+				t.Errorf("SyncPlan.IsEmpty() = %v", got.IsEmpty())
+			}
+		})
+	}
+}
@@ -0,0 +1,307 @@
+package stew
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// The directories of a .deb or .rpm package that its binaries are found in
+var systemPackageBinaryDirs = []string{"usr/bin", "usr/local/bin", "usr/sbin", "bin", "sbin"}
+
+// The kinds of extra files that can be installed from a .deb or .rpm package
+const (
+	ManPagesExtra    = "manPages"
+	CompletionsExtra = "completions"
+)
+
+// systemPackageExtraDirs maps the directories of the extra files in a .deb or .rpm package to where they are installed in the stewPath
+var systemPackageExtraDirs = []struct {
+	kind   string
+	source string
+	dest   string
+}{
+	{ManPagesExtra, "usr/share/man", "share/man"},
+	{ManPagesExtra, "usr/local/share/man", "share/man"},
+	{CompletionsExtra, "usr/share/bash-completion/completions", "share/bash-completion/completions"},
+	{CompletionsExtra, "etc/bash_completion.d", "share/bash-completion/completions"},
+	{CompletionsExtra, "usr/share/zsh/site-functions", "share/zsh/site-functions"},
+	{CompletionsExtra, "usr/share/zsh/vendor-completions", "share/zsh/site-functions"},
+	{CompletionsExtra, "usr/share/fish/vendor_completions.d", "share/fish/vendor_completions.d"},
+	{CompletionsExtra, "usr/share/fish/completions", "share/fish/vendor_completions.d"},
+}
+
+var systemPackageExtras = map[string]bool{}
+
+// SetSystemPackageExtras sets which extra files are installed from .deb and .rpm packages besides their binaries
+func SetSystemPackageExtras(manPages, completions bool) {
+	systemPackageExtras = map[string]bool{ManPagesExtra: manPages, CompletionsExtra: completions}
+}
+
+// isSystemPackage checks if the name of an asset is a .deb or .rpm package
+func isSystemPackage(filePath string) bool {
+	switch strings.ToLower(filepath.Ext(filePath)) {
+	case ".deb", ".rpm":
+		return true
+	}
+	return false
+}
+
+// isSystemPackageFile checks if a downloaded asset is a .deb or .rpm package from its magic bytes
+func isSystemPackageFile(filePath string) bool {
+	format, err := detectAssetFormat(filePath)
+	return err == nil && (format == debFormat || format == rpmFormat)
+}
+
+// extractSystemPackage extracts the files of a .deb or .rpm package into the destPath, without a system package manager or root
+func extractSystemPackage(filePath, destPath, format string) error {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if format == debFormat {
+		return extractDeb(file, destPath, filepath.Base(filePath))
+	}
+	return extractRPM(file, destPath, filepath.Base(filePath))
+}
+
+// extractDeb extracts the data.tar member of the ar archive of a .deb package
+func extractDeb(file io.Reader, destPath, asset string) error {
+	reader := bufio.NewReader(file)
+	globalHeader := make([]byte, 8)
+	if _, err := io.ReadFull(reader, globalHeader); err != nil || string(globalHeader) != "!<arch>\n" {
+		return InvalidSystemPackageError{Asset: asset, Reason: "it is not an ar archive"}
+	}
+
+	memberHeader := make([]byte, 60)
+	for {
+		if _, err := io.ReadFull(reader, memberHeader); err == io.EOF {
+			return InvalidSystemPackageError{Asset: asset, Reason: "it does not contain a data.tar member"}
+		} else if err != nil {
+			return InvalidSystemPackageError{Asset: asset, Reason: "its ar archive is truncated"}
+		}
+		memberName := strings.TrimSuffix(strings.TrimSpace(string(memberHeader[0:16])), "/")
+		memberSize, err := strconv.ParseInt(strings.TrimSpace(string(memberHeader[48:58])), 10, 64)
+		if err != nil {
+			return InvalidSystemPackageError{Asset: asset, Reason: "its ar archive has an invalid member size"}
+		}
+
+		if strings.HasPrefix(memberName, "data.tar") {
+			payload, err := decompressStream(io.LimitReader(reader, memberSize))
+			if err != nil {
+				return err
+			}
+			defer payload.Close()
+			return extractTarStream(payload, destPath)
+		}
+
+		// The members are aligned to an even offset
+		if _, err = reader.Discard(int(memberSize + memberSize%2)); err != nil {
+			return InvalidSystemPackageError{Asset: asset, Reason: "its ar archive is truncated"}
+		}
+	}
+}
+
+// extractRPM skips the lead and the headers of a .rpm package and extracts its cpio payload
+func extractRPM(file io.Reader, destPath, asset string) error {
+	reader := bufio.NewReader(file)
+	lead := make([]byte, 96)
+	if _, err := io.ReadFull(reader, lead); err != nil || !bytes.Equal(lead[0:4], []byte{0xed, 0xab, 0xee, 0xdb}) {
+		return InvalidSystemPackageError{Asset: asset, Reason: "it does not start with the rpm lead"}
+	}
+
+	// The signature header is padded to a multiple of 8 bytes, but the main header is not
+	for _, alignment := range []int{8, 1} {
+		headerIntro := make([]byte, 16)
+		if _, err := io.ReadFull(reader, headerIntro); err != nil || !bytes.Equal(headerIntro[0:3], []byte{0x8e, 0xad, 0xe8}) {
+			return InvalidSystemPackageError{Asset: asset, Reason: "its header is not valid"}
+		}
+		indexCount := int(binary.BigEndian.Uint32(headerIntro[8:12]))
+		storeSize := int(binary.BigEndian.Uint32(headerIntro[12:16]))
+		headerSize := 16*indexCount + storeSize
+		if padding := (16 + headerSize) % alignment; padding != 0 {
+			headerSize += alignment - padding
+		}
+		if _, err := reader.Discard(headerSize); err != nil {
+			return InvalidSystemPackageError{Asset: asset, Reason: "its header is truncated"}
+		}
+	}
+
+	payload, err := decompressStream(reader)
+	if err != nil {
+		return err
+	}
+	defer payload.Close()
+	return extractCpioStream(payload, destPath, asset)
+}
+
+// maxCpioNameSize is the longest entry name that is read from a cpio header, which is PATH_MAX on linux
+const maxCpioNameSize = 4096
+
+// extractCpioStream extracts the directories, files, and symlinks of a cpio stream in the newc format that rpm uses into the destPath
+func extractCpioStream(stream io.Reader, destPath, asset string) error {
+	reader := bufio.NewReader(stream)
+	header := make([]byte, 110)
+	for {
+		if _, err := io.ReadFull(reader, header); err != nil {
+			return InvalidSystemPackageError{Asset: asset, Reason: "its cpio payload is truncated"}
+		}
+		if magic := string(header[0:6]); magic != "070701" && magic != "070702" {
+			return InvalidSystemPackageError{Asset: asset, Reason: "its payload is not a cpio archive in the newc format"}
+		}
+		var fields [13]int64
+		for index := range fields {
+			field, err := strconv.ParseInt(string(header[6+8*index:14+8*index]), 16, 64)
+			if err != nil {
+				return InvalidSystemPackageError{Asset: asset, Reason: "its cpio payload has an invalid header"}
+			}
+			fields[index] = field
+		}
+		mode, fileSize, nameSize := fields[1], fields[6], fields[11]
+		if nameSize > maxCpioNameSize {
+			return InvalidSystemPackageError{Asset: asset, Reason: "its cpio payload has an entry name that is too long"}
+		}
+
+		// The name and the file contents are both padded to a multiple of 4 bytes
+		name := make([]byte, nameSize+(4-(110+nameSize)%4)%4)
+		if _, err := io.ReadFull(reader, name); err != nil {
+			return InvalidSystemPackageError{Asset: asset, Reason: "its cpio payload is truncated"}
+		}
+		entryName := string(bytes.TrimRight(name[:nameSize], "\x00"))
+		if entryName == "TRAILER!!!" {
+			return nil
+		}
+		contents := io.LimitReader(reader, fileSize)
+
+		var err error
+		switch mode & 0170000 {
+		case 0040000:
+			err = extractDirEntry(destPath, entryName)
+		case 0100000:
+			err = extractFileEntry(destPath, entryName, contents, os.FileMode(mode&0777))
+		case 0120000:
+			var linkTarget []byte
+			if linkTarget, err = io.ReadAll(contents); err == nil {
+				err = extractSymlinkEntry(destPath, entryName, string(linkTarget))
+			}
+		}
+		if err != nil {
+			return err
+		}
+		if _, err = io.Copy(io.Discard, contents); err != nil {
+			return err
+		}
+		if _, err = reader.Discard(int((4 - fileSize%4) % 4)); err != nil {
+			return InvalidSystemPackageError{Asset: asset, Reason: "its cpio payload is truncated"}
+		}
+	}
+}
+
+// systemPackageBinaryPaths returns the files in the binary directories of an extracted .deb or .rpm package.
+// Packages often link their binaries from another directory, so symlinks to files inside of the package are included too.
+func systemPackageBinaryPaths(extractionPath string) ([]string, error) {
+	binaryPaths := []string{}
+	for _, binaryDir := range systemPackageBinaryDirs {
+		binaryDirPath := filepath.Join(extractionPath, filepath.FromSlash(binaryDir))
+		if binaryDirInfo, err := os.Lstat(binaryDirPath); err != nil || !binaryDirInfo.IsDir() {
+			continue
+		}
+		entries, err := os.ReadDir(binaryDirPath)
+		if err != nil {
+			return []string{}, err
+		}
+		for _, entry := range entries {
+			filePath := filepath.Join(binaryDirPath, entry.Name())
+			if fileInfo, err := os.Stat(filePath); err != nil || !fileInfo.Mode().IsRegular() {
+				continue
+			}
+			if entry.Type()&os.ModeSymlink != 0 {
+				if inside, err := resolvesInside(extractionPath, filePath); err != nil || !inside {
+					continue
+				}
+			}
+			binaryPaths = append(binaryPaths, filePath)
+		}
+	}
+	return binaryPaths, nil
+}
+
+// extraFile is a man page or completion of an extracted .deb or .rpm package, and where it is installed relative to the stewPath
+type extraFile struct {
+	sourcePath string
+	destPath   string
+}
+
+// findExtraFiles finds the man pages and completions of an extracted .deb or .rpm package that should be installed
+func findExtraFiles(downloadedFilePath, extractionPath string) ([]extraFile, error) {
+	extraFiles := []extraFile{}
+	if !isSystemPackageFile(downloadedFilePath) {
+		return extraFiles, nil
+	}
+	for _, extraDir := range systemPackageExtraDirs {
+		if !systemPackageExtras[extraDir.kind] {
+			continue
+		}
+		sourceDir := filepath.Join(extractionPath, filepath.FromSlash(extraDir.source))
+		if sourceInfo, err := os.Stat(sourceDir); err != nil || !sourceInfo.IsDir() {
+			continue
+		}
+		if inside, err := resolvesInside(extractionPath, sourceDir); err != nil || !inside {
+			continue
+		}
+		sourcePaths, err := walkDir(sourceDir)
+		if err != nil {
+			return []extraFile{}, err
+		}
+		for _, sourcePath := range sourcePaths {
+			relativePath, err := filepath.Rel(sourceDir, sourcePath)
+			if err != nil {
+				return []extraFile{}, err
+			}
+			extraFiles = append(extraFiles, extraFile{sourcePath: sourcePath, destPath: extraDir.dest + "/" + filepath.ToSlash(relativePath)})
+		}
+	}
+	return extraFiles, nil
+}
+
+// installExtraFiles copies the man pages and completions of a package into the stewPath and returns their paths relative to the stewPath
+func installExtraFiles(extraFiles []extraFile, stewPath string) ([]string, error) {
+	var installedPaths []string
+	for _, extra := range extraFiles {
+		destPath := filepath.Join(stewPath, filepath.FromSlash(extra.destPath))
+		if err := os.MkdirAll(filepath.Dir(destPath), 0755); err != nil {
+			return []string{}, err
+		}
+		sourceFile, err := os.Open(extra.sourcePath)
+		if err != nil {
+			return []string{}, err
+		}
+		err = writeFileAtomic(destPath, sourceFile, 0644)
+		sourceFile.Close()
+		if err != nil {
+			return []string{}, err
+		}
+		installedPaths = append(installedPaths, extra.destPath)
+	}
+	return installedPaths, nil
+}
+
+// removeExtraFiles removes the installed man pages and completions of a package, except for the ones that are kept
+func removeExtraFiles(stewPath string, extraFiles, keptFiles []string) error {
+	for _, extraFilePath := range extraFiles {
+		if _, kept := Contains(keptFiles, extraFilePath); kept {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(stewPath, filepath.FromSlash(extraFilePath))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,356 @@
+package stew
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/ulikunitz/xz"
+)
+
+type testPackageFile struct {
+	name       string
+	contents   string
+	linkTarget string
+}
+
+func testPackageFiles(version string) []testPackageFile {
+	return []testPackageFile{
+		{name: "./"},
+		{name: "./usr/"},
+		{name: "./usr/bin/"},
+		{name: "./usr/bin/tool", linkTarget: "/usr/lib/tool/tool"},
+		{name: "./usr/lib/tool/tool", contents: "tool " + version},
+		{name: "./usr/lib/tool/helper", contents: "helper " + version},
+		{name: "./usr/share/man/man1/tool.1", contents: "manual " + version},
+		{name: "./usr/share/bash-completion/completions/tool", contents: "completion " + version},
+	}
+}
+
+func compressTestPayload(t *testing.T, payload []byte, compression string) []byte {
+	var compressed bytes.Buffer
+	var writer io.WriteCloser
+	var err error
+	switch compression {
+	case "xz":
+		writer, err = xz.NewWriter(&compressed)
+	default:
+		writer = gzip.NewWriter(&compressed)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	writer.Write(payload)
+	writer.Close()
+	return compressed.Bytes()
+}
+
+func newTestDeb(t *testing.T, dirPath, asset string, files []testPackageFile, compression string) string {
+	var data bytes.Buffer
+	tarWriter := tar.NewWriter(&data)
+	for _, file := range files {
+		header := &tar.Header{Name: file.name, Mode: 0755, Typeflag: tar.TypeReg, Size: int64(len(file.contents))}
+		if strings.HasSuffix(file.name, "/") {
+			header.Typeflag = tar.TypeDir
+		} else if file.linkTarget != "" {
+			header.Typeflag = tar.TypeSymlink
+			header.Linkname = file.linkTarget
+		}
+		tarWriter.WriteHeader(header)
+		tarWriter.Write([]byte(file.contents))
+	}
+	tarWriter.Close()
+
+	var deb bytes.Buffer
+	deb.WriteString("!<arch>\n")
+	dataMember := "data.tar.gz"
+	if compression == "xz" {
+		dataMember = "data.tar.xz"
+	}
+	for _, member := range []struct {
+		name     string
+		contents []byte
+	}{
+		{"debian-binary", []byte("2.0\n")},
+		{"control.tar.gz", compressTestPayload(t, []byte("control"), "gz")},
+		{dataMember, compressTestPayload(t, data.Bytes(), compression)},
+	} {
+		fmt.Fprintf(&deb, "%-16s%-12s%-6s%-6s%-8s%-10d`\n", member.name+"/", "0", "0", "0", "100644", len(member.contents))
+		deb.Write(member.contents)
+		if len(member.contents)%2 != 0 {
+			deb.WriteByte('\n')
+		}
+	}
+
+	debPath := filepath.Join(dirPath, asset)
+	os.WriteFile(debPath, deb.Bytes(), 0644)
+	return debPath
+}
+
+func writeTestCpioEntry(cpio *bytes.Buffer, name string, mode int64, contents string) {
+	fmt.Fprintf(cpio, "070701%08x%08x%08x%08x%08x%08x%08x%08x%08x%08x%08x%08x%08x", 0, mode, 0, 0, 1, 0, len(contents), 0, 0, 0, 0, len(name)+1, 0)
+	cpio.WriteString(name + "\x00")
+	cpio.Write(make([]byte, (4-(110+len(name)+1)%4)%4))
+	cpio.WriteString(contents)
+	cpio.Write(make([]byte, (4-len(contents)%4)%4))
+}
+
+func newTestRPM(t *testing.T, dirPath, asset string, files []testPackageFile) string {
+	var cpio bytes.Buffer
+	for _, file := range files {
+		switch {
+		case strings.HasSuffix(file.name, "/"):
+			writeTestCpioEntry(&cpio, strings.TrimSuffix(file.name, "/"), 0040755, "")
+		case file.linkTarget != "":
+			writeTestCpioEntry(&cpio, file.name, 0120777, file.linkTarget)
+		default:
+			writeTestCpioEntry(&cpio, file.name, 0100755, file.contents)
+		}
+	}
+	writeTestCpioEntry(&cpio, "TRAILER!!!", 0, "")
+
+	var rpm bytes.Buffer
+	lead := make([]byte, 96)
+	copy(lead, []byte{0xed, 0xab, 0xee, 0xdb})
+	rpm.Write(lead)
+	// The signature header is padded to 8 bytes, and the main header isn't
+	for index, storeSize := range []int{5, 3} {
+		headerIntro := make([]byte, 16)
+		copy(headerIntro, []byte{0x8e, 0xad, 0xe8, 0x01})
+		binary.BigEndian.PutUint32(headerIntro[8:12], 1)
+		binary.BigEndian.PutUint32(headerIntro[12:16], uint32(storeSize))
+		rpm.Write(headerIntro)
+		rpm.Write(make([]byte, 16+storeSize))
+		if index == 0 {
+			rpm.Write(make([]byte, (8-(32+storeSize)%8)%8))
+		}
+	}
+	rpm.Write(compressTestPayload(t, cpio.Bytes(), "gz"))
+
+	rpmPath := filepath.Join(dirPath, asset)
+	os.WriteFile(rpmPath, rpm.Bytes(), 0644)
+	return rpmPath
+}
+
+func TestExtractSystemPackage(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks need extra privileges on Windows")
+	}
+	escapingFiles := []testPackageFile{
+		{name: "./usr/escape", linkTarget: "../../.."},
+		{name: "./usr/escape/evil", contents: "evil"},
+	}
+	tests := []struct {
+		name    string
+		newPkg  func(dirPath string) string
+		wantErr bool
+	}{
+		{
+			name: "test1",
+			newPkg: func(dirPath string) string {
+				return newTestDeb(t, dirPath, "tool_1.0.0_amd64.deb", testPackageFiles("v1.0.0"), "gz")
+			},
+			wantErr: false,
+		},
+		{
+			name: "test2",
+			newPkg: func(dirPath string) string {
+				return newTestDeb(t, dirPath, "tool_1.0.0_amd64.deb", testPackageFiles("v1.0.0"), "xz")
+			},
+			wantErr: false,
+		},
+		{
+			name: "test3",
+			newPkg: func(dirPath string) string {
+				return newTestRPM(t, dirPath, "tool-1.0.0-1.x86_64.rpm", testPackageFiles("v1.0.0"))
+			},
+			wantErr: false,
+		},
+		{
+			name: "test4",
+			newPkg: func(dirPath string) string {
+				return newTestDeb(t, dirPath, "tool_1.0.0_amd64.deb", escapingFiles, "gz")
+			},
+			wantErr: true,
+		},
+		{
+			name: "test5",
+			newPkg: func(dirPath string) string {
+				return newTestRPM(t, dirPath, "tool-1.0.0-1.x86_64.rpm", escapingFiles)
+			},
+			wantErr: true,
+		},
+		{
+			name: "test6",
+			newPkg: func(dirPath string) string {
+				debPath := filepath.Join(dirPath, "tool_1.0.0_amd64.deb")
+				os.WriteFile(debPath, []byte("not a deb"), 0644)
+				return debPath
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir := t.TempDir()
+			extractionPath := filepath.Join(tempDir, "extracted")
+			os.MkdirAll(extractionPath, 0755)
+
+			pkgPath := tt.newPkg(tempDir)
+			format := debFormat
+			if strings.HasSuffix(pkgPath, ".rpm") {
+				format = rpmFormat
+			}
+			err := extractSystemPackage(pkgPath, extractionPath, format)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("extractSystemPackage() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if exists, _ := PathExists(filepath.Join(tempDir, "evil")); exists {
+					t.Errorf("extractSystemPackage() extracted a file outside of the extraction path")
+				}
+				return
+			}
+
+			for path, want := range map[string]string{
+				"usr/bin/tool":              "tool v1.0.0",
+				"usr/lib/tool/helper":       "helper v1.0.0",
+				"usr/share/man/man1/tool.1": "manual v1.0.0",
+			} {
+				if got, _ := os.ReadFile(filepath.Join(extractionPath, path)); string(got) != want {
+					t.Errorf("extractSystemPackage() %v = %v, want %v", path, string(got), want)
+				}
+			}
+			if linkTarget, _ := os.Readlink(filepath.Join(extractionPath, "usr", "bin", "tool")); filepath.IsAbs(linkTarget) {
+				t.Errorf("extractSystemPackage() kept the absolute symlink target %v", linkTarget)
+			}
+		})
+	}
+}
+
+func Test_extractCpioStream_LongName(t *testing.T) {
+	var cpio bytes.Buffer
+	fmt.Fprintf(&cpio, "070701%08x%08x%08x%08x%08x%08x%08x%08x%08x%08x%08x%08x%08x", 0, 0100755, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0xffffffff, 0)
+
+	err := extractCpioStream(&cpio, t.TempDir(), "tool.rpm")
+	if _, ok := err.(InvalidSystemPackageError); !ok {
+		t.Errorf("extractCpioStream() error = %v, want InvalidSystemPackageError", err)
+	}
+}
+
+func Test_findBinary_SystemPackage(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks need extra privileges on Windows")
+	}
+	tempDir := t.TempDir()
+	extractionPath := filepath.Join(tempDir, "extracted")
+	os.MkdirAll(extractionPath, 0755)
+	debPath := newTestDeb(t, tempDir, "tool_1.0.0_amd64.deb", testPackageFiles("v1.0.0"), "gz")
+	if err := extractBinary(debPath, extractionPath, ""); err != nil {
+		t.Fatalf("extractBinary() error = %v", err)
+	}
+
+	binaryPath, binaryName, binaryHash, err := findBinary(debPath, extractionPath, "", "")
+	if err != nil {
+		t.Fatalf("findBinary() error = %v", err)
+	}
+	if want := filepath.Join(extractionPath, "usr", "bin", "tool"); binaryPath != want {
+		t.Errorf("findBinary() path = %v, want %v", binaryPath, want)
+	}
+	if binaryName != "tool" {
+		t.Errorf("findBinary() name = %v, want tool", binaryName)
+	}
+	if wantHash, _ := CalculateFileHash(filepath.Join(extractionPath, "usr", "lib", "tool", "tool")); binaryHash != wantHash {
+		t.Errorf("findBinary() hash = %v, want %v", binaryHash, wantHash)
+	}
+}
+
+func TestInstallBinary_SystemPackage(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks need extra privileges on Windows")
+	}
+	SetSystemPackageExtras(true, false)
+	t.Cleanup(func() { SetSystemPackageExtras(false, false) })
+
+	systemInfo := newTestTransactionSystemInfo(t)
+	rpmPath := newTestRPM(t, systemInfo.StewPkgPath, "tool-1.0.0-1.x86_64.rpm", testPackageFiles("v1.0.0"))
+	lockFile := LockFile{Os: "linux", Arch: "amd64", Packages: []PackageData{}}
+
+	binaryName, _, extraFiles, err := InstallBinary(rpmPath, "tool", "v1.0.0", systemInfo, &lockFile, false, "", "", nil, false)
+	if err != nil {
+		t.Fatalf("InstallBinary() error = %v", err)
+	}
+	if binaryName != "tool" {
+		t.Errorf("InstallBinary() binary = %v, want tool", binaryName)
+	}
+	if got, _ := os.ReadFile(filepath.Join(systemInfo.StewBinPath, "tool")); string(got) != "tool v1.0.0" {
+		t.Errorf("InstallBinary() binary contents = %v, want tool v1.0.0", string(got))
+	}
+	if want := []string{"share/man/man1/tool.1"}; !reflect.DeepEqual(extraFiles, want) {
+		t.Errorf("InstallBinary() extra files = %v, want %v", extraFiles, want)
+	}
+	if got, _ := os.ReadFile(filepath.Join(systemInfo.StewPath, "share", "man", "man1", "tool.1")); string(got) != "manual v1.0.0" {
+		t.Errorf("InstallBinary() man page = %v, want manual v1.0.0", string(got))
+	}
+	if exists, _ := PathExists(filepath.Join(systemInfo.StewPath, "share", "bash-completion")); exists {
+		t.Errorf("InstallBinary() installed the completions without the completions setting")
+	}
+
+	pkg := PackageData{Tag: "v1.0.0", Asset: filepath.Base(rpmPath), Binary: binaryName, ExtraFiles: extraFiles}
+	if err = DeletePackage(systemInfo, pkg); err != nil {
+		t.Fatalf("DeletePackage() error = %v", err)
+	}
+	if exists, _ := PathExists(filepath.Join(systemInfo.StewPath, "share", "man", "man1", "tool.1")); exists {
+		t.Errorf("DeletePackage() did not remove the man page")
+	}
+}
+
+func TestInstallStagedPackages_SystemPackage(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks need extra privileges on Windows")
+	}
+	SetSystemPackageExtras(true, true)
+	t.Cleanup(func() { SetSystemPackageExtras(false, false) })
+
+	systemInfo := newTestTransactionSystemInfo(t)
+	for _, version := range []string{"v1.0.0", "v1.1.0"} {
+		files := testPackageFiles(version)
+		if version == "v1.1.0" {
+			// The new version doesn't have completions anymore
+			files = files[:len(files)-1]
+		}
+		stagingPath := filepath.Join(systemInfo.StewTmpPath, "staged", version)
+		os.MkdirAll(stagingPath, 0755)
+		debPath := newTestDeb(t, stagingPath, "tool_"+version+"_amd64.deb", files, "gz")
+		stagedPkg, err := StagePackage(PackageData{Source: "github", Owner: "marwanhawari", Repo: "tool", Tag: version, Asset: filepath.Base(debPath)}, debPath, stagingPath)
+		if err != nil {
+			t.Fatalf("StagePackage() error = %v", err)
+		}
+		if err = InstallStagedPackages([]StagedPackage{stagedPkg}, systemInfo, "linux", "amd64"); err != nil {
+			t.Fatalf("InstallStagedPackages() error = %v", err)
+		}
+	}
+
+	if got, _ := os.ReadFile(filepath.Join(systemInfo.StewBinPath, "tool")); string(got) != "tool v1.1.0" {
+		t.Errorf("InstallStagedPackages() binary = %v, want tool v1.1.0", string(got))
+	}
+	if got, _ := os.ReadFile(filepath.Join(systemInfo.StewPath, "share", "man", "man1", "tool.1")); string(got) != "manual v1.1.0" {
+		t.Errorf("InstallStagedPackages() man page = %v, want manual v1.1.0", string(got))
+	}
+	if exists, _ := PathExists(filepath.Join(systemInfo.StewPath, "share", "bash-completion", "completions", "tool")); exists {
+		t.Errorf("InstallStagedPackages() did not remove the previous completion")
+	}
+	lockFile, _ := ReadLockFileJSON(systemInfo.StewLockFilePath)
+	if want := []string{"share/man/man1/tool.1"}; len(lockFile.Packages) != 1 || !reflect.DeepEqual(lockFile.Packages[0].ExtraFiles, want) {
+		t.Errorf("InstallStagedPackages() lockfile = %v, want the extra files %v", lockFile, want)
+	}
+}
@@ -0,0 +1,306 @@
+package stew
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// StagedPackage contains a package whose asset has been downloaded and whose binary has been extracted and verified, but not installed yet
+type StagedPackage struct {
+	Package    PackageData
+	AssetPath  string
+	BinaryPath string
+	// ExtractionPath contains every extracted file, which is moved to the package store in the symlink mode.
+	// It is the package root for a directory package.
+	ExtractionPath string
+	// extraFiles are the man pages and completions of a .deb or .rpm package
+	extraFiles []extraFile
+}
+
+// StagePackage extracts the binary from a downloaded asset into the stagingPath and verifies its hash without installing it
+func StagePackage(pkg PackageData, downloadedFilePath, stagingPath string) (StagedPackage, error) {
+	tmpExtractionPath := filepath.Join(stagingPath, "extracted")
+	if err := os.MkdirAll(tmpExtractionPath, 0755); err != nil {
+		return StagedPackage{}, err
+	}
+	if err := extractBinary(downloadedFilePath, tmpExtractionPath, pkg.Binary); err != nil {
+		return StagedPackage{}, err
+	}
+	extraFiles, err := findExtraFiles(downloadedFilePath, tmpExtractionPath)
+	if err != nil {
+		return StagedPackage{}, err
+	}
+
+	if IsDirectoryPackage(pkg) {
+		rootPath, binaryPath, binaryName, binaryHash, err := findEntrypoints(tmpExtractionPath, pkg.Binary, pkg.Entrypoints, pkg.BinaryHash)
+		if err != nil {
+			return StagedPackage{}, err
+		}
+		pkg.Binary = binaryName
+		pkg.BinaryHash = binaryHash
+		return StagedPackage{Package: pkg, AssetPath: downloadedFilePath, BinaryPath: binaryPath, ExtractionPath: rootPath, extraFiles: extraFiles}, nil
+	}
+
+	binaryPath, binaryName, binaryHash, err := findBinary(downloadedFilePath, tmpExtractionPath, pkg.Binary, pkg.BinaryHash)
+	if err != nil {
+		return StagedPackage{}, err
+	}
+
+	pkg.Binary = binaryName
+	pkg.BinaryHash = binaryHash
+	return StagedPackage{Package: pkg, AssetPath: downloadedFilePath, BinaryPath: binaryPath, ExtractionPath: tmpExtractionPath, extraFiles: extraFiles}, nil
+}
+
+// InstallStagedPackages installs all of the staged packages together and adds them to the lockfile.
+// If any of them can't be installed, the previous binaries, assets, and lockfile are restored.
+// The installed packages, with their extra files, are written back to stagedPkgs.
+func InstallStagedPackages(stagedPkgs []StagedPackage, systemInfo SystemInfo, userOS, userArch string) error {
+	stewLockFilePath := systemInfo.StewLockFilePath
+	backupPath := filepath.Join(systemInfo.StewTmpPath, "backup")
+
+	lockFile, err := NewLockFile(stewLockFilePath, userOS, userArch)
+	if err != nil {
+		return err
+	}
+
+	var rollbacks []func() error
+	rollback := func(err error) error {
+		for index := len(rollbacks) - 1; index >= 0; index-- {
+			if rollbackErr := rollbacks[index](); rollbackErr != nil {
+				return RollbackError{Err: err, RollbackErr: rollbackErr}
+			}
+		}
+		return err
+	}
+
+	restoreLockFile, err := backupFile(stewLockFilePath, filepath.Join(backupPath, "lockfile"))
+	if err != nil {
+		return err
+	}
+	rollbacks = append(rollbacks, restoreLockFile)
+
+	previousAssetPaths := []string{}
+	previousStorePaths := []string{}
+	staleEntrypoints := []string{}
+	staleExtraFiles := []string{}
+	for index, stagedPkg := range stagedPkgs {
+		pkg := stagedPkg.Package
+		pkgBackupPath := filepath.Join(backupPath, strconv.Itoa(index))
+
+		previousStorePath, err := installedStorePath(lockFile, systemInfo.StewPkgPath, systemInfo.StewBinPath, pkg.Binary)
+		if err != nil {
+			return rollback(err)
+		}
+		indexInLockFile, binaryFoundInLockFile := FindBinaryInLockFile(lockFile, pkg.Binary)
+		if binaryFoundInLockFile {
+			staleEntrypoints = append(staleEntrypoints, staleEntrypointPaths(systemInfo.StewBinPath, lockFile.Packages[indexInLockFile], pkg)...)
+		}
+
+		binaryInstallPath := filepath.Join(systemInfo.StewBinPath, pkg.Binary)
+		restoreBinary, err := backupFile(binaryInstallPath, filepath.Join(pkgBackupPath, "binary"))
+		if err != nil {
+			return rollback(err)
+		}
+		rollbacks = append(rollbacks, restoreBinary)
+
+		// The extra files are copied before the extracted files are moved to the package store
+		for extraIndex, extra := range stagedPkg.extraFiles {
+			restoreExtraFile, err := backupFile(filepath.Join(systemInfo.StewPath, filepath.FromSlash(extra.destPath)), filepath.Join(pkgBackupPath, "extra-"+strconv.Itoa(extraIndex)))
+			if err != nil {
+				return rollback(err)
+			}
+			rollbacks = append(rollbacks, restoreExtraFile)
+		}
+		pkg.ExtraFiles, err = installExtraFiles(stagedPkg.extraFiles, systemInfo.StewPath)
+		if err != nil {
+			return rollback(err)
+		}
+		if binaryFoundInLockFile {
+			for _, extraFilePath := range lockFile.Packages[indexInLockFile].ExtraFiles {
+				if _, kept := Contains(pkg.ExtraFiles, extraFilePath); !kept {
+					staleExtraFiles = append(staleExtraFiles, extraFilePath)
+				}
+			}
+		}
+
+		if IsDirectoryPackage(pkg) || IsSymlinkMode() {
+			storePath := PackageStorePath(systemInfo.StewPkgPath, pkg.Binary, pkg.Tag)
+			restoreStorePath, err := backupDir(storePath, filepath.Join(pkgBackupPath, "store"))
+			if err != nil {
+				return rollback(err)
+			}
+			rollbacks = append(rollbacks, restoreStorePath)
+
+			if IsDirectoryPackage(pkg) {
+				for entrypointIndex, entrypointPath := range EntrypointPaths(systemInfo.StewBinPath, pkg) {
+					if entrypointPath == binaryInstallPath {
+						continue
+					}
+					restoreEntrypoint, err := backupFile(entrypointPath, filepath.Join(pkgBackupPath, "entrypoint-"+strconv.Itoa(entrypointIndex)))
+					if err != nil {
+						return rollback(err)
+					}
+					rollbacks = append(rollbacks, restoreEntrypoint)
+				}
+				_, err = linkPackage(stagedPkg.ExtractionPath, systemInfo.StewPkgPath, systemInfo.StewBinPath, pkg)
+			} else {
+				_, err = linkBinary(stagedPkg.ExtractionPath, stagedPkg.BinaryPath, systemInfo.StewPkgPath, systemInfo.StewBinPath, pkg.Binary, pkg.Tag)
+			}
+			if err != nil {
+				return rollback(err)
+			}
+			if previousStorePath != "" && previousStorePath != storePath {
+				previousStorePaths = append(previousStorePaths, previousStorePath)
+			}
+		} else {
+			assetInstallPath := filepath.Join(systemInfo.StewPkgPath, pkg.Asset)
+			restoreAsset, err := backupFile(assetInstallPath, filepath.Join(pkgBackupPath, "asset"))
+			if err != nil {
+				return rollback(err)
+			}
+			rollbacks = append(rollbacks, restoreAsset)
+
+			if err = copyFile(stagedPkg.BinaryPath, binaryInstallPath); err != nil {
+				return rollback(err)
+			}
+			if err = copyFile(stagedPkg.AssetPath, assetInstallPath); err != nil {
+				return rollback(err)
+			}
+			if previousStorePath != "" {
+				previousStorePaths = append(previousStorePaths, previousStorePath)
+			}
+		}
+
+		if binaryFoundInLockFile {
+			if previousAsset := lockFile.Packages[indexInLockFile].Asset; previousAsset != pkg.Asset && previousStorePath == "" {
+				previousAssetPaths = append(previousAssetPaths, filepath.Join(systemInfo.StewPkgPath, previousAsset))
+			}
+			lockFile.Packages[indexInLockFile] = pkg
+		} else {
+			lockFile.Packages = append(lockFile.Packages, pkg)
+		}
+		stagedPkgs[index].Package = pkg
+	}
+
+	if err = WriteLockFileJSON(lockFile, stewLockFilePath); err != nil {
+		return rollback(err)
+	}
+
+	// The previous assets are only removed once every binary is in place and no other package uses them
+	for _, previousAssetPath := range previousAssetPaths {
+		if assetInLockFile(lockFile, filepath.Base(previousAssetPath)) {
+			continue
+		}
+		if IsSymlinkMode() && storeInLockFile(lockFile, filepath.Base(previousAssetPath)) {
+			continue
+		}
+		if err = os.RemoveAll(previousAssetPath); err != nil {
+			return err
+		}
+	}
+	for _, previousStorePath := range previousStorePaths {
+		if err = removeStorePath(previousStorePath); err != nil {
+			return err
+		}
+	}
+	for _, entrypointPath := range staleEntrypoints {
+		if err = os.RemoveAll(entrypointPath); err != nil {
+			return err
+		}
+	}
+	if err = removeExtraFiles(systemInfo.StewPath, staleExtraFiles, []string{}); err != nil {
+		return err
+	}
+
+	return os.RemoveAll(backupPath)
+}
+
+func assetInLockFile(lockFile LockFile, asset string) bool {
+	for _, pkg := range lockFile.Packages {
+		if pkg.Asset == asset {
+			return true
+		}
+	}
+	return false
+}
+
+// storeInLockFile checks if a package in the lockfile has a binary that is stored in the package store under the name
+func storeInLockFile(lockFile LockFile, name string) bool {
+	for _, pkg := range lockFile.Packages {
+		if pkg.Binary == name {
+			return true
+		}
+	}
+	return false
+}
+
+// backupFile copies filePath to backupFilePath and returns a function that restores it.
+// A symlink is restored with its previous target. If filePath doesn't exist, the returned function removes it instead.
+func backupFile(filePath, backupFilePath string) (func() error, error) {
+	linkTarget, err := readBinaryLink(filePath)
+	if err != nil {
+		return nil, err
+	}
+	if linkTarget != "" {
+		return func() error {
+			return replaceLink(linkTarget, filePath)
+		}, nil
+	}
+
+	fileExists, err := PathExists(filePath)
+	if err != nil {
+		return nil, err
+	}
+	if !fileExists {
+		return func() error {
+			return os.RemoveAll(filePath)
+		}, nil
+	}
+
+	fileInfo, err := os.Stat(filePath)
+	if err != nil {
+		return nil, err
+	}
+	if err = os.MkdirAll(filepath.Dir(backupFilePath), 0755); err != nil {
+		return nil, err
+	}
+	if err = copyFile(filePath, backupFilePath); err != nil {
+		return nil, err
+	}
+	return func() error {
+		backupContents, err := os.Open(backupFilePath)
+		if err != nil {
+			return err
+		}
+		defer backupContents.Close()
+		return writeFileAtomic(filePath, backupContents, fileInfo.Mode().Perm())
+	}, nil
+}
+
+// backupDir moves dirPath to backupDirPath and returns a function that moves it back.
+// If dirPath doesn't exist, the returned function removes it instead.
+func backupDir(dirPath, backupDirPath string) (func() error, error) {
+	dirExists, err := PathExists(dirPath)
+	if err != nil {
+		return nil, err
+	}
+	if !dirExists {
+		return func() error {
+			return os.RemoveAll(dirPath)
+		}, nil
+	}
+
+	if err = os.MkdirAll(filepath.Dir(backupDirPath), 0755); err != nil {
+		return nil, err
+	}
+	if err = os.Rename(dirPath, backupDirPath); err != nil {
+		return nil, err
+	}
+	return func() error {
+		if err := os.RemoveAll(dirPath); err != nil {
+			return err
+		}
+		return os.Rename(backupDirPath, dirPath)
+	}, nil
+}
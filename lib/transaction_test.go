@@ -0,0 +1,148 @@
+package stew
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+func newTestTransactionSystemInfo(t *testing.T) SystemInfo {
+	tempDir := t.TempDir()
+	systemInfo := SystemInfo{
+		StewPath:         tempDir,
+		StewBinPath:      filepath.Join(tempDir, "bin"),
+		StewPkgPath:      filepath.Join(tempDir, "pkg"),
+		StewLockFilePath: filepath.Join(tempDir, "Stewfile.lock.json"),
+		StewTmpPath:      filepath.Join(tempDir, "tmp"),
+	}
+	for _, dirPath := range []string{systemInfo.StewBinPath, systemInfo.StewPkgPath, systemInfo.StewTmpPath} {
+		os.MkdirAll(dirPath, 0755)
+	}
+	return systemInfo
+}
+
+func newTestStagedPackage(t *testing.T, systemInfo SystemInfo, binary, asset, contents string) StagedPackage {
+	stagingPath := filepath.Join(systemInfo.StewTmpPath, "staged", binary)
+	os.MkdirAll(stagingPath, 0755)
+	assetPath := filepath.Join(stagingPath, asset)
+	binaryPath := filepath.Join(stagingPath, binary)
+	os.WriteFile(assetPath, []byte(contents), 0644)
+	os.WriteFile(binaryPath, []byte(contents), 0755)
+	return StagedPackage{
+		Package:    PackageData{Source: "github", Owner: "marwanhawari", Repo: binary, Tag: "v1.0.0", Asset: asset, Binary: binary},
+		AssetPath:  assetPath,
+		BinaryPath: binaryPath,
+	}
+}
+
+func TestInstallStagedPackages(t *testing.T) {
+	systemInfo := newTestTransactionSystemInfo(t)
+
+	previousLockFile := LockFile{Os: "linux", Arch: "amd64", Packages: []PackageData{
+		{Source: "github", Owner: "marwanhawari", Repo: "ppath", Tag: "v0.0.2", Asset: "ppath-v0.0.2-linux-amd64.tar.gz", Binary: "ppath"},
+	}}
+	WriteLockFileJSON(previousLockFile, systemInfo.StewLockFilePath)
+	os.WriteFile(filepath.Join(systemInfo.StewBinPath, "ppath"), []byte("old"), 0755)
+	os.WriteFile(filepath.Join(systemInfo.StewPkgPath, "ppath-v0.0.2-linux-amd64.tar.gz"), []byte("old"), 0644)
+
+	stagedPkgs := []StagedPackage{
+		newTestStagedPackage(t, systemInfo, "ppath", "ppath-v0.0.3-linux-amd64.tar.gz", "new"),
+		newTestStagedPackage(t, systemInfo, "ls-go", "ls-go-v0.0.1-linux-amd64.tar.gz", "new"),
+	}
+
+	err := InstallStagedPackages(stagedPkgs, systemInfo, "linux", "amd64")
+	if err != nil {
+		t.Fatalf("InstallStagedPackages() error = %v", err)
+	}
+
+	for _, binary := range []string{"ppath", "ls-go"} {
+		if got, _ := os.ReadFile(filepath.Join(systemInfo.StewBinPath, binary)); string(got) != "new" {
+			t.Errorf("InstallStagedPackages() binary %v = %v, want new", binary, string(got))
+		}
+	}
+	if exists, _ := PathExists(filepath.Join(systemInfo.StewPkgPath, "ppath-v0.0.2-linux-amd64.tar.gz")); exists {
+		t.Errorf("InstallStagedPackages() did not remove the previous asset")
+	}
+
+	got, _ := ReadLockFileJSON(systemInfo.StewLockFilePath)
+	want := LockFile{Os: "linux", Arch: "amd64", Packages: []PackageData{stagedPkgs[0].Package, stagedPkgs[1].Package}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("InstallStagedPackages() lockfile = %v, want %v", got, want)
+	}
+}
+
+func TestInstallStagedPackages_Rollback(t *testing.T) {
+	systemInfo := newTestTransactionSystemInfo(t)
+
+	previousLockFile := LockFile{Os: "linux", Arch: "amd64", Packages: []PackageData{
+		{Source: "github", Owner: "marwanhawari", Repo: "ppath", Tag: "v0.0.2", Asset: "ppath-v0.0.2-linux-amd64.tar.gz", Binary: "ppath"},
+	}}
+	WriteLockFileJSON(previousLockFile, systemInfo.StewLockFilePath)
+	os.WriteFile(filepath.Join(systemInfo.StewBinPath, "ppath"), []byte("old"), 0755)
+	os.WriteFile(filepath.Join(systemInfo.StewPkgPath, "ppath-v0.0.2-linux-amd64.tar.gz"), []byte("old"), 0644)
+
+	failingPkg := newTestStagedPackage(t, systemInfo, "ls-go", "ls-go-v0.0.1-linux-amd64.tar.gz", "new")
+	failingPkg.BinaryPath = filepath.Join(systemInfo.StewTmpPath, "missing")
+	stagedPkgs := []StagedPackage{
+		newTestStagedPackage(t, systemInfo, "ppath", "ppath-v0.0.3-linux-amd64.tar.gz", "new"),
+		failingPkg,
+	}
+
+	err := InstallStagedPackages(stagedPkgs, systemInfo, "linux", "amd64")
+	if err == nil {
+		t.Fatalf("InstallStagedPackages() error = nil, want an error")
+	}
+
+	if got, _ := os.ReadFile(filepath.Join(systemInfo.StewBinPath, "ppath")); string(got) != "old" {
+		t.Errorf("InstallStagedPackages() did not restore the ppath binary, got %v", string(got))
+	}
+	for _, path := range []string{
+		filepath.Join(systemInfo.StewBinPath, "ls-go"),
+		filepath.Join(systemInfo.StewPkgPath, "ppath-v0.0.3-linux-amd64.tar.gz"),
+	} {
+		if exists, _ := PathExists(path); exists {
+			t.Errorf("InstallStagedPackages() did not remove %v", path)
+		}
+	}
+	if exists, _ := PathExists(filepath.Join(systemInfo.StewPkgPath, "ppath-v0.0.2-linux-amd64.tar.gz")); !exists {
+		t.Errorf("InstallStagedPackages() removed the previous asset")
+	}
+
+	got, _ := ReadLockFileJSON(systemInfo.StewLockFilePath)
+	if !reflect.DeepEqual(got, previousLockFile) {
+		t.Errorf("InstallStagedPackages() lockfile = %v, want %v", got, previousLockFile)
+	}
+}
+
+func TestInstallStagedPackages_Symlink(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks need extra privileges on windows")
+	}
+	SetLinkMode(SymlinkLinkMode)
+	defer SetLinkMode(CopyLinkMode)
+	systemInfo := newTestTransactionSystemInfo(t)
+	previousStorePath := newTestLinkedBinary(t, systemInfo, "ppath", "v0.0.2", "old")
+	WriteLockFileJSON(LockFile{Os: "linux", Arch: "amd64", Packages: []PackageData{
+		{Source: "github", Owner: "marwanhawari", Repo: "ppath", Tag: "v0.0.2", Asset: "ppath-v0.0.2-linux-amd64.tar.gz", Binary: "ppath"},
+	}}, systemInfo.StewLockFilePath)
+
+	stagedPkg := newTestStagedPackage(t, systemInfo, "ppath", "ppath-v0.0.3-linux-amd64.tar.gz", "new")
+	stagedPkg.ExtractionPath = filepath.Dir(stagedPkg.BinaryPath)
+	err := InstallStagedPackages([]StagedPackage{stagedPkg}, systemInfo, "linux", "amd64")
+	if err != nil {
+		t.Fatalf("InstallStagedPackages() error = %v", err)
+	}
+
+	if got, _ := os.ReadFile(filepath.Join(systemInfo.StewBinPath, "ppath")); string(got) != "new" {
+		t.Errorf("InstallStagedPackages() binary = %v, want new", string(got))
+	}
+	wantStorePath := filepath.Join(systemInfo.StewPkgPath, "ppath", "v1.0.0")
+	if got, _ := LinkedStorePath(systemInfo.StewPkgPath, systemInfo.StewBinPath, "ppath"); got != wantStorePath {
+		t.Errorf("InstallStagedPackages() store path = %v, want %v", got, wantStorePath)
+	}
+	if exists, _ := PathExists(previousStorePath); exists {
+		t.Errorf("InstallStagedPackages() did not remove the previous store path")
+	}
+}
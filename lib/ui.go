@@ -1,19 +1,64 @@
 package stew
 
 import (
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
 	"github.com/AlecAivazis/survey/v2"
 )
 
+var nonInteractive bool
+
+// SetNonInteractive turns the non-interactive mode on or off. Prompts fail in the non-interactive mode instead of waiting for input.
+func SetNonInteractive(enabled bool) {
+	nonInteractive = enabled
+}
+
+// IsNonInteractive checks if stew is running in the non-interactive mode
+func IsNonInteractive() bool {
+	return nonInteractive
+}
+
+// FuzzyFilter checks if the characters of the filter appear in order in the value, ignoring case and spaces.
+// It is used to filter the options of the selection UI as you type.
+func FuzzyFilter(filter string, value string, index int) bool {
+	value = strings.ToLower(value)
+	for _, filterRune := range strings.ToLower(filter) {
+		if unicode.IsSpace(filterRune) {
+			continue
+		}
+		runeIndex := strings.IndexRune(value, filterRune)
+		if runeIndex == -1 {
+			return false
+		}
+		value = value[runeIndex+utf8.RuneLen(filterRune):]
+	}
+	return true
+}
+
 // PromptSelect launches the selection UI
 func PromptSelect(message string, options []string) (string, error) {
+	return PromptSelectDefault(message, options, "")
+}
+
+// PromptSelectDefault launches the selection UI with the cursor on the default option
+func PromptSelectDefault(message string, options []string, defaultOption string) (string, error) {
+	if nonInteractive {
+		return "", NonInteractiveError{Prompt: message}
+	}
 	result := ""
 	prompt := &survey.Select{
 		Message: message,
 		Options: options,
 	}
-	err := survey.AskOne(prompt, &result, survey.WithIcons(func(icons *survey.IconSet) {
+	if defaultOption != "" {
+		prompt.Default = defaultOption
+	}
+	err := survey.AskOne(prompt, &result, survey.WithStdio(os.Stdin, humanOutput, os.Stderr), survey.WithIcons(func(icons *survey.IconSet) {
 		icons.Question.Text = "*"
-	}))
+	}), survey.WithFilter(FuzzyFilter))
 	if err != nil {
 		return "", ExitUserSelectionError{Err: err}
 	}
@@ -23,13 +68,16 @@ func PromptSelect(message string, options []string) (string, error) {
 
 // PromptMultiSelect launches the multiple selection UI
 func PromptMultiSelect(message string, options []string, defaultSelections []string) ([]string, error) {
+	if nonInteractive {
+		return []string{}, NonInteractiveError{Prompt: message}
+	}
 	result := []string{}
 	prompt := &survey.MultiSelect{
 		Message: message,
 		Options: options,
 		Default: defaultSelections,
 	}
-	err := survey.AskOne(prompt, &result, survey.WithIcons(func(icons *survey.IconSet) {
+	err := survey.AskOne(prompt, &result, survey.WithStdio(os.Stdin, humanOutput, os.Stderr), survey.WithIcons(func(icons *survey.IconSet) {
 		icons.Question.Text = "*"
 	}))
 	if err != nil {
@@ -41,12 +89,15 @@ func PromptMultiSelect(message string, options []string, defaultSelections []str
 
 // PromptInput launches the input UI
 func PromptInput(message string, defaultInput string) (string, error) {
+	if nonInteractive {
+		return "", NonInteractiveError{Prompt: message}
+	}
 	result := ""
 	prompt := &survey.Input{
 		Message: message,
 		Default: defaultInput,
 	}
-	err := survey.AskOne(prompt, &result, survey.WithIcons(func(icons *survey.IconSet) {
+	err := survey.AskOne(prompt, &result, survey.WithStdio(os.Stdin, humanOutput, os.Stderr), survey.WithIcons(func(icons *survey.IconSet) {
 		icons.Question.Text = "*"
 	}))
 	if err != nil {
@@ -58,12 +109,15 @@ func PromptInput(message string, defaultInput string) (string, error) {
 
 // WarningPromptSelect launches the selection UI with a warning styling
 func WarningPromptSelect(message string, options []string) (string, error) {
+	if nonInteractive {
+		return "", NonInteractiveError{Prompt: message}
+	}
 	result := ""
 	prompt := &survey.Select{
 		Message: message,
 		Options: options,
 	}
-	err := survey.AskOne(prompt, &result, survey.WithIcons(func(icons *survey.IconSet) {
+	err := survey.AskOne(prompt, &result, survey.WithStdio(os.Stdin, humanOutput, os.Stderr), survey.WithIcons(func(icons *survey.IconSet) {
 		icons.Question.Text = "!"
 		icons.Question.Format = "yellow+hb"
 	}))
@@ -76,11 +130,14 @@ func WarningPromptSelect(message string, options []string) (string, error) {
 
 // WarningPromptConfirm launches the confirm UI with a warning styling
 func WarningPromptConfirm(message string) (bool, error) {
+	if nonInteractive {
+		return false, NonInteractiveError{Prompt: message}
+	}
 	result := false
 	prompt := &survey.Confirm{
 		Message: message,
 	}
-	err := survey.AskOne(prompt, &result, survey.WithIcons(func(icons *survey.IconSet) {
+	err := survey.AskOne(prompt, &result, survey.WithStdio(os.Stdin, humanOutput, os.Stderr), survey.WithIcons(func(icons *survey.IconSet) {
 		icons.Question.Text = "!"
 		icons.Question.Format = "yellow+hb"
 	}))
@@ -93,12 +150,15 @@ func WarningPromptConfirm(message string) (bool, error) {
 
 // warningPromptInput launches the input UI with a warning styling
 func warningPromptInput(message string, defaultInput string) (string, error) {
+	if nonInteractive {
+		return "", NonInteractiveError{Prompt: message}
+	}
 	result := ""
 	prompt := &survey.Input{
 		Message: message,
 		Default: defaultInput,
 	}
-	err := survey.AskOne(prompt, &result, survey.WithIcons(func(icons *survey.IconSet) {
+	err := survey.AskOne(prompt, &result, survey.WithStdio(os.Stdin, humanOutput, os.Stderr), survey.WithIcons(func(icons *survey.IconSet) {
 		icons.Question.Text = "!"
 		icons.Question.Format = "yellow+hb"
 	}))
@@ -0,0 +1,44 @@
+package stew
+
+import "testing"
+
+func TestFuzzyFilter(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter string
+		value  string
+		want   bool
+	}{
+		{
+			name:   "test1",
+			filter: "lnxamd",
+			value:  "ppath-v0.0.3-Linux-amd64.tar.gz",
+			want:   true,
+		},
+		{
+			name:   "test2",
+			filter: "linux arm",
+			value:  "ppath-v0.0.3-linux-arm64.tar.gz",
+			want:   true,
+		},
+		{
+			name:   "test3",
+			filter: "amdlinux",
+			value:  "ppath-v0.0.3-linux-amd64.tar.gz",
+			want:   false,
+		},
+		{
+			name:   "test4",
+			filter: "",
+			value:  "ppath-v0.0.3-linux-amd64.tar.gz",
+			want:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FuzzyFilter(tt.filter, tt.value, 0); got != tt.want {
+				t.Errorf("FuzzyFilter() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
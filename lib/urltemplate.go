@@ -0,0 +1,242 @@
+package stew
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// VersionSource describes where the latest version of a package installed from a URL template is found.
+// The text type uses the whole response, the json type uses the value at a dotted JSONPath like "data.0.version",
+// and the html type uses the highest version matched by a regex, such as the entries of a directory listing.
+type VersionSource struct {
+	Type     string `json:"type" toml:"type"`
+	URL      string `json:"url" toml:"url"`
+	JSONPath string `json:"jsonPath,omitempty" toml:"jsonPath,omitempty"`
+	Regex    string `json:"regex,omitempty" toml:"regex,omitempty"`
+}
+
+// URLTemplateData contains the values that can be used in a URL template
+type URLTemplateData struct {
+	Version string
+	// VersionNumber is the version without a leading v
+	VersionNumber string
+	OS            string
+	Arch          string
+}
+
+// IsURLTemplate checks if a URL contains template actions like {{.Version}}
+func IsURLTemplate(url string) bool {
+	return strings.Contains(url, "{{")
+}
+
+// RenderURLTemplate renders a URL template for a version and platform
+func RenderURLTemplate(urlTemplate, version, userOS, userArch string) (string, error) {
+	tmpl, err := template.New("url").Option("missingkey=error").Parse(urlTemplate)
+	if err != nil {
+		return "", InvalidURLTemplateError{Template: urlTemplate, Err: err}
+	}
+	var url strings.Builder
+	data := URLTemplateData{Version: version, VersionNumber: strings.TrimPrefix(version, "v"), OS: userOS, Arch: userArch}
+	if err = tmpl.Execute(&url, data); err != nil {
+		return "", InvalidURLTemplateError{Template: urlTemplate, Err: err}
+	}
+	return url.String(), nil
+}
+
+// NewVersionSource creates a version source from the CLI flags. The type is json if there is a JSON path, html if there is a regex, and text otherwise.
+func NewVersionSource(url, jsonPath, regex string) (*VersionSource, error) {
+	if url == "" {
+		if jsonPath != "" || regex != "" {
+			return nil, InvalidVersionSourceError{Reason: "the version URL is missing"}
+		}
+		return nil, nil
+	}
+	versionSource := VersionSource{Type: "text", URL: url, JSONPath: jsonPath, Regex: regex}
+	switch {
+	case jsonPath != "":
+		versionSource.Type = "json"
+	case regex != "":
+		versionSource.Type = "html"
+	}
+	if err := versionSource.Validate(); err != nil {
+		return nil, err
+	}
+	return &versionSource, nil
+}
+
+// Validate checks that the version source has the settings that its type needs
+func (v VersionSource) Validate() error {
+	if v.URL == "" {
+		return InvalidVersionSourceError{Reason: "the version URL is missing"}
+	}
+	switch v.Type {
+	case "text":
+	case "json":
+		if v.JSONPath == "" {
+			return InvalidVersionSourceError{Reason: "the json type needs a JSON path"}
+		}
+	case "html":
+		if v.Regex == "" {
+			return InvalidVersionSourceError{Reason: "the html type needs a regex"}
+		}
+	default:
+		return InvalidVersionSourceError{Reason: fmt.Sprintf("the type %v is not text, json, or html", v.Type)}
+	}
+	if v.Regex != "" {
+		if _, err := regexp.Compile(v.Regex); err != nil {
+			return InvalidVersionSourceError{Reason: err.Error()}
+		}
+	}
+	return nil
+}
+
+// FindLatestVersion finds the latest version of a package from its version source
+func FindLatestVersion(versionSource VersionSource) (string, error) {
+	if err := versionSource.Validate(); err != nil {
+		return "", err
+	}
+	body, err := getHTTPResponseBody(versionSource.URL)
+	if err != nil {
+		return "", err
+	}
+	version, err := ParseVersion(versionSource, body)
+	if err != nil {
+		return "", err
+	}
+	if version == "" {
+		return "", VersionNotFoundError{URL: versionSource.URL}
+	}
+	return version, nil
+}
+
+// ParseVersion finds the version in the response of a version source. It returns an empty version if there isn't one.
+func ParseVersion(versionSource VersionSource, body string) (string, error) {
+	switch versionSource.Type {
+	case "json":
+		var value any
+		if err := json.Unmarshal([]byte(body), &value); err != nil {
+			return "", err
+		}
+		for _, key := range strings.Split(versionSource.JSONPath, ".") {
+			switch typedValue := value.(type) {
+			case map[string]any:
+				value = typedValue[key]
+			case []any:
+				index, err := strconv.Atoi(key)
+				if err != nil || index < 0 || index >= len(typedValue) {
+					return "", nil
+				}
+				value = typedValue[index]
+			default:
+				return "", nil
+			}
+		}
+		switch typedValue := value.(type) {
+		case string:
+			return strings.TrimSpace(typedValue), nil
+		case float64:
+			return strconv.FormatFloat(typedValue, 'f', -1, 64), nil
+		}
+		return "", nil
+	case "html":
+		re := regexp.MustCompile(versionSource.Regex)
+		latestVersion := ""
+		for _, match := range re.FindAllStringSubmatch(body, -1) {
+			version := match[0]
+			if len(match) > 1 {
+				version = match[1]
+			}
+			if latestVersion == "" || CompareVersions(version, latestVersion) > 0 {
+				latestVersion = version
+			}
+		}
+		return latestVersion, nil
+	default:
+		version := strings.TrimSpace(body)
+		if versionSource.Regex != "" {
+			match := regexp.MustCompile(versionSource.Regex).FindStringSubmatch(version)
+			if len(match) == 0 {
+				return "", nil
+			}
+			version = match[len(match)-1]
+		}
+		return version, nil
+	}
+}
+
+var versionNumberRegex = regexp.MustCompile(`\d+`)
+
+// prereleaseVersionRegex splits a version like v1.30.0-rc.1+build.5 into its prefix, its numbers, and its prerelease
+var prereleaseVersionRegex = regexp.MustCompile(`^(\D*)(\d+(?:\.\d+)*)(?:-([0-9A-Za-z.-]+))?(?:\+[0-9A-Za-z.-]+)?$`)
+
+// CompareVersions compares the numbers in two versions one by one, so that 1.10.0 is newer than 1.9.2.
+// A prerelease like 1.30.0-rc.1 is older than the release 1.30.0.
+// Versions with the same numbers are the same, so that v1.2.3 and 1.2.3 are equal.
+// It returns a positive number if a is newer, a negative number if b is newer, and 0 if they are the same.
+func CompareVersions(a, b string) int {
+	aMatch := prereleaseVersionRegex.FindStringSubmatch(a)
+	bMatch := prereleaseVersionRegex.FindStringSubmatch(b)
+	if aMatch == nil || bMatch == nil {
+		return compareVersionNumbers(a, b)
+	}
+	if result := compareVersionNumbers(aMatch[2], bMatch[2]); result != 0 {
+		return result
+	}
+	aPrerelease, bPrerelease := aMatch[3], bMatch[3]
+	switch {
+	case aPrerelease == "" && bPrerelease == "":
+		return 0
+	case aPrerelease == "":
+		return 1
+	case bPrerelease == "":
+		return -1
+	}
+	return comparePrereleases(aPrerelease, bPrerelease)
+}
+
+// comparePrereleases compares the dot separated identifiers of two prereleases like semver does, so that rc.2 is newer than rc.1 and beta.2.
+// Identifiers with letters are compared by their numbers first, so that rc10 is newer than rc9.
+func comparePrereleases(a, b string) int {
+	aIdentifiers := strings.Split(a, ".")
+	bIdentifiers := strings.Split(b, ".")
+	for index := 0; index < len(aIdentifiers) && index < len(bIdentifiers); index++ {
+		aNumber, aErr := strconv.Atoi(aIdentifiers[index])
+		bNumber, bErr := strconv.Atoi(bIdentifiers[index])
+		switch {
+		case aErr == nil && bErr == nil:
+			if aNumber != bNumber {
+				return aNumber - bNumber
+			}
+		case aErr == nil:
+			return -1
+		case bErr == nil:
+			return 1
+		default:
+			if result := compareVersionNumbers(aIdentifiers[index], bIdentifiers[index]); result != 0 {
+				return result
+			}
+		}
+	}
+	return len(aIdentifiers) - len(bIdentifiers)
+}
+
+// compareVersionNumbers compares the numbers in two versions one by one and falls back to comparing the versions as strings
+func compareVersionNumbers(a, b string) int {
+	aNumbers := versionNumberRegex.FindAllString(a, -1)
+	bNumbers := versionNumberRegex.FindAllString(b, -1)
+	for index := 0; index < len(aNumbers) && index < len(bNumbers); index++ {
+		aNumber, _ := strconv.Atoi(aNumbers[index])
+		bNumber, _ := strconv.Atoi(bNumbers[index])
+		if aNumber != bNumber {
+			return aNumber - bNumber
+		}
+	}
+	if len(aNumbers) != len(bNumbers) {
+		return len(aNumbers) - len(bNumbers)
+	}
+	return strings.Compare(a, b)
+}
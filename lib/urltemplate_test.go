@@ -0,0 +1,225 @@
+package stew
+
+import "testing"
+
+func TestRenderURLTemplate(t *testing.T) {
+	tests := []struct {
+		name        string
+		urlTemplate string
+		want        string
+		wantErr     bool
+	}{
+		{
+			name:        "test1",
+			urlTemplate: "https://dl.example.com/tool/{{.Version}}/tool_{{.OS}}_{{.Arch}}.tar.gz",
+			want:        "https://dl.example.com/tool/v1.2.0/tool_linux_amd64.tar.gz",
+		},
+		{
+			name:        "test2",
+			urlTemplate: `https://dl.example.com/tool-{{.VersionNumber}}-{{if eq .Arch "amd64"}}x86_64{{else}}{{.Arch}}{{end}}.zip`,
+			want:        "https://dl.example.com/tool-1.2.0-x86_64.zip",
+		},
+		{
+			name:        "test3",
+			urlTemplate: "https://dl.example.com/{{.Verison}}/tool",
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RenderURLTemplate(tt.urlTemplate, "v1.2.0", "linux", "amd64")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RenderURLTemplate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("RenderURLTemplate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewVersionSource(t *testing.T) {
+	tests := []struct {
+		name     string
+		url      string
+		jsonPath string
+		regex    string
+		wantType string
+		wantErr  bool
+	}{
+		{
+			name:     "test1",
+			url:      "https://dl.k8s.io/release/stable.txt",
+			wantType: "text",
+		},
+		{
+			name:     "test2",
+			url:      "https://api.example.com/latest",
+			jsonPath: "data.version",
+			wantType: "json",
+		},
+		{
+			name:     "test3",
+			url:      "https://dl.example.com/tool/",
+			regex:    `href="(v[0-9.]+)/"`,
+			wantType: "html",
+		},
+		{
+			name:    "test4",
+			regex:   `v[0-9.]+`,
+			wantErr: true,
+		},
+		{
+			name:    "test5",
+			url:     "https://dl.example.com/tool/",
+			regex:   `v[0-9.+`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewVersionSource(tt.url, tt.jsonPath, tt.regex)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewVersionSource() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got.Type != tt.wantType {
+				t.Errorf("NewVersionSource() type = %v, want %v", got.Type, tt.wantType)
+			}
+		})
+	}
+}
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		name          string
+		versionSource VersionSource
+		body          string
+		want          string
+	}{
+		{
+			name:          "test1",
+			versionSource: VersionSource{Type: "text"},
+			body:          "v1.29.2\n",
+			want:          "v1.29.2",
+		},
+		{
+			name:          "test2",
+			versionSource: VersionSource{Type: "json", JSONPath: "data.1.version"},
+			body:          `{"data": [{"version": "1.0.0"}, {"version": "2.0.0"}]}`,
+			want:          "2.0.0",
+		},
+		{
+			name:          "test3",
+			versionSource: VersionSource{Type: "json", JSONPath: "data.version"},
+			body:          `{"data": []}`,
+			want:          "",
+		},
+		{
+			name:          "test4",
+			versionSource: VersionSource{Type: "html", Regex: `href="(v[0-9.]+)/"`},
+			body:          `<a href="v1.9.2/">v1.9.2/</a> <a href="v1.10.0/">v1.10.0/</a> <a href="v1.2.0/">v1.2.0/</a>`,
+			want:          "v1.10.0",
+		},
+		{
+			name:          "test5",
+			versionSource: VersionSource{Type: "html", Regex: `href="(v[0-9.]+(?:-[a-z0-9.]+)?)/"`},
+			body:          `<a href="v1.30.0/">v1.30.0/</a> <a href="v1.30.0-rc.1/">v1.30.0-rc.1/</a> <a href="v1.29.3/">v1.29.3/</a>`,
+			want:          "v1.30.0",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseVersion(tt.versionSource, tt.body)
+			if err != nil {
+				t.Fatalf("ParseVersion() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseVersion() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		name string
+		a    string
+		b    string
+		want int
+	}{
+		{
+			name: "test1",
+			a:    "v1.10.0",
+			b:    "v1.9.2",
+			want: 1,
+		},
+		{
+			name: "test2",
+			a:    "1.2",
+			b:    "1.2.1",
+			want: -1,
+		},
+		{
+			name: "test3",
+			a:    "v2.0.0",
+			b:    "v2.0.0",
+			want: 0,
+		},
+		{
+			name: "test4",
+			a:    "1.30.0-rc.1",
+			b:    "1.30.0",
+			want: -1,
+		},
+		{
+			name: "test5",
+			a:    "v1.30.0-rc.2",
+			b:    "v1.30.0-rc.1",
+			want: 1,
+		},
+		{
+			name: "test6",
+			a:    "v1.30.0-rc10",
+			b:    "v1.30.0-rc9",
+			want: 1,
+		},
+		{
+			name: "test7",
+			a:    "v1.30.0-beta.2",
+			b:    "v1.30.0-rc.1",
+			want: -1,
+		},
+		{
+			name: "test8",
+			a:    "v1.31.0-alpha.1",
+			b:    "v1.30.0",
+			want: 1,
+		},
+		{
+			name: "test9",
+			a:    "jq-1.7.1",
+			b:    "jq-1.7",
+			want: 1,
+		},
+		{
+			name: "test10",
+			a:    "1.2.3",
+			b:    "v1.2.3",
+			want: 0,
+		},
+		{
+			name: "test11",
+			a:    "v0.6.0",
+			b:    "0.6.0",
+			want: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := CompareVersions(tt.a, tt.b)
+			if (got > 0) != (tt.want > 0) || (got < 0) != (tt.want < 0) {
+				t.Errorf("CompareVersions() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
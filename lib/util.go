@@ -7,6 +7,7 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"runtime"
 	"strings"
 
 	"github.com/marwanhawari/stew/constants"
@@ -33,6 +34,8 @@ func isExecutableFile(filePath string) (bool, error) {
 func CatchAndExit(err error) {
 	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
+		EmitResult(NewErrorResult("", nil, err))
+		FlushResults()
 		os.Exit(1)
 	}
 }
@@ -53,21 +56,13 @@ func PathExists(path string) (bool, error) {
 func DownloadFile(downloadPath string, url string) error {
 	sp := constants.LoadingSpinner
 	sp.Start()
-	client := &http.Client{}
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := newGetRequest(url, "application/octet-stream")
 	if err != nil {
+		sp.Stop()
 		return err
 	}
 
-	if strings.Contains(url, "api.github.com") {
-		req.Header.Add("Accept", "application/octet-stream")
-		githubToken := os.Getenv("GITHUB_TOKEN")
-		if githubToken != "" {
-			req.Header.Add("Authorization", fmt.Sprintf("token %v", githubToken))
-		}
-	}
-
-	resp, err := client.Do(req)
+	resp, err := httpClient.Do(req)
 	sp.Stop()
 
 	if err != nil {
@@ -80,49 +75,84 @@ func DownloadFile(downloadPath string, url string) error {
 		return NonZeroStatusCodeDownloadError{StatusCode: resp.StatusCode}
 	}
 
-	outputFile, err := os.Create(downloadPath)
-	if err != nil {
-		return err
-	}
-	defer outputFile.Close()
-
 	bar := progressbar.DefaultBytes(
 		resp.ContentLength,
 		"⬇️  Downloading asset:",
 	)
-	_, err = io.Copy(io.MultiWriter(outputFile, bar), resp.Body)
+	return writeFileAtomic(downloadPath, io.TeeReader(resp.Body, bar), 0644)
+}
+
+func copyFile(srcFile, destFile string) error {
+	srcContents, err := os.Open(srcFile)
 	if err != nil {
 		return err
 	}
+	defer srcContents.Close()
 
-	_, err = io.Copy(outputFile, resp.Body)
+	return writeFileAtomic(destFile, srcContents, 0755)
+}
+
+// writeFileAtomic writes the contents to a temporary file in the same directory, syncs it, then renames it to the destination.
+// The destination is either left untouched or completely replaced.
+func writeFileAtomic(destFile string, contents io.Reader, perm os.FileMode) error {
+	destDir := filepath.Dir(destFile)
+	tmpFile, err := os.CreateTemp(destDir, "."+filepath.Base(destFile)+".tmp-*")
 	if err != nil {
 		return err
 	}
+	tmpFilePath := tmpFile.Name()
+	defer os.Remove(tmpFilePath)
 
-	return nil
+	if _, err = io.Copy(tmpFile, contents); err != nil {
+		tmpFile.Close()
+		return err
+	}
+	if err = tmpFile.Sync(); err != nil {
+		tmpFile.Close()
+		return err
+	}
+	if err = tmpFile.Close(); err != nil {
+		return err
+	}
+	if err = os.Chmod(tmpFilePath, perm); err != nil {
+		return err
+	}
 
+	if err = replaceFile(tmpFilePath, destFile); err != nil {
+		return err
+	}
+
+	return syncDir(destDir)
 }
 
-func copyFile(srcFile, destFile string) error {
-	srcContents, err := os.Open(srcFile)
-	if err != nil {
+// replaceFile renames srcFile to destFile. A running executable can't be replaced on Windows, so it is moved aside first.
+func replaceFile(srcFile, destFile string) error {
+	err := os.Rename(srcFile, destFile)
+	if err == nil || runtime.GOOS != "windows" {
 		return err
 	}
-	defer srcContents.Close()
 
-	destContents, err := os.Create(destFile)
-	if err != nil {
+	oldDestFile := destFile + ".old"
+	if err := os.RemoveAll(oldDestFile); err != nil {
 		return err
 	}
-	defer destContents.Close()
+	if err := os.Rename(destFile, oldDestFile); err != nil {
+		return err
+	}
+	return os.Rename(srcFile, destFile)
+}
 
-	_, err = io.Copy(destContents, srcContents)
+// syncDir makes sure that a rename in the directory is persisted. Directories can't be synced on Windows.
+func syncDir(dirPath string) error {
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+	dir, err := os.Open(dirPath)
 	if err != nil {
 		return err
 	}
-
-	return os.Chmod(destFile, 0755)
+	defer dir.Close()
+	return dir.Sync()
 }
 
 func walkDir(rootDir string) ([]string, error) {
@@ -168,6 +198,15 @@ func getBinary(filePaths []string, desiredBinaryRename, expectedBinaryHash strin
 	}
 
 	if len(executableFiles) != 1 {
+		if nonInteractive {
+			// Fall back to the executable matching the desired binary name
+			for _, executableFile := range executableFiles {
+				if desiredBinaryRename != "" && executableFile.fileName == desiredBinaryRename {
+					return executableFile.filePath, executableFile.fileName, executableFile.fileHash, nil
+				}
+			}
+			return "", "", "", NonInteractiveError{Prompt: "Could not automatically detect the binary", Resolution: "Name the binary with binary:owner/repo in a Stewfile or install from a Stewfile.lock.json"}
+		}
 		binaryFilePath, err := WarningPromptSelect("Could not automatically detect the binary. Please select it manually:", filePaths)
 		if err != nil {
 			return "", "", "", err
@@ -192,6 +231,21 @@ func getBinary(filePaths []string, desiredBinaryRename, expectedBinaryHash strin
 	return executableFiles[0].filePath, executableFiles[0].fileName, executableFiles[0].fileHash, nil
 }
 
+// findBinary finds the binary in the extracted files of an asset. Only the binary directories of a .deb or .rpm package are searched.
+func findBinary(downloadedFilePath, extractionPath, desiredBinaryRename, expectedBinaryHash string) (string, string, string, error) {
+	var allFilePaths []string
+	var err error
+	if isSystemPackageFile(downloadedFilePath) {
+		allFilePaths, err = systemPackageBinaryPaths(extractionPath)
+	} else {
+		allFilePaths, err = walkDir(extractionPath)
+	}
+	if err != nil {
+		return "", "", "", err
+	}
+	return getBinary(allFilePaths, desiredBinaryRename, expectedBinaryHash)
+}
+
 // ValidateCLIInput makes sure the CLI input isn't empty
 func ValidateCLIInput(cliInput string) error {
 	if cliInput == "" {
@@ -260,6 +314,9 @@ func parseGithubInput(cliInput string) (PackageData, error) {
 }
 
 func parseURLInput(cliInput string) (PackageData, error) {
+	if IsURLTemplate(cliInput) {
+		return PackageData{Source: "other", URLTemplate: cliInput}, nil
+	}
 	return PackageData{Source: "other", Asset: filepath.Base(cliInput), URL: cliInput}, nil
 }
 
@@ -283,47 +340,139 @@ func FindBinaryInLockFile(lockFile LockFile, binaryName string) (int, bool) {
 }
 
 func extractBinary(downloadedFilePath, tmpExtractionPath, desiredBinaryRename string) error {
-	isArchive := isArchiveFile(downloadedFilePath)
-	if isArchive {
-		err := archiver.Unarchive(downloadedFilePath, tmpExtractionPath)
+	isArchive, err := extractAsset(downloadedFilePath, tmpExtractionPath)
+	if err != nil || isArchive {
+		return err
+	}
+	format, err := detectAssetFormat(downloadedFilePath)
+	if err != nil {
+		return err
+	}
+	// A single file compressed binary is named without its compression extension
+	originalBinaryName := filepath.Base(downloadedFilePath)
+	if format == compressedFormat {
+		originalBinaryName = decompressedName(downloadedFilePath)
+	}
+	renamedBinaryName := desiredBinaryRename
+	if renamedBinaryName == "" {
+		renamedBinaryName, err = PromptRenameBinary(originalBinaryName)
 		if err != nil {
 			return err
 		}
-		return nil
 	}
-	originalBinaryName := filepath.Base(downloadedFilePath)
-	if desiredBinaryRename != "" {
-		return copyFile(downloadedFilePath, filepath.Join(tmpExtractionPath, desiredBinaryRename))
-	}
-	renamedBinaryName, err := PromptRenameBinary(originalBinaryName)
-	if err != nil {
-		return err
+	if format == compressedFormat {
+		return decompressFile(downloadedFilePath, filepath.Join(tmpExtractionPath, renamedBinaryName))
 	}
 	return copyFile(downloadedFilePath, filepath.Join(tmpExtractionPath, renamedBinaryName))
 }
 
-// InstallBinary will extract the binary and copy it to the ~/.stew/bin path
-func InstallBinary(downloadedFilePath string, repo string, systemInfo SystemInfo, lockFile *LockFile, overwriteFromUpgrade bool, desiredBinaryRename, expectedBinaryHash string) (string, string, error) {
-	tmpExtractionPath, stewPkgPath, binaryInstallPath := systemInfo.StewTmpPath, systemInfo.StewPkgPath, systemInfo.StewBinPath
+// InstallBinary will extract the binary and copy it to the ~/.stew/bin path.
+// In the symlink mode the extracted files are moved to the package store for the tag instead, and the binary is linked there.
+// A directory package with entrypoints is always moved to the package store, and each of its entrypoints is linked.
+// It returns the name and hash of the binary, and the man pages and completions that were installed from a .deb or .rpm package.
+func InstallBinary(downloadedFilePath string, repo string, tag string, systemInfo SystemInfo, lockFile *LockFile, overwriteFromUpgrade bool, desiredBinaryRename, expectedBinaryHash string, entrypoints []string, wrappers bool) (string, string, []string, error) {
+	stewPkgPath, binaryInstallPath := systemInfo.StewPkgPath, systemInfo.StewBinPath
+	tmpExtractionPath := filepath.Join(systemInfo.StewTmpPath, "extracted")
+	if err := os.MkdirAll(tmpExtractionPath, 0755); err != nil {
+		return "", "", []string{}, err
+	}
 	if err := extractBinary(downloadedFilePath, tmpExtractionPath, desiredBinaryRename); err != nil {
-		return "", "", err
+		return "", "", []string{}, err
 	}
 
-	allFilePaths, err := walkDir(tmpExtractionPath)
+	var packageRootPath, binaryFileInTmpExtractionPath, binaryName, binaryHash string
+	var err error
+	if len(entrypoints) > 0 {
+		packageRootPath, binaryFileInTmpExtractionPath, binaryName, binaryHash, err = findEntrypoints(tmpExtractionPath, desiredBinaryRename, entrypoints, expectedBinaryHash)
+	} else {
+		binaryFileInTmpExtractionPath, binaryName, binaryHash, err = findBinary(downloadedFilePath, tmpExtractionPath, desiredBinaryRename, expectedBinaryHash)
+	}
 	if err != nil {
-		return "", "", err
+		return "", "", []string{}, err
 	}
+	pkg := PackageData{Tag: tag, Binary: binaryName, Entrypoints: entrypoints, Wrappers: wrappers}
 
-	binaryFileInTmpExtractionPath, binaryName, binaryHash, err := getBinary(allFilePaths, desiredBinaryRename, expectedBinaryHash)
+	// The previous install is found before its lockfile entry can be removed
+	var previousPkg PackageData
+	if indexInLockFile, binaryFoundInLockFile := FindBinaryInLockFile(*lockFile, binaryName); binaryFoundInLockFile {
+		previousPkg = lockFile.Packages[indexInLockFile]
+	}
+	previousStorePath, err := installedStorePath(*lockFile, stewPkgPath, binaryInstallPath, binaryName)
 	if err != nil {
-		return "", "", err
+		return "", "", []string{}, err
+	}
+	previousAssetPath, err := handleExistingBinary(lockFile, binaryName, downloadedFilePath, stewPkgPath, overwriteFromUpgrade)
+	if err != nil {
+		return "", "", []string{}, err
+	}
+
+	// The extra files are copied before the extracted files are moved to the package store
+	extraFiles, err := findExtraFiles(downloadedFilePath, tmpExtractionPath)
+	if err != nil {
+		return "", "", []string{}, err
+	}
+	installedExtraFiles, err := installExtraFiles(extraFiles, systemInfo.StewPath)
+	if err != nil {
+		return "", "", []string{}, err
+	}
+
+	var storePath string
+	switch {
+	case IsDirectoryPackage(pkg):
+		storePath, err = linkPackage(packageRootPath, stewPkgPath, binaryInstallPath, pkg)
+	case IsSymlinkMode():
+		storePath, err = linkBinary(tmpExtractionPath, binaryFileInTmpExtractionPath, stewPkgPath, binaryInstallPath, binaryName, tag)
+	default:
+		err = copyFile(binaryFileInTmpExtractionPath, filepath.Join(binaryInstallPath, binaryName))
 	}
+	if err != nil {
+		return "", "", []string{}, err
+	}
+	for _, entrypointPath := range staleEntrypointPaths(binaryInstallPath, previousPkg, pkg) {
+		if err = os.RemoveAll(entrypointPath); err != nil {
+			return "", "", []string{}, err
+		}
+	}
+	if err = removeExtraFiles(systemInfo.StewPath, previousPkg.ExtraFiles, installedExtraFiles); err != nil {
+		return "", "", []string{}, err
+	}
+
+	// The previous asset or package store directory is only removed once the new binary is in place
+	if previousStorePath != "" && previousStorePath != storePath {
+		if err = removeStorePath(previousStorePath); err != nil {
+			return "", "", []string{}, err
+		}
+	} else if previousStorePath == "" && previousAssetPath != "" && previousAssetPath != filepath.Dir(storePath) {
+		if err = os.RemoveAll(previousAssetPath); err != nil {
+			return "", "", []string{}, err
+		}
+	}
+
+	err = os.RemoveAll(tmpExtractionPath)
+	if err != nil {
+		return "", "", []string{}, err
+	}
+
+	return binaryName, binaryHash, installedExtraFiles, nil
+}
 
-	if err = handleExistingBinary(lockFile, binaryName, downloadedFilePath, stewPkgPath, overwriteFromUpgrade); err != nil {
+// GetBinaryFromAsset will extract the binary from a downloaded asset and return its name and hash without installing it.
+// The binary of a directory package is its first entrypoint.
+func GetBinaryFromAsset(downloadedFilePath, tmpExtractionPath, desiredBinaryRename string, entrypoints []string) (string, string, error) {
+	if err := os.MkdirAll(tmpExtractionPath, 0755); err != nil {
+		return "", "", err
+	}
+	if err := extractBinary(downloadedFilePath, tmpExtractionPath, desiredBinaryRename); err != nil {
 		return "", "", err
 	}
 
-	err = copyFile(binaryFileInTmpExtractionPath, filepath.Join(binaryInstallPath, binaryName))
+	var binaryName, binaryHash string
+	var err error
+	if len(entrypoints) > 0 {
+		_, _, binaryName, binaryHash, err = findEntrypoints(tmpExtractionPath, desiredBinaryRename, entrypoints, "")
+	} else {
+		_, binaryName, binaryHash, err = findBinary(downloadedFilePath, tmpExtractionPath, desiredBinaryRename, "")
+	}
 	if err != nil {
 		return "", "", err
 	}
@@ -336,37 +485,46 @@ func InstallBinary(downloadedFilePath string, repo string, systemInfo SystemInfo
 	return binaryName, binaryHash, nil
 }
 
-func handleExistingBinary(lockFile *LockFile, binaryName, newlyDownloadedAssetPath, stewPkgPath string, overwriteFromUpgrade bool) error {
+func handleExistingBinary(lockFile *LockFile, binaryName, newlyDownloadedAssetPath, stewPkgPath string, overwriteFromUpgrade bool) (string, error) {
 	indexInLockFile, binaryFoundInLockFile := FindBinaryInLockFile(*lockFile, binaryName)
 	if !binaryFoundInLockFile {
-		return nil
+		return "", nil
 	}
-	pkg := lockFile.Packages[indexInLockFile]
 	if !overwriteFromUpgrade {
-		userChoosingToOverwrite, err := WarningPromptConfirm(fmt.Sprintf("The binary %v version: %v is already installed, would you like to overwrite it?", constants.YellowColor(binaryName), constants.YellowColor(pkg.Tag)))
-		if err != nil {
+		if err := ConfirmBinaryOverwrite(*lockFile, binaryName); err != nil {
 			if err := os.RemoveAll(newlyDownloadedAssetPath); err != nil {
-				return err
+				return "", err
 			}
-			return err
-		}
-		if !userChoosingToOverwrite {
-			if err := os.RemoveAll(newlyDownloadedAssetPath); err != nil {
-				return err
-			}
-			return AbortBinaryOverwriteError{Binary: binaryName}
+			return "", err
 		}
 	}
 	return overwriteBinary(lockFile, indexInLockFile, newlyDownloadedAssetPath, stewPkgPath, overwriteFromUpgrade)
 }
 
-func overwriteBinary(lockFile *LockFile, indexInLockFile int, newlyDownloadedAssetPath, stewPkgPath string, overwriteFromUpgrade bool) error {
+// ConfirmBinaryOverwrite asks whether a binary that is already installed should be overwritten.
+// The existing binary is always overwritten in the non-interactive mode.
+func ConfirmBinaryOverwrite(lockFile LockFile, binaryName string) error {
+	indexInLockFile, binaryFoundInLockFile := FindBinaryInLockFile(lockFile, binaryName)
+	if !binaryFoundInLockFile || nonInteractive {
+		return nil
+	}
+	pkg := lockFile.Packages[indexInLockFile]
+	userChoosingToOverwrite, err := WarningPromptConfirm(fmt.Sprintf("The binary %v version: %v is already installed, would you like to overwrite it?", constants.YellowColor(binaryName), constants.YellowColor(pkg.Tag)))
+	if err != nil {
+		return err
+	}
+	if !userChoosingToOverwrite {
+		return AbortBinaryOverwriteError{Binary: binaryName}
+	}
+	return nil
+}
+
+// overwriteBinary returns the path of the previous asset that should be removed once the new binary is installed
+func overwriteBinary(lockFile *LockFile, indexInLockFile int, newlyDownloadedAssetPath, stewPkgPath string, overwriteFromUpgrade bool) (string, error) {
 	pkg := lockFile.Packages[indexInLockFile]
-	previousAssetPath := filepath.Join(stewPkgPath, pkg.Asset)
-	if previousAssetPath != newlyDownloadedAssetPath {
-		if err := os.RemoveAll(previousAssetPath); err != nil {
-			return err
-		}
+	var previousAssetPath string
+	if assetPath := filepath.Join(stewPkgPath, pkg.Asset); assetPath != newlyDownloadedAssetPath {
+		previousAssetPath = assetPath
 	}
 	// If not overwriting as part of an upgrade, remove the package entry from the lock file
 	// This is because the upgrade command will update the package entry in place
@@ -375,14 +533,18 @@ func overwriteBinary(lockFile *LockFile, indexInLockFile int, newlyDownloadedAss
 		var err error
 		lockFile.Packages, err = RemovePackage(lockFile.Packages, indexInLockFile)
 		if err != nil {
-			return err
+			return "", err
 		}
 	}
-	return nil
+	return previousAssetPath, nil
 }
 
 // PromptRenameBinary takes in the original name of the binary and will return the new name of the binary.
+// The original name is kept in the non-interactive mode.
 func PromptRenameBinary(originalBinaryName string) (string, error) {
+	if nonInteractive {
+		return originalBinaryName, nil
+	}
 	renamedBinaryName, err := warningPromptInput("Rename the binary?", originalBinaryName)
 	if err != nil {
 		return "", err
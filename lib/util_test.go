@@ -1,9 +1,12 @@
 package stew
 
 import (
+	"errors"
+	"io"
 	"os"
 	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -586,6 +589,17 @@ func Test_parseURLInput(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "test2",
+			args: args{
+				cliInput: "https://dl.k8s.io/release/{{.Version}}/bin/{{.OS}}/{{.Arch}}/kubectl",
+			},
+			want: PackageData{
+				Source:      "other",
+				URLTemplate: "https://dl.k8s.io/release/{{.Version}}/bin/{{.OS}}/{{.Arch}}/kubectl",
+			},
+			wantErr: false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -727,7 +741,7 @@ func TestInstallBinary(t *testing.T) {
 				t.Errorf("Could not download file to %v", downloadedFilePath)
 			}
 
-			got, _, err := InstallBinary(downloadedFilePath, repo, systemInfo, &lockFile, true, "", "")
+			got, _, _, err := InstallBinary(downloadedFilePath, repo, "v0.0.3", systemInfo, &lockFile, true, "", "", nil, false)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("InstallBinary() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -791,7 +805,7 @@ func TestInstallBinary_Fail(t *testing.T) {
 				t.Errorf("Could not download file to %v", downloadedFilePath)
 			}
 
-			got, _, err := InstallBinary(downloadedFilePath, repo, systemInfo, &lockFile, false, "", "")
+			got, _, _, err := InstallBinary(downloadedFilePath, repo, "v0.0.3", systemInfo, &lockFile, false, "", "", nil, false)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("InstallBinary() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -803,3 +817,161 @@ func TestInstallBinary_Fail(t *testing.T) {
 		})
 	}
 }
+
+func TestGetBinaryFromAsset(t *testing.T) {
+	tests := []struct {
+		name           string
+		binaryRename   string
+		wantBinaryName string
+		wantErr        bool
+	}{
+		{
+			name:           "test1",
+			binaryRename:   "testBinary",
+			wantBinaryName: "testBinary",
+			wantErr:        false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir := t.TempDir()
+			assetPath := filepath.Join(tempDir, "testBinary-linux-amd64")
+			os.WriteFile(assetPath, []byte("A test binary"), 0755)
+			wantBinaryHash, _ := CalculateFileHash(assetPath)
+			tmpExtractionPath := filepath.Join(tempDir, "tmp")
+
+			gotBinaryName, gotBinaryHash, err := GetBinaryFromAsset(assetPath, tmpExtractionPath, tt.binaryRename, nil)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetBinaryFromAsset() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if gotBinaryName != tt.wantBinaryName {
+				t.Errorf("GetBinaryFromAsset() binaryName = %v, want %v", gotBinaryName, tt.wantBinaryName)
+			}
+			if gotBinaryHash != wantBinaryHash {
+				t.Errorf("GetBinaryFromAsset() binaryHash = %v, want %v", gotBinaryHash, wantBinaryHash)
+			}
+			if tmpExists, _ := PathExists(tmpExtractionPath); tmpExists {
+				t.Errorf("The extraction path %v was not removed", tmpExtractionPath)
+			}
+		})
+	}
+}
+
+func Test_getBinaryNonInteractive(t *testing.T) {
+	tests := []struct {
+		name                string
+		desiredBinaryRename string
+		wantBinaryName      string
+		wantErr             bool
+	}{
+		{
+			name:                "test1",
+			desiredBinaryRename: "uvx",
+			wantBinaryName:      "uvx",
+			wantErr:             false,
+		},
+		{
+			name:                "test2",
+			desiredBinaryRename: "",
+			wantBinaryName:      "",
+			wantErr:             true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetNonInteractive(true)
+			defer SetNonInteractive(false)
+
+			tempDir := t.TempDir()
+			testFilePaths := []string{filepath.Join(tempDir, "uv"), filepath.Join(tempDir, "uvx")}
+			for _, testFilePath := range testFilePaths {
+				os.WriteFile(testFilePath, []byte(filepath.Base(testFilePath)), 0755)
+			}
+
+			_, gotBinaryName, _, err := getBinary(testFilePaths, tt.desiredBinaryRename, "")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("getBinary() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if gotBinaryName != tt.wantBinaryName {
+				t.Errorf("getBinary() gotBinaryName = %v, want %v", gotBinaryName, tt.wantBinaryName)
+			}
+		})
+	}
+}
+
+func TestPromptRenameBinaryNonInteractive(t *testing.T) {
+	SetNonInteractive(true)
+	defer SetNonInteractive(false)
+
+	got, err := PromptRenameBinary("fzf")
+	if err != nil {
+		t.Errorf("PromptRenameBinary() error = %v", err)
+	}
+	if got != "fzf" {
+		t.Errorf("PromptRenameBinary() = %v, want %v", got, "fzf")
+	}
+}
+
+func TestConfirmBinaryOverwriteNonInteractive(t *testing.T) {
+	SetNonInteractive(true)
+	defer SetNonInteractive(false)
+
+	lockFile := LockFile{Packages: []PackageData{{Binary: "fzf", Tag: "v0.1.0"}}}
+	if err := ConfirmBinaryOverwrite(lockFile, "fzf"); err != nil {
+		t.Errorf("ConfirmBinaryOverwrite() error = %v", err)
+	}
+}
+
+type failingReader struct{}
+
+func (failingReader) Read(p []byte) (int, error) {
+	return 0, errors.New("interrupted")
+}
+
+func Test_writeFileAtomic(t *testing.T) {
+	tests := []struct {
+		name     string
+		contents io.Reader
+		want     string
+		wantErr  bool
+	}{
+		{
+			name:     "test1",
+			contents: strings.NewReader("new binary"),
+			want:     "new binary",
+			wantErr:  false,
+		},
+		{
+			name:     "test2",
+			contents: io.MultiReader(strings.NewReader("partial"), failingReader{}),
+			want:     "old binary",
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir := t.TempDir()
+			destFilePath := filepath.Join(tempDir, "testBinary")
+			os.WriteFile(destFilePath, []byte("old binary"), 0755)
+
+			err := writeFileAtomic(destFilePath, tt.contents, 0755)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("writeFileAtomic() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			got, _ := os.ReadFile(destFilePath)
+			if string(got) != tt.want {
+				t.Errorf("writeFileAtomic() contents = %v, want %v", string(got), tt.want)
+			}
+			if isExecutable, _ := isExecutableFile(destFilePath); !isExecutable {
+				t.Errorf("writeFileAtomic() did not keep the file executable")
+			}
+			if dirEntries, _ := os.ReadDir(tempDir); len(dirEntries) != 1 {
+				t.Errorf("writeFileAtomic() left %v files behind, want 1", len(dirEntries))
+			}
+		})
+	}
+}
@@ -5,30 +5,125 @@ import (
 	"os"
 
 	"github.com/marwanhawari/stew/cmd"
+	"github.com/marwanhawari/stew/constants"
+	stew "github.com/marwanhawari/stew/lib"
 	"github.com/urfave/cli"
+	"golang.org/x/term"
 )
 
 func main() {
 
 	app := &cli.App{
 		Name:    "stew",
-		Version: "v0.6.0",
+		Version: constants.StewVersion,
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:   "yes, non-interactive",
+				Usage:  "Never prompt. Use the default choice or fail with an error instead. Enabled automatically when stdin is not a terminal",
+				EnvVar: "STEW_NONINTERACTIVE",
+			},
+			&cli.StringFlag{
+				Name:  "output, o",
+				Value: "text",
+				Usage: "The output format: text, json, or jsonl. Human readable output is written to stderr with json and jsonl",
+			},
+			&cli.BoolFlag{
+				Name:   "no-hooks",
+				Usage:  "Don't run the postInstall, postUpgrade, and preUninstall hooks of the packages",
+				EnvVar: "STEW_NO_HOOKS",
+			},
+			&cli.DurationFlag{
+				Name:  "wait",
+				Usage: "How long to wait for another stew process to finish before failing. [Ex: stew --wait 2m install Stewfile]",
+			},
+		},
+		Before: func(c *cli.Context) error {
+			stew.SetNonInteractive(c.Bool("yes") || !term.IsTerminal(int(os.Stdin.Fd())))
+			stew.SetLockWaitTimeout(c.Duration("wait"))
+			stew.SetHooksEnabled(!c.Bool("no-hooks"))
+			if command := c.App.Command(c.Args().First()); command != nil {
+				stew.SetOutputCommand(command.Name)
+			}
+			return stew.SetOutputFormat(c.String("output"))
+		},
+		After: func(c *cli.Context) error {
+			stew.FlushResults()
+			return nil
+		},
 		Commands: []cli.Command{
 			{
 				Name:    "install",
 				Usage:   "Install a binary. The input can be a GitHub repo or a URL. [Ex: stew install marwanhawari/ppath]",
 				Aliases: []string{"i"},
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "offline",
+						Usage: "Install a Stewfile.lock.json from the mirror or the download cache without any network access",
+					},
+					&cli.StringFlag{
+						Name:  "mirror",
+						Usage: "A mirror directory created by stew mirror create to install a Stewfile.lock.json from",
+					},
+					&cli.StringFlag{
+						Name:  "version",
+						Usage: "The version to render a URL template with, instead of the latest version from the version source",
+					},
+					&cli.StringFlag{
+						Name:  "version-url",
+						Usage: "A URL that returns the latest version of a URL template, as plain text, JSON, or an HTML page",
+					},
+					&cli.StringFlag{
+						Name:  "version-path",
+						Usage: "The dotted path of the version in the JSON returned by --version-url. [Ex: data.0.version]",
+					},
+					&cli.StringFlag{
+						Name:  "version-regex",
+						Usage: "A regex that matches versions in the page returned by --version-url. The highest version is used",
+					},
+				},
 				Action: func(c *cli.Context) error {
-					cmd.Install(c.Args().First())
+					cmd.Install(c.Args().First(), c.Bool("offline"), c.String("mirror"), c.String("version"), c.String("version-url"), c.String("version-path"), c.String("version-regex"))
 					return nil
 				},
 			},
 			{
 				Name:    "search",
-				Usage:   "Search for a GitHub repo then browse the selected repo's releases and assets. [Ex: stew search ripgrep]",
+				Usage:   "Search for a GitHub repo then install, browse, or view the README of the selected repo. [Ex: stew search ripgrep]",
 				Aliases: []string{"s"},
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "check",
+						Usage: "Check the latest release of each result for an asset matching your OS/arch",
+					},
+					&cli.BoolFlag{
+						Name:  "installable",
+						Usage: "Only show the results that have an asset matching your OS/arch",
+					},
+					&cli.StringFlag{
+						Name:  "sort",
+						Value: "relevance",
+						Usage: "Sort the results by relevance, stars, or release",
+					},
+					&cli.IntFlag{
+						Name:  "page",
+						Value: 1,
+						Usage: "The page of search results to start at",
+					},
+					&cli.BoolFlag{
+						Name:  "install",
+						Usage: "Install the latest release of the selected project",
+					},
+					&cli.BoolFlag{
+						Name:  "browse",
+						Usage: "Browse the releases and assets of the selected project",
+					},
+					&cli.BoolFlag{
+						Name:  "readme",
+						Usage: "View the description and README of the selected project",
+					},
+				},
 				Action: func(c *cli.Context) error {
-					cmd.Search(c.Args())
+					cmd.Search(c.Args(), c.Bool("check"), c.Bool("installable"), c.String("sort"), c.Int("page"), c.Bool("install"), c.Bool("browse"), c.Bool("readme"))
 					return nil
 				},
 			},
@@ -50,9 +145,27 @@ func main() {
 						Name:  "all",
 						Usage: "Upgrade all binaries",
 					},
+					&cli.BoolFlag{
+						Name:  "changelog",
+						Usage: "Write the release notes since the installed tag to the stew path, and show them before confirming each upgrade",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					cmd.Upgrade(c.Bool("all"), c.Args().First(), c.Bool("changelog"))
+					return nil
+				},
+			},
+			{
+				Name:  "outdated",
+				Usage: "List the installed binaries that have a newer release. [Ex: stew outdated --changelog]",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "changelog",
+						Usage: "Show the release notes since the installed tag of each outdated binary",
+					},
 				},
 				Action: func(c *cli.Context) error {
-					cmd.Upgrade(c.Bool("all"), c.Args().First())
+					cmd.Outdated(c.Bool("changelog"))
 					return nil
 				},
 			},
@@ -73,10 +186,10 @@ func main() {
 			},
 			{
 				Name:    "rename",
-				Usage:   "Rename an installed binary using an interactive UI. [Ex: stew rename fzf]",
+				Usage:   "Rename an installed binary using an interactive UI or a second argument. [Ex: stew rename fzf] [Ex: stew rename fzf fuzzy]",
 				Aliases: []string{"re"},
 				Action: func(c *cli.Context) error {
-					cmd.Rename(c.Args().First())
+					cmd.Rename(c.Args().First(), c.Args().Get(1))
 					return nil
 				},
 			},
@@ -95,6 +208,171 @@ func main() {
 					return nil
 				},
 			},
+			{
+				Name:  "info",
+				Usage: "Show the details of an installed binary or a GitHub repo. [Ex: stew info fzf] [Ex: stew info junegunn/fzf]",
+				Action: func(c *cli.Context) error {
+					cmd.Info(c.Args().First())
+					return nil
+				},
+			},
+			{
+				Name:  "doctor",
+				Usage: "Check for missing, modified, and dangling binaries, and for orphaned links and packages. [Ex: stew doctor --fix]",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "fix",
+						Usage: "Remove the links, packages, and assets that no installed binary uses",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					cmd.Doctor(c.Bool("fix"))
+					return nil
+				},
+			},
+			{
+				Name:  "sync",
+				Usage: "Install, change, and optionally prune binaries so that they match a Stewfile. Defaults to ./Stewfile. [Ex: stew sync --prune Stewfile]",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "prune",
+						Usage: "Uninstall binaries that are not in the Stewfile",
+					},
+					&cli.BoolFlag{
+						Name:  "dry-run",
+						Usage: "Print the sync plan without making any changes",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					cmd.Sync(c.Args().First(), c.Bool("prune"), c.Bool("dry-run"))
+					return nil
+				},
+			},
+			{
+				Name:  "lock",
+				Usage: "Resolve the assets, URLs, and hashes of a lockfile or Stewfile for multiple platforms. Defaults to the installed binaries. [Ex: stew lock --platform linux/amd64 --platform darwin/arm64]",
+				Flags: []cli.Flag{
+					&cli.StringSliceFlag{
+						Name:  "platform",
+						Usage: "A platform to lock in the os/arch format. Can be repeated",
+					},
+					&cli.BoolFlag{
+						Name:  "force",
+						Usage: "Replace the Stewfile.lock.json next to a Stewfile without asking",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					cmd.Lock(c.Args().First(), c.StringSlice("platform"), c.Bool("force"))
+					return nil
+				},
+			},
+			{
+				Name:    "export",
+				Usage:   "Print a Stewfile that reproduces the installed binaries. [Ex: stew export --pin --format toml > Stewfile.toml]",
+				Aliases: []string{"freeze"},
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "pin",
+						Usage: "Pin the installed tags and assets",
+					},
+					&cli.StringFlag{
+						Name:  "format",
+						Value: "line",
+						Usage: "The Stewfile format: line, toml, or json",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					cmd.Export(c.Bool("pin"), c.String("format"))
+					return nil
+				},
+			},
+			{
+				Name:  "mirror",
+				Usage: "Manage mirror directories for offline installs. [Ex: stew mirror create Stewfile.lock.json /mnt/stew-mirror]",
+				Subcommands: []cli.Command{
+					{
+						Name:      "create",
+						Usage:     "Download every asset in a Stewfile.lock.json, including the locked platforms, into a mirror directory",
+						ArgsUsage: "<Stewfile.lock.json> <mirror directory>",
+						Action: func(c *cli.Context) error {
+							cmd.MirrorCreate(c.Args().First(), c.Args().Get(1))
+							return nil
+						},
+					},
+				},
+			},
+			{
+				Name:  "auth",
+				Usage: "Show the credentials that stew uses. [Ex: stew auth status]",
+				Subcommands: []cli.Command{
+					{
+						Name:  "status",
+						Usage: "Show which credential is used for each host without revealing it",
+						Action: func(c *cli.Context) error {
+							cmd.AuthStatus()
+							return nil
+						},
+					},
+				},
+			},
+			{
+				Name:  "cache",
+				Usage: "Manage the download cache that is shared by every stew path. [Ex: stew cache prune --older-than 30d]",
+				Subcommands: []cli.Command{
+					{
+						Name:  "list",
+						Usage: "List the cached assets",
+						Action: func(c *cli.Context) error {
+							cmd.CacheList()
+							return nil
+						},
+					},
+					{
+						Name:  "size",
+						Usage: "Print the size of the cache",
+						Action: func(c *cli.Context) error {
+							cmd.CacheSize()
+							return nil
+						},
+					},
+					{
+						Name:  "prune",
+						Usage: "Remove cached assets. Needs --older-than or --all",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:  "older-than",
+								Usage: "Only remove assets that haven't been used for this long. [Ex: 12h, 30d]",
+							},
+							&cli.BoolFlag{
+								Name:  "all",
+								Usage: "Remove every cached asset",
+							},
+						},
+						Action: func(c *cli.Context) error {
+							cmd.CachePrune(c.String("older-than"), c.Bool("all"))
+							return nil
+						},
+					},
+				},
+			},
+			{
+				Name:  "self-update",
+				Usage: "Update stew itself to the latest release. [Ex: stew self-update --check]",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "check",
+						Usage: "Only check if a newer release is available",
+					},
+					&cli.StringFlag{
+						Name:  "to",
+						Usage: "Update to a specific release tag instead of the latest release",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					cmd.SelfUpdate(c.Bool("check"), c.String("to"))
+					return nil
+				},
+			},
 			{
 				Name:  "config",
 				Usage: "Configure stew using an interactive UI. [Ex: stew config]",